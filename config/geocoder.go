@@ -0,0 +1,37 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+)
+
+// Geocoder bernilai nil jika GEOCODER tidak diset; event tanpa koordinat
+// lalu tidak muncul di pencarian terdekat.
+var Geocoder utils.Geocoder
+
+// InitGeocoder - GEOCODER=nominatim memakai Nominatim (GEOCODER_URL,
+// GEOCODER_USER_AGENT), GEOCODER=stub memakai daftar kota offline
+func InitGeocoder() {
+	switch os.Getenv("GEOCODER") {
+	case "":
+		log.Println("GEOCODER not set, venue geocoding disabled")
+	case "stub":
+		Geocoder = utils.StubGeocoder{}
+		log.Println("Using offline stub geocoder")
+	case "nominatim":
+		baseURL := os.Getenv("GEOCODER_URL")
+		if baseURL == "" {
+			baseURL = "https://nominatim.openstreetmap.org"
+		}
+		userAgent := os.Getenv("GEOCODER_USER_AGENT")
+		if userAgent == "" {
+			userAgent = "Ticketing-Backend"
+		}
+		Geocoder = utils.NominatimGeocoder{BaseURL: baseURL, UserAgent: userAgent}
+		log.Println("Using Nominatim geocoder at", baseURL)
+	default:
+		log.Println("Unknown GEOCODER, venue geocoding disabled:", os.Getenv("GEOCODER"))
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// SMTPConfig - server email untuk notifikasi. Bernilai nil jika SMTP_HOST
+// tidak diset; notifikasi lalu hanya tersimpan di aplikasi.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+var SMTP *SMTPConfig
+
+func InitSMTP() {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Println("SMTP_HOST not set, notifications are in-app only")
+		return
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = os.Getenv("SMTP_USERNAME")
+	}
+
+	SMTP = &SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+	log.Println("SMTP configured for", host)
+}
+
+// SendMail mengirim email teks biasa. Tanpa konfigurasi SMTP tidak melakukan apa-apa.
+func SendMail(to, subject, body string) error {
+	if SMTP == nil || to == "" {
+		return nil
+	}
+
+	var auth smtp.Auth
+	if SMTP.Username != "" {
+		auth = smtp.PlainAuth("", SMTP.Username, SMTP.Password, SMTP.Host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + SMTP.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	return smtp.SendMail(fmt.Sprintf("%s:%s", SMTP.Host, SMTP.Port), auth, SMTP.From, []string{to}, []byte(msg))
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis bernilai nil jika REDIS_URL tidak diset; fitur live lalu hanya
+// menyiarkan ke koneksi di instance yang sama.
+var Redis *redis.Client
+
+func InitRedis() {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		log.Println("REDIS_URL not set, live updates are limited to this instance")
+		return
+	}
+
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		log.Println("Invalid REDIS_URL, live updates are limited to this instance:", err)
+		return
+	}
+
+	client := redis.NewClient(opt)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Println("Failed to connect to Redis, live updates are limited to this instance:", err)
+		return
+	}
+
+	Redis = client
+	log.Println("Redis connected successfully")
+}
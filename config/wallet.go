@@ -0,0 +1,172 @@
+package config
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"log"
+	"os"
+	"strings"
+)
+
+// AppleWalletConfig - sertifikat dan identitas Pass Type ID untuk .pkpass
+type AppleWalletConfig struct {
+	PassTypeID    string
+	TeamID        string
+	Organization  string
+	WebServiceURL string
+	APNsHost      string
+	Certificate   *x509.Certificate
+	PrivateKey    crypto.PrivateKey
+	WWDR          *x509.Certificate
+	// PEM mentah untuk TLS client certificate ke APNs
+	CertificatePEM []byte
+	PrivateKeyPEM  []byte
+}
+
+// GoogleWalletConfig - service account untuk menandatangani save-JWT Google Wallet
+type GoogleWalletConfig struct {
+	IssuerID    string
+	ClientEmail string
+	PrivateKey  *rsa.PrivateKey
+	Origins     []string
+}
+
+// Bernilai nil jika wallet tidak dikonfigurasi
+var AppleWallet *AppleWalletConfig
+var GoogleWallet *GoogleWalletConfig
+
+func InitWallet() {
+	if os.Getenv("APPLE_PASS_TYPE_ID") != "" {
+		apple, err := loadAppleWallet()
+		if err != nil {
+			log.Println("Apple Wallet disabled:", err)
+		} else {
+			AppleWallet = apple
+			log.Println("Apple Wallet initialized successfully")
+		}
+	}
+
+	if os.Getenv("GOOGLE_WALLET_ISSUER_ID") != "" {
+		google, err := loadGoogleWallet()
+		if err != nil {
+			log.Println("Google Wallet disabled:", err)
+		} else {
+			GoogleWallet = google
+			log.Println("Google Wallet initialized successfully")
+		}
+	}
+}
+
+func loadAppleWallet() (*AppleWalletConfig, error) {
+	certPEM, err := os.ReadFile(os.Getenv("APPLE_PASS_CERT_FILE"))
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(os.Getenv("APPLE_PASS_KEY_FILE"))
+	if err != nil {
+		return nil, err
+	}
+	wwdrPEM, err := os.ReadFile(os.Getenv("APPLE_WWDR_CERT_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := parseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	wwdr, err := parseCertificatePEM(wwdrPEM)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	organization := os.Getenv("APPLE_PASS_ORGANIZATION")
+	if organization == "" {
+		organization = "Ticketing"
+	}
+	apnsHost := os.Getenv("APPLE_APNS_HOST")
+	if apnsHost == "" {
+		apnsHost = "https://api.push.apple.com"
+	}
+
+	return &AppleWalletConfig{
+		PassTypeID:     os.Getenv("APPLE_PASS_TYPE_ID"),
+		TeamID:         os.Getenv("APPLE_TEAM_ID"),
+		Organization:   organization,
+		WebServiceURL:  os.Getenv("APPLE_PASS_WEB_SERVICE_URL"),
+		APNsHost:       apnsHost,
+		Certificate:    cert,
+		PrivateKey:     key,
+		WWDR:           wwdr,
+		CertificatePEM: certPEM,
+		PrivateKeyPEM:  keyPEM,
+	}, nil
+}
+
+func loadGoogleWallet() (*GoogleWalletConfig, error) {
+	raw, err := os.ReadFile(os.Getenv("GOOGLE_WALLET_SERVICE_ACCOUNT_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	var account struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return nil, err
+	}
+
+	key, err := parsePrivateKeyPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("service account key is not an RSA key")
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("GOOGLE_WALLET_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return &GoogleWalletConfig{
+		IssuerID:    os.Getenv("GOOGLE_WALLET_ISSUER_ID"),
+		ClientEmail: account.ClientEmail,
+		PrivateKey:  rsaKey,
+		Origins:     origins,
+	}, nil
+}
+
+func parseCertificatePEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid certificate PEM")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
@@ -0,0 +1,606 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SpeakerRequest struct {
+	Name     string `json:"name" form:"name"`
+	Headline string `json:"headline" form:"headline"`
+	Bio      string `json:"bio" form:"bio"`
+	Photo    string `json:"photo" form:"photo"`
+}
+
+type AgendaSessionRequest struct {
+	Title             string   `json:"title"`
+	Description       string   `json:"description"`
+	Stage             string   `json:"stage"`
+	StartTime         string   `json:"start_time"`
+	EndTime           string   `json:"end_time"`
+	Tags              []string `json:"tags"`
+	SpeakerIDs        []string `json:"speaker_ids"`
+	TicketCategoryIDs []string `json:"ticket_category_ids"`
+}
+
+// applySpeakerRequest menyalin request ke speaker. Foto bisa berupa URL di
+// field `photo` atau file multipart `photo` yang diunggah ke Cloudinary.
+func applySpeakerRequest(c *fiber.Ctx, event models.Event, req SpeakerRequest, speaker *models.Speaker) (int, string) {
+	if strings.TrimSpace(req.Name) == "" {
+		return fiber.StatusBadRequest, "Name is required"
+	}
+
+	speaker.Name = strings.TrimSpace(req.Name)
+	speaker.Headline = req.Headline
+	speaker.Bio = req.Bio
+	if req.Photo != "" {
+		speaker.Photo = req.Photo
+	}
+
+	photoFile, err := c.FormFile("photo")
+	if err == nil {
+		file, err := photoFile.Open()
+		if err == nil {
+			defer file.Close()
+			folder := fmt.Sprintf("ticketing-app/events/%s/speakers", event.OwnerID)
+			photoURL, err := config.UploadImage(context.Background(), file, folder)
+			if err != nil {
+				return fiber.StatusInternalServerError, "Failed to upload speaker photo"
+			}
+			speaker.Photo = photoURL
+		}
+	}
+
+	return 0, ""
+}
+
+// applySessionRequest memvalidasi sesi terhadap event dan mengembalikan
+// speaker yang dipilih
+func applySessionRequest(event models.Event, req AgendaSessionRequest, session *models.AgendaSession) ([]models.Speaker, string) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, "Title is required"
+	}
+
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return nil, "Invalid start_time format. Use RFC3339"
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		return nil, "Invalid end_time format. Use RFC3339"
+	}
+	if !endTime.After(startTime) {
+		return nil, "end_time must be after start_time"
+	}
+	if startTime.Before(event.DateStart) || endTime.After(event.DateEnd) {
+		return nil, "Session must take place between the event date_start and date_end"
+	}
+
+	for _, id := range req.TicketCategoryIDs {
+		found := false
+		for _, category := range event.TicketCategories {
+			if category.TicketCategoryID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, "Ticket category does not belong to this event: " + id
+		}
+	}
+
+	speakers := make([]models.Speaker, 0, len(req.SpeakerIDs))
+	if len(req.SpeakerIDs) > 0 {
+		if err := config.DB.Where("speaker_id IN ? AND event_id = ?", req.SpeakerIDs, event.EventID).Find(&speakers).Error; err != nil {
+			return nil, "Failed to load speakers"
+		}
+		if len(speakers) != len(req.SpeakerIDs) {
+			return nil, "Speaker does not belong to this event"
+		}
+	}
+
+	tags := make([]string, 0, len(req.Tags))
+	for _, tag := range req.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	session.Title = strings.TrimSpace(req.Title)
+	session.Description = req.Description
+	session.Stage = strings.TrimSpace(req.Stage)
+	session.StartTime = startTime
+	session.EndTime = endTime
+	session.Tags = tags
+	session.TicketCategoryIDs = req.TicketCategoryIDs
+	return speakers, ""
+}
+
+// sessionAccessible - pemegang tiket boleh menyimpan sesi jika kategorinya
+// termasuk di TicketCategoryIDs sesi (kosong = semua kategori)
+func sessionAccessible(db *gorm.DB, session models.AgendaSession, userID string) (bool, error) {
+	query := db.Model(&models.Ticket{}).
+		Where("event_id = ? AND owner_id = ? AND status IN ?", session.EventID, userID, []string{"active", "used"})
+	if len(session.TicketCategoryIDs) > 0 {
+		query = query.Where("ticket_category_id IN ?", session.TicketCategoryIDs)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func loadAgendaSession(c *fiber.Ctx, eventID string) (models.AgendaSession, error) {
+	var session models.AgendaSession
+	if err := config.DB.Where("session_id = ? AND event_id = ?", c.Params("session_id"), eventID).First(&session).Error; err != nil {
+		return session, fiber.NewError(fiber.StatusNotFound, "Session not found")
+	}
+	return session, nil
+}
+
+// GetEventAgenda - Agenda publik event (?stage, tag, speaker_id, date=YYYY-MM-DD)
+func GetEventAgenda(c *fiber.Ctx) error {
+	var event models.Event
+	if err := publicEvents(config.DB).Where("event_id = ?", c.Params("id")).First(&event).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+
+	query := config.DB.Preload("Speakers").Where("event_id = ?", event.EventID)
+	if stage := c.Query("stage"); stage != "" {
+		query = query.Where("stage = ?", stage)
+	}
+	if date := c.Query("date"); date != "" {
+		day, err := time.ParseInLocation("2006-01-02", date, event.DateStart.Location())
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid date, use YYYY-MM-DD",
+			})
+		}
+		query = query.Where("start_time >= ? AND start_time < ?", day, day.AddDate(0, 0, 1))
+	}
+	if speakerID := c.Query("speaker_id"); speakerID != "" {
+		query = query.Where("session_id IN (?)",
+			config.DB.Table("agenda_session_speakers").Select("session_id").Where("speaker_id = ?", speakerID))
+	}
+
+	var sessions []models.AgendaSession
+	if err := query.Order("start_time ASC, stage ASC").Find(&sessions).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch agenda",
+		})
+	}
+
+	// tag disimpan sebagai JSON, difilter setelah dimuat
+	if tag := c.Query("tag"); tag != "" {
+		filtered := sessions[:0]
+		for _, session := range sessions {
+			if containsString(session.Tags, tag) {
+				filtered = append(filtered, session)
+			}
+		}
+		sessions = filtered
+	}
+
+	var speakers []models.Speaker
+	if err := config.DB.Where("event_id = ?", event.EventID).Order("name ASC").Find(&speakers).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch speakers",
+		})
+	}
+
+	var stages []string
+	if err := config.DB.Model(&models.AgendaSession{}).
+		Where("event_id = ? AND stage <> ''", event.EventID).
+		Distinct().Pluck("stage", &stages).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch stages",
+		})
+	}
+	sort.Strings(stages)
+
+	return c.JSON(fiber.Map{
+		"message":  "Agenda retrieved successfully",
+		"event_id": event.EventID,
+		"sessions": sessions,
+		"stages":   stages,
+		"speakers": speakers,
+	})
+}
+
+// GetSpeakers - Daftar speaker event (owner/admin)
+func GetSpeakers(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var speakers []models.Speaker
+	if err := config.DB.Where("event_id = ?", event.EventID).Order("name ASC").Find(&speakers).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch speakers",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Speakers retrieved successfully",
+		"speakers": speakers,
+	})
+}
+
+// CreateSpeaker - Tambah speaker (JSON atau multipart dengan file photo)
+func CreateSpeaker(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req SpeakerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	speaker := models.Speaker{
+		SpeakerID: utils.GenerateSpeakerID(),
+		EventID:   event.EventID,
+	}
+	if status, msg := applySpeakerRequest(c, event, req, &speaker); msg != "" {
+		return c.Status(status).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	if err := config.DB.Create(&speaker).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create speaker",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Speaker created successfully",
+		"speaker": speaker,
+	})
+}
+
+// UpdateSpeaker - Ubah data speaker
+func UpdateSpeaker(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var speaker models.Speaker
+	if err := config.DB.Where("speaker_id = ? AND event_id = ?", c.Params("speaker_id"), event.EventID).First(&speaker).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Speaker not found",
+		})
+	}
+
+	var req SpeakerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	if status, msg := applySpeakerRequest(c, event, req, &speaker); msg != "" {
+		return c.Status(status).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	if err := config.DB.Save(&speaker).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update speaker",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Speaker updated successfully",
+		"speaker": speaker,
+	})
+}
+
+// DeleteSpeaker - Hapus speaker dan lepaskan dari semua sesi
+func DeleteSpeaker(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	speakerID := c.Params("speaker_id")
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	if err := tx.Exec("DELETE FROM agenda_session_speakers WHERE speaker_id = ?", speakerID).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to detach speaker from sessions",
+		})
+	}
+
+	result := tx.Where("speaker_id = ? AND event_id = ?", speakerID, event.EventID).Delete(&models.Speaker{})
+	if result.Error != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete speaker",
+		})
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Speaker not found",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Speaker deleted successfully",
+	})
+}
+
+// CreateAgendaSession - Tambah sesi ke agenda event
+func CreateAgendaSession(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req AgendaSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	session := models.AgendaSession{
+		SessionID: utils.GenerateAgendaSessionID(),
+		EventID:   event.EventID,
+	}
+	speakers, msg := applySessionRequest(event, req, &session)
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+	session.Speakers = speakers
+
+	if err := config.DB.Create(&session).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create session",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Session created successfully",
+		"session": session,
+	})
+}
+
+// UpdateAgendaSession - Ubah sesi; speaker_ids menggantikan daftar speaker lama
+func UpdateAgendaSession(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	session, err := loadAgendaSession(c, event.EventID)
+	if err != nil {
+		return err
+	}
+
+	var req AgendaSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	speakers, msg := applySessionRequest(event, req, &session)
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	if err := tx.Omit("Speakers").Save(&session).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update session",
+		})
+	}
+
+	if err := tx.Model(&session).Association("Speakers").Replace(speakers); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update session speakers",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	session.Speakers = speakers
+
+	return c.JSON(fiber.Map{
+		"message": "Session updated successfully",
+		"session": session,
+	})
+}
+
+// DeleteAgendaSession - Hapus sesi beserta speaker link dan bookmark-nya
+func DeleteAgendaSession(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	session, err := loadAgendaSession(c, event.EventID)
+	if err != nil {
+		return err
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	if err := tx.Model(&session).Association("Speakers").Clear(); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to detach session speakers",
+		})
+	}
+
+	if err := tx.Where("session_id = ?", session.SessionID).Delete(&models.SessionBookmark{}).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete bookmarks",
+		})
+	}
+
+	if err := tx.Delete(&session).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete session",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Session deleted successfully",
+	})
+}
+
+// GetMyBookmarks - Sesi yang disimpan user untuk satu event
+func GetMyBookmarks(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	var sessions []models.AgendaSession
+	if err := config.DB.Preload("Speakers").
+		Where("event_id = ? AND session_id IN (?)", c.Params("id"),
+			config.DB.Model(&models.SessionBookmark{}).Select("session_id").Where("user_id = ?", user.UserID)).
+		Order("start_time ASC").
+		Find(&sessions).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch bookmarks",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Bookmarks retrieved successfully",
+		"sessions": sessions,
+	})
+}
+
+// BookmarkSession - Simpan sesi ke jadwal pribadi (khusus pemegang tiket)
+func BookmarkSession(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	session, err := loadAgendaSession(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	allowed, err := sessionAccessible(config.DB, session, user.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check tickets",
+		})
+	}
+	if !allowed {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "A valid ticket for this session is required",
+		})
+	}
+
+	var bookmark models.SessionBookmark
+	err = config.DB.Where("session_id = ? AND user_id = ?", session.SessionID, user.UserID).First(&bookmark).Error
+	if err == nil {
+		return c.JSON(fiber.Map{
+			"message":  "Session already bookmarked",
+			"bookmark": bookmark,
+		})
+	}
+
+	bookmark = models.SessionBookmark{
+		BookmarkID: utils.GenerateBookmarkID(),
+		SessionID:  session.SessionID,
+		UserID:     user.UserID,
+		EventID:    session.EventID,
+	}
+	if err := config.DB.Create(&bookmark).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to bookmark session",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":  "Session bookmarked successfully",
+		"bookmark": bookmark,
+	})
+}
+
+// RemoveBookmark - Hapus sesi dari jadwal pribadi
+func RemoveBookmark(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	result := config.DB.Where("session_id = ? AND user_id = ? AND event_id = ?", c.Params("session_id"), user.UserID, c.Params("id")).
+		Delete(&models.SessionBookmark{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to remove bookmark",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Bookmark not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Bookmark removed successfully",
+	})
+}
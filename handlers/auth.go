@@ -65,7 +65,8 @@ func Register(c *fiber.Ctx) error {
 
 	// Check if user already exists
 	var existingUser models.User
-	if err := config.DB.Where("username = ? OR email = ?", username, email).First(&existingUser).Error; err == nil {
+	// akun yang dihapus (soft delete) tetap memegang username/email sampai dipurge
+	if err := config.DB.Unscoped().Where("username = ? OR email = ?", username, email).First(&existingUser).Error; err == nil {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"error": "Username or email already exists",
 		})
@@ -169,7 +170,7 @@ func DefaultAdminSetup() error {
 
 	// Cek apakah admin sudah ada
 	var adminCheck models.User
-	if err := config.DB.Where("username = ? && email = ?", df_admin_username, df_admin_email).First(&adminCheck).Error; err == nil {
+	if err := config.DB.Unscoped().Where("username = ? && email = ?", df_admin_username, df_admin_email).First(&adminCheck).Error; err == nil {
 		log.Println("Default admin user already exists")
 		return nil
 	}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Event yang bisa diekspor ke kalender. Event batal tetap diikutkan agar
+// klien kalender menandainya CANCELLED, bukan sekadar menghilang.
+var calendarEventStatuses = []string{"active", "approved", "ended", "postponed", "cancelled"}
+
+// Feed hanya memuat event yang selesai paling lama sekian hari lalu
+const calendarFeedHistory = 90 * 24 * time.Hour
+
+func calendarEventUID(eventID string) string {
+	return eventID + "@ticketing"
+}
+
+// eventICal memetakan event ke VEVENT; status postponed ditandai TENTATIVE
+func eventICal(event models.Event) utils.ICalEvent {
+	status := "CONFIRMED"
+	switch event.Status {
+	case "cancelled":
+		status = "CANCELLED"
+	case "postponed":
+		status = "TENTATIVE"
+	}
+
+	var location []string
+	for _, part := range []string{event.Venue, event.Location, event.District} {
+		if part = strings.TrimSpace(part); part != "" {
+			location = append(location, part)
+		}
+	}
+
+	return utils.ICalEvent{
+		UID:          calendarEventUID(event.EventID),
+		Summary:      event.Name,
+		Description:  event.Description,
+		Location:     strings.Join(location, ", "),
+		Start:        event.DateStart,
+		End:          event.DateEnd,
+		Status:       status,
+		LastModified: event.UpdatedAt,
+		Latitude:     event.Latitude,
+		Longitude:    event.Longitude,
+	}
+}
+
+func sendICalendar(c *fiber.Ctx, filename string, calendar []byte) error {
+	c.Set("Content-Type", "text/calendar; charset=utf-8")
+	if filename != "" {
+		c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	}
+	return c.Send(calendar)
+}
+
+// GetEventCalendar - file .ics satu event publik
+func GetEventCalendar(c *fiber.Ctx) error {
+	var event models.Event
+	if err := config.DB.Where("event_id = ? AND status IN ?", c.Params("id"), calendarEventStatuses).
+		Where("(publish_at IS NULL OR publish_at <= ?)", time.Now()).
+		First(&event).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+
+	calendar := utils.BuildICalendar(event.Name, []utils.ICalEvent{eventICal(event)})
+	return sendICalendar(c, fmt.Sprintf("event_%s.ics", event.EventID), calendar)
+}
+
+// GetTicketCalendar - file .ics untuk satu tiket, berisi kategori dan kursi
+func GetTicketCalendar(c *fiber.Ctx) error {
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if ticket.Status != "active" && ticket.Status != "used" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Calendar export is only available for paid tickets",
+		})
+	}
+
+	var event models.Event
+	if err := config.DB.First(&event, "event_id = ?", ticket.EventID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+
+	var category models.TicketCategory
+	config.DB.First(&category, "ticket_category_id = ?", ticket.TicketCategoryID)
+
+	entry := eventICal(event)
+	entry.UID = calendarEventUID(ticket.TicketID)
+	details := []string{"Ticket: " + ticket.TicketID}
+	if category.Name != "" {
+		details = append(details, "Category: "+category.Name)
+	}
+	if ticket.SeatLabel != "" {
+		details = append(details, "Seat: "+ticket.SeatLabel)
+	}
+	if entry.Description != "" {
+		details = append(details, "", entry.Description)
+	}
+	entry.Description = strings.Join(details, "\n")
+
+	calendar := utils.BuildICalendar(event.Name, []utils.ICalEvent{entry})
+	return sendICalendar(c, fmt.Sprintf("ticket_%s.ics", ticket.TicketID), calendar)
+}
+
+func calendarFeedURL(c *fiber.Ctx, token string) string {
+	return fmt.Sprintf("%s/api/calendar/%s/feed.ics", c.BaseURL(), token)
+}
+
+// GetCalendarFeedURL - URL langganan kalender user, token dibuat saat pertama diminta
+func GetCalendarFeedURL(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	if user.CalendarToken != nil {
+		return c.JSON(fiber.Map{
+			"url": calendarFeedURL(c, *user.CalendarToken),
+		})
+	}
+	return issueCalendarToken(c, user)
+}
+
+// RotateCalendarFeedURL - token baru, URL lama langsung tidak berlaku
+func RotateCalendarFeedURL(c *fiber.Ctx) error {
+	return issueCalendarToken(c, c.Locals("user").(models.User))
+}
+
+// RevokeCalendarFeedURL - mematikan langganan kalender
+func RevokeCalendarFeedURL(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	if err := config.DB.Model(&models.User{}).Where("user_id = ?", user.UserID).
+		Update("calendar_token", nil).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke calendar feed",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Calendar feed revoked",
+	})
+}
+
+func issueCalendarToken(c *fiber.Ctx, user models.User) error {
+	token := utils.GenerateCalendarToken()
+	if err := config.DB.Model(&models.User{}).Where("user_id = ?", user.UserID).
+		Update("calendar_token", token).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create calendar feed",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"url": calendarFeedURL(c, token),
+	})
+}
+
+// GetCalendarFeed - feed .ics untuk aplikasi kalender (tanpa header auth,
+// token di URL). Isinya event yang tiketnya dimiliki atau yang disukai user,
+// dibangun ulang setiap request sehingga perubahan jadwal ikut terbawa.
+func GetCalendarFeed(c *fiber.Ctx) error {
+	token := c.Params("token")
+
+	var user models.User
+	if token == "" || config.DB.Where("calendar_token = ?", token).First(&user).Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Calendar feed not found",
+		})
+	}
+
+	var events []models.Event
+	if err := config.DB.Where("status IN ?", calendarEventStatuses).
+		Where("(publish_at IS NULL OR publish_at <= ?)", time.Now()).
+		Where("date_end >= ?", time.Now().Add(-calendarFeedHistory)).
+		Where("(event_id IN (?) OR event_id IN (?))",
+			// tiket event batal ikut dibatalkan, tetap dimuat agar event tampil CANCELLED
+			config.DB.Model(&models.Ticket{}).Select("event_id").
+				Where("owner_id = ?", user.UserID).
+				Where("status IN ? OR (status = ? AND event_id IN (?))", []string{"active", "used"}, "cancelled",
+					config.DB.Model(&models.Event{}).Select("event_id").Where("status = ?", "cancelled")),
+			config.DB.Model(&models.EventLike{}).Select("event_id").
+				Where("user_id = ?", user.UserID),
+		).
+		Order("date_start ASC").
+		Find(&events).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to build calendar feed",
+		})
+	}
+
+	entries := make([]utils.ICalEvent, 0, len(events))
+	for _, event := range events {
+		entries = append(entries, eventICal(event))
+	}
+
+	c.Set("Cache-Control", "no-cache")
+	return sendICalendar(c, "", utils.BuildICalendar("My Events", entries))
+}
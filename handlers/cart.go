@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"gorm.io/gorm"
+
 	"github.com/Tsaniii18/Ticketing-Backend/config"
 	"github.com/Tsaniii18/Ticketing-Backend/models"
 	"github.com/Tsaniii18/Ticketing-Backend/utils"
@@ -55,6 +57,25 @@ func AddToCart(c *fiber.Ctx) error {
 		})
 	}
 
+	if problem := eventSaleProblem(ticketCategory.EventID); problem != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": problem,
+		})
+	}
+
+	if ticketCategory.Archived {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Ticket category is no longer on sale",
+		})
+	}
+
+	// kategori bernomor kursi masuk keranjang lewat pemilihan kursi
+	if ticketCategory.Seated {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "This ticket category uses reserved seating, select seats first",
+		})
+	}
+
 	// Cek apakah item dengan ticket category yang sama sudah ada di cart user
 	var existingCart models.Cart
 	err := config.DB.
@@ -66,7 +87,7 @@ func AddToCart(c *fiber.Ctx) error {
 		newQuantity := existingCart.Quantity + cartData.Quantity
 
 		// Cek ketersediaan kuota untuk quantity baru
-		if ticketCategory.Sold+newQuantity > ticketCategory.Quota {
+		if ticketCategory.Sold+ticketCategory.Imported+newQuantity > ticketCategory.Quota {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Not enough quota available",
 			})
@@ -140,7 +161,7 @@ func AddToCart(c *fiber.Ctx) error {
 	}
 
 	// Item belum ada di cart, buat cart baru
-	if ticketCategory.Sold+cartData.Quantity > ticketCategory.Quota {
+	if ticketCategory.Sold+ticketCategory.Imported+cartData.Quantity > ticketCategory.Quota {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Not enough quota available",
 		})
@@ -224,6 +245,7 @@ type CartResponse struct {
 	OwnerID        string                  `json:"owner_id"`
 	Quantity       uint                    `json:"quantity"`
 	PriceTotal     float64                 `json:"price_total"`
+	SeatIDs        []string                `json:"seat_ids,omitempty"`
 	CreatedAt      time.Time               `json:"created_at"`
 	UpdatedAt      time.Time               `json:"updated_at"`
 	TicketCategory *TicketCategoryResponse `json:"ticket_category"`
@@ -294,6 +316,7 @@ func GetCart(c *fiber.Ctx) error {
 			OwnerID:    cart.OwnerID,
 			Quantity:   cart.Quantity,
 			PriceTotal: cart.PriceTotal,
+			SeatIDs:    cart.SeatIDs,
 			CreatedAt:  cart.CreatedAt,
 			UpdatedAt:  cart.UpdatedAt,
 			TicketCategory: &TicketCategoryResponse{
@@ -381,8 +404,14 @@ func UpdateCart(c *fiber.Ctx) error {
 		})
 	}
 
+	if ticketCategory.Seated {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Change seats for reserved seating instead of quantity",
+		})
+	}
+
 	// Cek ketersediaan kuota
-	availableQuota := ticketCategory.Quota - ticketCategory.Sold
+	availableQuota := ticketCategory.Quota - ticketCategory.Sold - ticketCategory.Imported
 	if updateData.Quantity > availableQuota {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": fmt.Sprintf("Not enough quota available. Available: %d, Requested: %d", availableQuota, updateData.Quantity),
@@ -483,6 +512,13 @@ func DeleteCart(c *fiber.Ctx) error {
 		})
 	}
 
+	// kursi yang ditahan untuk item ini ikut dilepas
+	if err := releaseHeldSeats(config.DB, user.UserID, cart.SeatIDs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to release seats: " + err.Error(),
+		})
+	}
+
 	if err := config.DB.Delete(&cart).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete cart item: " + err.Error(),
@@ -494,3 +530,13 @@ func DeleteCart(c *fiber.Ctx) error {
 		"deleted_cart_id": deleteData.CartID,
 	})
 }
+
+// repriceCarts menyesuaikan total cart kategori setelah harganya berubah
+func repriceCarts(tx *gorm.DB, categoryID string, price float64) error {
+	return tx.Model(&models.Cart{}).
+		Where("ticket_category_id = ?", categoryID).
+		Updates(map[string]interface{}{
+			"price_total": gorm.Expr("quantity * ?", price),
+			"updated_at":  time.Now(),
+		}).Error
+}
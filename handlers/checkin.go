@@ -0,0 +1,737 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// toleransi jam perangkat scanner yang lebih cepat dari server
+const offlineClockSkew = 5 * time.Minute
+
+// scan offline paling lama sekian sebelum batch diunggah; scan yang lebih tua
+// ditolak agar scanned_at tidak bisa dimundurkan untuk menghidupkan token lama
+const offlineMaxWindow = 12 * time.Hour
+
+// checkInOpensAt - gate dibuka sejak event mulai dikurangi grace period
+func checkInOpensAt(event models.Event) time.Time {
+	return event.DateStart.Add(-time.Duration(event.CheckInGraceBefore) * time.Minute)
+}
+
+// checkInClosesAt - gate ditutup saat event selesai ditambah grace period
+func checkInClosesAt(event models.Event) time.Time {
+	return event.DateEnd.Add(time.Duration(event.CheckInGraceAfter) * time.Minute)
+}
+
+// categoryWindow - jendela berlaku kategori tiket termasuk grace period event.
+// ok bernilai false jika kategori tidak punya jendela sendiri.
+func categoryWindow(category models.TicketCategory, event models.Event) (time.Time, time.Time, bool) {
+	if category.DateTimeStart.IsZero() || category.DateTimeEnd.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+	from := category.DateTimeStart.Add(-time.Duration(event.CheckInGraceBefore) * time.Minute)
+	until := category.DateTimeEnd.Add(time.Duration(event.CheckInGraceAfter) * time.Minute)
+	return from, until, true
+}
+
+func outsideCategoryWindow(category models.TicketCategory, event models.Event, at time.Time) bool {
+	from, until, ok := categoryWindow(category, event)
+	return ok && (at.Before(from) || at.After(until))
+}
+
+// categoryWindowMessage - pesan untuk ditampilkan aplikasi scanner
+func categoryWindowMessage(category models.TicketCategory, event models.Event, at time.Time) string {
+	from, until, _ := categoryWindow(category, event)
+	layout := "02 Jan 2006 15:04"
+	if at.Before(from) {
+		return fmt.Sprintf("%s ticket is not valid yet. Entry opens %s", category.Name, from.In(time.Local).Format(layout))
+	}
+	return fmt.Sprintf("%s ticket is no longer valid. Entry closed %s", category.Name, until.In(time.Local).Format(layout))
+}
+
+// ticketUsedAt - waktu check-in tiket; tiket lama belum punya used_at
+func ticketUsedAt(ticket models.Ticket) *time.Time {
+	if ticket.UsedAt != nil {
+		return ticket.UsedAt
+	}
+	if ticket.Status == "used" {
+		return &ticket.UpdatedAt
+	}
+	return nil
+}
+
+// scanScope - hak check-in user untuk satu event. Pemilik event dan admin boleh
+// semua tiket; gate staff dibatasi penugasan (kategori dan jam jaga).
+type scanScope struct {
+	full  bool
+	staff models.EventStaff
+}
+
+// loadScanScope mengembalikan false jika user tidak punya akses check-in ke event
+func loadScanScope(user models.User, event models.Event) (scanScope, bool) {
+	if event.OwnerID == user.UserID || user.Role == "admin" {
+		return scanScope{full: true}, true
+	}
+
+	if user.Role != "staff" {
+		return scanScope{}, false
+	}
+
+	var assignment models.EventStaff
+	if err := config.DB.Where("event_id = ? AND user_id = ?", event.EventID, user.UserID).First(&assignment).Error; err != nil {
+		return scanScope{}, false
+	}
+
+	return scanScope{staff: assignment}, true
+}
+
+// allows mengembalikan alasan penolakan, atau "" jika scan diizinkan
+func (s scanScope) allows(ticketCategoryID string, at time.Time) string {
+	if s.full {
+		return ""
+	}
+
+	if s.staff.ValidFrom != nil && at.Before(*s.staff.ValidFrom) {
+		return "outside_shift"
+	}
+	if s.staff.ValidUntil != nil && at.After(*s.staff.ValidUntil) {
+		return "outside_shift"
+	}
+
+	if !s.allowsCategory(ticketCategoryID) {
+		return "category_not_assigned"
+	}
+	return ""
+}
+
+func (s scanScope) allowsCategory(ticketCategoryID string) bool {
+	if s.full || len(s.staff.TicketCategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range s.staff.TicketCategoryIDs {
+		if id == ticketCategoryID {
+			return true
+		}
+	}
+	return false
+}
+
+type manifestTicket struct {
+	TicketID         string `json:"tid"`
+	TicketCategoryID string `json:"cid"`
+	Status           string `json:"s"`
+}
+
+// manifestWindow - jendela check-in kategori (sudah termasuk grace period)
+type manifestWindow struct {
+	From  time.Time `json:"from"`
+	Until time.Time `json:"until"`
+}
+
+type manifestClaims struct {
+	EventID    string                    `json:"eid"`
+	Categories map[string]string         `json:"categories"`
+	Windows    map[string]manifestWindow `json:"windows"`
+	Tickets    []manifestTicket          `json:"tickets"`
+	jwt.RegisteredClaims
+}
+
+// GetCheckInManifest - Manifest bertanda tangan berisi tiket valid untuk scan offline
+func GetCheckInManifest(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+	eventID := c.Params("event_id")
+
+	var event models.Event
+	if err := config.DB.Preload("TicketCategories").First(&event, "event_id = ?", eventID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+
+	scope, ok := loadScanScope(user, event)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Not authorized to check in tickets for this event",
+		})
+	}
+
+	// staff hanya menerima kategori yang ditugaskan kepadanya
+	categories := make(map[string]string)
+	windows := make(map[string]manifestWindow)
+	var categoryIDs []string
+	for _, category := range event.TicketCategories {
+		if !scope.allowsCategory(category.TicketCategoryID) {
+			continue
+		}
+		categories[category.TicketCategoryID] = category.Name
+		categoryIDs = append(categoryIDs, category.TicketCategoryID)
+		if from, until, ok := categoryWindow(category, event); ok {
+			windows[category.TicketCategoryID] = manifestWindow{From: from, Until: until}
+		}
+	}
+
+	var tickets []models.Ticket
+	if err := config.DB.
+		Where("event_id = ? AND ticket_category_id IN ? AND status IN ?", event.EventID, categoryIDs, []string{"active", "used"}).
+		Find(&tickets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch tickets",
+		})
+	}
+
+	entries := make([]manifestTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		entries = append(entries, manifestTicket{
+			TicketID:         ticket.TicketID,
+			TicketCategoryID: ticket.TicketCategoryID,
+			Status:           ticket.Status,
+		})
+	}
+
+	now := time.Now()
+	claims := manifestClaims{
+		EventID:    event.EventID,
+		Categories: categories,
+		Windows:    windows,
+		Tickets:    entries,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(checkInClosesAt(event).Add(24 * time.Hour)),
+		},
+	}
+
+	manifest, err := utils.SignEventManifest(event.EventID, claims)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to sign manifest",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":      "Manifest generated successfully",
+		"event_id":     event.EventID,
+		"generated_at": now,
+		"ticket_count": len(entries),
+		"manifest":     manifest,
+		// manifest dan QR diverifikasi dengan public key yang sama
+		"algorithm":  "EdDSA",
+		"public_key": base64.StdEncoding.EncodeToString(utils.EventTicketPublicKey(event.EventID)),
+	})
+}
+
+type offlineScanRequest struct {
+	ScanID    string `json:"scan_id"`
+	TicketID  string `json:"ticket_id"`
+	Code      string `json:"code"`
+	ScannedAt string `json:"scanned_at"`
+}
+
+type offlineScanResult struct {
+	ScanID    string     `json:"scan_id"`
+	TicketID  string     `json:"ticket_id,omitempty"`
+	Result    string     `json:"result"`
+	Reason    string     `json:"reason,omitempty"`
+	ScannedAt time.Time  `json:"scanned_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+type offlineScanConflict struct {
+	TicketID      string    `json:"ticket_id"`
+	WinnerDevice  string    `json:"winner_device_id"`
+	WinnerScanner string    `json:"winner_scanner_id,omitempty"`
+	WinnerScanID  string    `json:"winner_scan_id"`
+	WinnerTime    time.Time `json:"winner_scanned_at"`
+	LoserDevice   string    `json:"loser_device_id"`
+	LoserScanID   string    `json:"loser_scan_id"`
+	LoserTime     time.Time `json:"loser_scanned_at"`
+	WinnerChanged bool      `json:"winner_changed"`
+}
+
+// SyncOfflineScans - Unggah batch scan offline dari satu perangkat.
+//
+// Rekonsiliasi deterministik: scan paling awal yang menang (scanned_at, lalu
+// device_id, lalu scan_id). Scan lain untuk tiket yang sama dilaporkan sebagai
+// conflict. Counter attendant hanya naik sekali per tiket.
+func SyncOfflineScans(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+	eventID := c.Params("event_id")
+
+	var req struct {
+		DeviceID string               `json:"device_id"`
+		Gate     string               `json:"gate"`
+		Scans    []offlineScanRequest `json:"scans"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	if req.DeviceID == "" || len(req.Scans) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "device_id and scans are required",
+		})
+	}
+
+	if len(req.Scans) > 1000 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Maximum 1000 scans per batch",
+		})
+	}
+
+	var event models.Event
+	if err := config.DB.First(&event, "event_id = ?", eventID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+
+	scope, ok := loadScanScope(user, event)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Not authorized to check in tickets for this event",
+		})
+	}
+
+	type pendingScan struct {
+		offlineScanRequest
+		at time.Time
+	}
+
+	uploadedAt := time.Now()
+	var scans []pendingScan
+	var results []offlineScanResult
+	for _, scan := range req.Scans {
+		at, err := time.Parse(time.RFC3339, scan.ScannedAt)
+		if scan.ScanID == "" || err != nil {
+			results = append(results, offlineScanResult{
+				ScanID: scan.ScanID,
+				Result: "rejected",
+				Reason: "scan_id and RFC3339 scanned_at are required",
+			})
+			continue
+		}
+		scans = append(scans, pendingScan{offlineScanRequest: scan, at: at})
+	}
+
+	// urutan deterministik agar hasil sama walau batch diunggah ulang
+	sort.SliceStable(scans, func(i, j int) bool {
+		if !scans[i].at.Equal(scans[j].at) {
+			return scans[i].at.Before(scans[j].at)
+		}
+		return scans[i].ScanID < scans[j].ScanID
+	})
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	var conflicts []offlineScanConflict
+	var entries []models.CheckInLog
+	categories := make(map[string]models.TicketCategory)
+	for _, scan := range scans {
+		// idempotent: scan yang sama dari perangkat yang sama tidak diproses dua kali
+		var existing models.OfflineScan
+		if err := tx.Where("device_id = ? AND scan_id = ?", req.DeviceID, scan.ScanID).First(&existing).Error; err == nil {
+			results = append(results, offlineScanResult{
+				ScanID:    scan.ScanID,
+				TicketID:  existing.TicketID,
+				Result:    existing.Result,
+				Reason:    "already synced",
+				ScannedAt: existing.ScannedAt,
+			})
+			continue
+		}
+
+		record := models.OfflineScan{
+			OfflineScanID: utils.GenerateOfflineScanID(),
+			EventID:       event.EventID,
+			DeviceID:      req.DeviceID,
+			ScanID:        scan.ScanID,
+			ScannerID:     user.UserID,
+			Code:          scan.Code,
+			ScannedAt:     scan.at,
+		}
+
+		ticket, reason := resolveOfflineScan(tx, event, scan.offlineScanRequest, scan.at, uploadedAt)
+		record.TicketID = ticket.TicketID
+		if reason == "" {
+			reason = scope.allows(ticket.TicketCategoryID, scan.at)
+		}
+
+		var category models.TicketCategory
+		if reason == "" {
+			var err error
+			category, err = offlineCategory(tx, categories, ticket.TicketCategoryID)
+			if err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to fetch ticket category",
+				})
+			}
+			if outsideCategoryWindow(category, event, scan.at) {
+				reason = models.CheckInOutsideWindow
+			}
+		}
+
+		switch {
+		case reason != "":
+			record.Result = "rejected"
+			record.Reason = reason
+
+		case ticket.Status == "active":
+			// check-out tidak ikut tersinkron, jadi anti-passback tidak dicek di sini
+			entitlement, denial, err := consumeEntitlement(tx, &ticket, category, event, scan.at, user.UserID, req.Gate, false)
+			if err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to check in ticket",
+				})
+			}
+			switch denial {
+			case "":
+				record.Result = "accepted"
+				if err := tx.Model(&models.TicketEntry{}).
+					Where("ticket_id = ? AND entitlement = ?", ticket.TicketID, entitlement.Consumed).
+					Update("offline_scan_id", record.OfflineScanID).Error; err != nil {
+					tx.Rollback()
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+						"error": "Failed to check in ticket",
+					})
+				}
+			case "already_used":
+				record.Result = "conflict"
+				record.Reason = "already_used"
+				conflicts = append(conflicts, entitlementConflict(tx, ticket, entitlement, record))
+			default:
+				record.Result = "rejected"
+				record.Reason = denial
+			}
+
+		case ticket.Status == "used":
+			conflict, err := reconcileOfflineScan(tx, &ticket, &record)
+			if err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to reconcile scan",
+				})
+			}
+			conflicts = append(conflicts, conflict)
+
+		default:
+			record.Result = "rejected"
+			record.Reason = "ticket " + ticket.Status
+		}
+
+		if err := tx.Create(&record).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to store scan",
+			})
+		}
+
+		results = append(results, offlineScanResult{
+			ScanID:    scan.ScanID,
+			TicketID:  ticket.TicketID,
+			Result:    record.Result,
+			Reason:    record.Reason,
+			ScannedAt: scan.at,
+			UsedAt:    ticket.UsedAt,
+		})
+
+		entries = append(entries, models.CheckInLog{
+			EventID:          event.EventID,
+			TicketID:         ticket.TicketID,
+			TicketCategoryID: ticket.TicketCategoryID,
+			ScannerID:        user.UserID,
+			DeviceID:         req.DeviceID,
+			Gate:             req.Gate,
+			Code:             scan.Code,
+			Outcome:          offlineOutcome(record.Result, record.Reason),
+			Message:          record.Reason,
+			Source:           "offline",
+			ScannedAt:        scan.at,
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	for _, entry := range entries {
+		recordCheckIn(entry)
+	}
+
+	summary := fiber.Map{"accepted": 0, "conflict": 0, "rejected": 0}
+	for _, result := range results {
+		if count, ok := summary[result.Result].(int); ok {
+			summary[result.Result] = count + 1
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":   "Offline scans synced",
+		"device_id": req.DeviceID,
+		"processed": len(results),
+		"summary":   summary,
+		"results":   results,
+		"conflicts": conflicts,
+	})
+}
+
+// resolveOfflineScan mencari tiket dari scan offline. Hanya kode bertanda
+// tangan yang diterima. Token divalidasi terhadap waktu scan karena nonce
+// rotasi mungkin sudah berganti sejak itu, tetapi waktu scan dibatasi
+// offlineMaxWindow sebelum batch diunggah.
+func resolveOfflineScan(tx *gorm.DB, event models.Event, scan offlineScanRequest, at time.Time, uploadedAt time.Time) (models.Ticket, string) {
+	var ticket models.Ticket
+
+	if at.After(uploadedAt.Add(offlineClockSkew)) {
+		return ticket, "scanned_at is in the future"
+	}
+	if at.Before(uploadedAt.Add(-offlineMaxWindow)) {
+		return ticket, "scanned_at is older than the offline window"
+	}
+
+	if !utils.IsTicketToken(scan.Code) {
+		return ticket, "invalid"
+	}
+	claims, err := utils.ParseTicketCodeAt(scan.Code, at)
+	if err != nil {
+		if utils.IsTicketTokenExpired(err) {
+			return ticket, "code_expired"
+		}
+		return ticket, "invalid"
+	}
+	if claims.EventID != event.EventID || (scan.TicketID != "" && scan.TicketID != claims.TicketID) {
+		return ticket, "invalid"
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&ticket, "ticket_id = ? AND event_id = ?", claims.TicketID, event.EventID).Error; err != nil {
+		return ticket, "invalid"
+	}
+
+	// nonce rotasi harus yang berlaku saat dipindai; nonce PDF/wallet yang
+	// sudah diganti berarti salinan itu dicabut
+	if strings.HasPrefix(claims.Nonce, "tix") {
+		if !ticketCodeValidAt(tx, ticket, claims.Nonce, at) {
+			return ticket, "code_revoked"
+		}
+	} else if !ticketNonceValid(tx, ticket, claims.Nonce) {
+		return ticket, "code_revoked"
+	}
+
+	if checkInOpensAt(event).After(at) {
+		return ticket, "not_started"
+	}
+	if checkInClosesAt(event).Before(at) {
+		return ticket, "expired"
+	}
+
+	return ticket, ""
+}
+
+// ticketCodeValidAt - nonce rotasi adalah kode aktif tiket, atau kode lama yang
+// baru diganti setelah waktu at
+func ticketCodeValidAt(tx *gorm.DB, ticket models.Ticket, nonce string, at time.Time) bool {
+	if nonce == ticket.Code {
+		return true
+	}
+	var count int64
+	tx.Model(&models.TicketCodeHistory{}).
+		Where("ticket_id = ? AND code = ? AND superseded_at > ?", ticket.TicketID, nonce, at).
+		Count(&count)
+	return count > 0
+}
+
+// offlineCategory - kategori tiket dengan cache per batch sinkronisasi
+func offlineCategory(tx *gorm.DB, cache map[string]models.TicketCategory, ticketCategoryID string) (models.TicketCategory, error) {
+	if category, ok := cache[ticketCategoryID]; ok {
+		return category, nil
+	}
+
+	var category models.TicketCategory
+	if err := tx.First(&category, "ticket_category_id = ?", ticketCategoryID).Error; err != nil {
+		return category, err
+	}
+	cache[ticketCategoryID] = category
+	return category, nil
+}
+
+// entitlementConflict - conflict untuk tiket harian/sesi yang hak masuknya sudah
+// dipakai lebih dulu. Pemenang diambil dari ticket entry yang sudah ada.
+func entitlementConflict(tx *gorm.DB, ticket models.Ticket, entitlement entitlementResult, record models.OfflineScan) offlineScanConflict {
+	conflict := offlineScanConflict{
+		TicketID:    ticket.TicketID,
+		LoserDevice: record.DeviceID,
+		LoserScanID: record.ScanID,
+		LoserTime:   record.ScannedAt,
+	}
+
+	var winner models.TicketEntry
+	if err := tx.Where("ticket_id = ? AND entitlement = ?", ticket.TicketID, entitlement.Consumed).First(&winner).Error; err != nil {
+		return conflict
+	}
+	conflict.WinnerTime = winner.CheckedInAt
+	conflict.WinnerScanner = winner.ScannerID
+
+	// entry dari scan offline: perangkat dan scan_id diambil dari OfflineScan-nya
+	var scan models.OfflineScan
+	if winner.OfflineScanID != "" && tx.First(&scan, "offline_scan_id = ?", winner.OfflineScanID).Error == nil {
+		conflict.WinnerDevice = scan.DeviceID
+		conflict.WinnerScanID = scan.ScanID
+	}
+	return conflict
+}
+
+// reconcileOfflineScan menerapkan aturan "scan pertama menang" untuk tiket yang
+// sudah dipakai. Jika scan ini lebih awal, scan ini menjadi pemenang baru dan
+// used_at tiket dimundurkan; counter tidak berubah.
+func reconcileOfflineScan(tx *gorm.DB, ticket *models.Ticket, record *models.OfflineScan) (offlineScanConflict, error) {
+	var winner models.OfflineScan
+	hasWinner := tx.Where("ticket_id = ? AND result = ?", ticket.TicketID, "accepted").First(&winner).Error == nil
+
+	usedAt := ticketUsedAt(*ticket)
+	conflict := offlineScanConflict{TicketID: ticket.TicketID}
+	if usedAt != nil {
+		conflict.WinnerTime = *usedAt
+	}
+	if hasWinner {
+		conflict.WinnerDevice = winner.DeviceID
+		conflict.WinnerScanID = winner.ScanID
+		conflict.WinnerTime = winner.ScannedAt
+	}
+
+	earlier := usedAt != nil && record.ScannedAt.Before(*usedAt)
+	if hasWinner && record.ScannedAt.Equal(winner.ScannedAt) {
+		earlier = record.DeviceID < winner.DeviceID ||
+			(record.DeviceID == winner.DeviceID && record.ScanID < winner.ScanID)
+	}
+
+	if !earlier {
+		record.Result = "conflict"
+		record.Reason = "already_used"
+		conflict.LoserDevice = record.DeviceID
+		conflict.LoserScanID = record.ScanID
+		conflict.LoserTime = record.ScannedAt
+		return conflict, nil
+	}
+
+	if hasWinner {
+		if err := tx.Model(&models.OfflineScan{}).
+			Where("offline_scan_id = ?", winner.OfflineScanID).
+			Updates(map[string]interface{}{
+				"result": "conflict",
+				"reason": "superseded by earlier scan",
+			}).Error; err != nil {
+			return conflict, err
+		}
+	}
+
+	at := record.ScannedAt
+	ticket.UsedAt = &at
+	if err := tx.Model(&models.Ticket{}).
+		Where("ticket_id = ?", ticket.TicketID).
+		Update("used_at", ticket.UsedAt).Error; err != nil {
+		return conflict, err
+	}
+
+	record.Result = "accepted"
+	conflict.LoserDevice = conflict.WinnerDevice
+	conflict.LoserScanID = conflict.WinnerScanID
+	conflict.LoserTime = conflict.WinnerTime
+	conflict.WinnerDevice = record.DeviceID
+	conflict.WinnerScanID = record.ScanID
+	conflict.WinnerTime = record.ScannedAt
+	conflict.WinnerChanged = true
+	return conflict, nil
+}
+
+// UpdateCheckInSettings - Atur grace period (menit) sebelum dan sesudah jendela check-in
+func UpdateCheckInSettings(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		GraceBefore *uint `json:"check_in_grace_before"`
+		GraceAfter  *uint `json:"check_in_grace_after"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	updates := map[string]interface{}{}
+	if req.GraceBefore != nil {
+		if *req.GraceBefore > 1440 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "check_in_grace_before must be at most 1440 minutes",
+			})
+		}
+		updates["check_in_grace_before"] = *req.GraceBefore
+		event.CheckInGraceBefore = *req.GraceBefore
+	}
+	if req.GraceAfter != nil {
+		if *req.GraceAfter > 1440 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "check_in_grace_after must be at most 1440 minutes",
+			})
+		}
+		updates["check_in_grace_after"] = *req.GraceAfter
+		event.CheckInGraceAfter = *req.GraceAfter
+	}
+
+	if len(updates) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Nothing to update",
+		})
+	}
+
+	if err := config.DB.Model(&models.Event{}).Where("event_id = ?", event.EventID).Updates(updates).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update check-in settings",
+		})
+	}
+
+	windows := make([]fiber.Map, 0, len(event.TicketCategories))
+	for _, category := range event.TicketCategories {
+		from, until, ok := categoryWindow(category, event)
+		if !ok {
+			continue
+		}
+		windows = append(windows, fiber.Map{
+			"ticket_category_id": category.TicketCategoryID,
+			"ticket_category":    category.Name,
+			"valid_from":         from,
+			"valid_until":        until,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":               "Check-in settings updated successfully",
+		"check_in_grace_before": event.CheckInGraceBefore,
+		"check_in_grace_after":  event.CheckInGraceAfter,
+		"gate_opens_at":         checkInOpensAt(event),
+		"gate_closes_at":        checkInClosesAt(event),
+		"category_windows":      windows,
+	})
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// recordCheckIn menyimpan satu percobaan scan ke audit trail. Ditulis di luar
+// transaksi check-in agar scan yang ditolak (rollback) tetap tercatat.
+func recordCheckIn(entry models.CheckInLog) {
+	if entry.Outcome == "" {
+		return
+	}
+
+	entry.CheckInLogID = utils.GenerateCheckInLogID()
+	if err := config.DB.Create(&entry).Error; err != nil {
+		log.Printf("Failed to record check-in log for event %s: %v", entry.EventID, err)
+	}
+
+	publishLiveScan(entry)
+}
+
+// offlineOutcome memetakan hasil sinkronisasi offline ke outcome audit trail
+func offlineOutcome(result string, reason string) string {
+	switch result {
+	case "accepted":
+		return models.CheckInSuccess
+	case "conflict":
+		return models.CheckInAlreadyUsed
+	}
+
+	switch reason {
+	case "not_started", "no_active_session":
+		return models.CheckInNotStarted
+	case "expired":
+		return models.CheckInExpired
+	case models.CheckInOutsideWindow:
+		return models.CheckInOutsideWindow
+	case "outside_shift", "category_not_assigned":
+		return models.CheckInUnauthorized
+	}
+	return models.CheckInInvalid
+}
+
+// GetCheckInLogs - Log scan event dengan filter untuk menyelesaikan sengketa di pintu masuk
+func GetCheckInLogs(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("event_id"))
+	if err != nil {
+		return err
+	}
+
+	query := config.DB.Model(&models.CheckInLog{}).Where("event_id = ?", event.EventID)
+
+	filters := map[string]string{
+		"outcome":            "outcome = ?",
+		"gate":               "gate = ?",
+		"device_id":          "device_id = ?",
+		"scanner_id":         "scanner_id = ?",
+		"ticket_id":          "ticket_id = ?",
+		"ticket_category_id": "ticket_category_id = ?",
+		"source":             "source = ?",
+	}
+	for param, condition := range filters {
+		if value := c.Query(param); value != "" {
+			query = query.Where(condition, value)
+		}
+	}
+
+	if q := c.Query("q"); q != "" {
+		like := "%" + q + "%"
+		query = query.Where("ticket_id LIKE ? OR code LIKE ? OR message LIKE ?", like, like, like)
+	}
+
+	if from := c.Query("from"); from != "" {
+		fromTime, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid from format. Use RFC3339 format",
+			})
+		}
+		query = query.Where("scanned_at >= ?", fromTime)
+	}
+
+	if to := c.Query("to"); to != "" {
+		toTime, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid to format. Use RFC3339 format",
+			})
+		}
+		query = query.Where("scanned_at <= ?", toTime)
+	}
+
+	logs, pagination, err := paginate(c, query, checkInLogListSpec, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Scanner")
+	})
+	if err != nil {
+		return listError(c, err, "Failed to fetch check-in logs")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Check-in logs retrieved successfully",
+		"logs":       logs,
+		"pagination": pagination,
+	})
+}
+
+// GetCheckInStats - Statistik per gate: jumlah per outcome dan throughput per interval
+func GetCheckInStats(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("event_id"))
+	if err != nil {
+		return err
+	}
+
+	interval, err := strconv.Atoi(c.Query("interval", "15"))
+	if err != nil || interval < 1 || interval > 1440 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid interval, must be between 1 and 1440 minutes",
+		})
+	}
+	bucketSeconds := interval * 60
+
+	type gateOutcome struct {
+		Gate      string    `json:"gate"`
+		Outcome   string    `json:"outcome"`
+		Total     int64     `json:"total"`
+		FirstScan time.Time `json:"first_scan"`
+		LastScan  time.Time `json:"last_scan"`
+	}
+
+	var rows []gateOutcome
+	if err := config.DB.Model(&models.CheckInLog{}).
+		Select("gate, outcome, COUNT(*) AS total, MIN(scanned_at) AS first_scan, MAX(scanned_at) AS last_scan").
+		Where("event_id = ?", event.EventID).
+		Group("gate, outcome").
+		Scan(&rows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch check-in stats",
+		})
+	}
+
+	type gateStats struct {
+		Gate           string           `json:"gate"`
+		Total          int64            `json:"total"`
+		Outcomes       map[string]int64 `json:"outcomes"`
+		FirstScan      time.Time        `json:"first_scan"`
+		LastScan       time.Time        `json:"last_scan"`
+		ScansPerMinute float64          `json:"scans_per_minute"`
+	}
+
+	gates := make(map[string]*gateStats)
+	var order []string
+	for _, row := range rows {
+		stats, exists := gates[row.Gate]
+		if !exists {
+			stats = &gateStats{Gate: row.Gate, Outcomes: make(map[string]int64), FirstScan: row.FirstScan, LastScan: row.LastScan}
+			gates[row.Gate] = stats
+			order = append(order, row.Gate)
+		}
+		stats.Total += row.Total
+		stats.Outcomes[row.Outcome] = row.Total
+		if row.FirstScan.Before(stats.FirstScan) {
+			stats.FirstScan = row.FirstScan
+		}
+		if row.LastScan.After(stats.LastScan) {
+			stats.LastScan = row.LastScan
+		}
+	}
+
+	gateList := make([]gateStats, 0, len(order))
+	for _, gate := range order {
+		stats := gates[gate]
+		minutes := stats.LastScan.Sub(stats.FirstScan).Minutes()
+		if minutes < 1 {
+			minutes = 1
+		}
+		stats.ScansPerMinute = float64(stats.Total) / minutes
+		gateList = append(gateList, *stats)
+	}
+
+	type throughputRow struct {
+		Gate    string `json:"gate"`
+		Bucket  int64  `json:"-"`
+		Success int64  `json:"success"`
+		Total   int64  `json:"total"`
+	}
+
+	var buckets []throughputRow
+	if err := config.DB.Model(&models.CheckInLog{}).
+		Select("gate, FLOOR(UNIX_TIMESTAMP(scanned_at) / ?) AS bucket, SUM(outcome = ?) AS success, COUNT(*) AS total", bucketSeconds, models.CheckInSuccess).
+		Where("event_id = ?", event.EventID).
+		Group("gate, bucket").
+		Order("bucket ASC").
+		Scan(&buckets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch check-in throughput",
+		})
+	}
+
+	throughput := make([]fiber.Map, 0, len(buckets))
+	for _, bucket := range buckets {
+		throughput = append(throughput, fiber.Map{
+			"gate":         bucket.Gate,
+			"bucket_start": time.Unix(bucket.Bucket*int64(bucketSeconds), 0),
+			"success":      bucket.Success,
+			"total":        bucket.Total,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"event_id":         event.EventID,
+		"interval_minutes": interval,
+		"gates":            gateList,
+		"throughput":       throughput,
+	})
+}
@@ -0,0 +1,658 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// batas jumlah hari event yang dihitung untuk tiket harian
+const maxEventDays = 366
+
+// entitlementResult - hak masuk yang dipakai oleh satu check-in dan sisanya
+type entitlementResult struct {
+	Mode        string   `json:"mode"`
+	Consumed    string   `json:"consumed"`
+	Label       string   `json:"label,omitempty"`
+	EntriesUsed int64    `json:"entries_used"`
+	Remaining   int      `json:"remaining"` // -1 = tanpa batas
+	Upcoming    []string `json:"upcoming,omitempty"`
+}
+
+type EntrySessionRequest struct {
+	Name              string   `json:"name"`
+	StartsAt          string   `json:"starts_at"`
+	EndsAt            string   `json:"ends_at"`
+	TicketCategoryIDs []string `json:"ticket_category_ids"`
+}
+
+// validEntryMode memvalidasi entry mode kategori tiket; mode kosong berarti single
+func validEntryMode(mode string) bool {
+	switch mode {
+	case "", models.EntryModeSingle, models.EntryModeMultiple, models.EntryModeDaily, models.EntryModeSession:
+		return true
+	}
+	return false
+}
+
+func entryModeOf(mode string) string {
+	if mode == "" {
+		return models.EntryModeSingle
+	}
+	return mode
+}
+
+// entryDate - tanggal lokal (YYYY-MM-DD) untuk rekap kehadiran per hari
+func entryDate(at time.Time) string {
+	return at.In(time.Local).Format("2006-01-02")
+}
+
+// eventDays - semua tanggal lokal antara DateStart dan DateEnd event
+func eventDays(event models.Event) []string {
+	start := event.DateStart.In(time.Local)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	last := entryDate(event.DateEnd)
+
+	var days []string
+	for i := 0; i < maxEventDays; i++ {
+		date := day.AddDate(0, 0, i).Format("2006-01-02")
+		days = append(days, date)
+		if date >= last {
+			break
+		}
+	}
+	return days
+}
+
+// sessionAllows - sesi tanpa daftar kategori berlaku untuk semua kategori
+func sessionAllows(session models.EntrySession, ticketCategoryID string) bool {
+	if len(session.TicketCategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range session.TicketCategoryIDs {
+		if id == ticketCategoryID {
+			return true
+		}
+	}
+	return false
+}
+
+func categorySessions(tx *gorm.DB, eventID string, ticketCategoryID string) ([]models.EntrySession, error) {
+	var sessions []models.EntrySession
+	if err := tx.Where("event_id = ?", eventID).Order("starts_at ASC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	var allowed []models.EntrySession
+	for _, session := range sessions {
+		if sessionAllows(session, ticketCategoryID) {
+			allowed = append(allowed, session)
+		}
+	}
+	return allowed, nil
+}
+
+// consumeEntitlement memakai satu hak masuk tiket sesuai entry mode kategorinya.
+// Mengembalikan alasan penolakan (already_used, already_inside, no_active_session)
+// atau "" jika berhasil. Counter attendant hanya naik pada entry pertama tiket.
+// antiPassback menolak entry baru selama tiket belum check-out. Untuk tiket
+// harian/sesi, anti-passback hanya berlaku dalam hak masuk yang sama; entry
+// hari/sesi sebelumnya yang tidak di-scan keluar ditutup otomatis.
+func consumeEntitlement(tx *gorm.DB, ticket *models.Ticket, category models.TicketCategory, event models.Event, at time.Time, scannerID string, gate string, antiPassback bool) (entitlementResult, string, error) {
+	mode := entryModeOf(category.EntryMode)
+	result := entitlementResult{Mode: mode}
+
+	if ticket.Status == "used" {
+		return result, "already_used", nil
+	}
+	windowed := mode == models.EntryModeDaily || mode == models.EntryModeSession
+	if antiPassback && ticket.InsideVenue && mode != models.EntryModeSingle && !windowed {
+		return result, "already_inside", nil
+	}
+
+	var used int64
+	if err := tx.Model(&models.TicketEntry{}).Where("ticket_id = ?", ticket.TicketID).Count(&used).Error; err != nil {
+		return result, "", err
+	}
+
+	date := entryDate(at)
+	switch mode {
+	case models.EntryModeMultiple:
+		if category.MaxEntries > 0 && used >= int64(category.MaxEntries) {
+			return result, "already_used", nil
+		}
+		result.Consumed = fmt.Sprintf("entry:%d", used+1)
+	case models.EntryModeDaily:
+		result.Consumed = "day:" + date
+		result.Label = date
+	case models.EntryModeSession:
+		sessions, err := categorySessions(tx, event.EventID, ticket.TicketCategoryID)
+		if err != nil {
+			return result, "", err
+		}
+		for _, session := range sessions {
+			if !at.Before(session.StartsAt) && !at.After(session.EndsAt) {
+				result.Consumed = "session:" + session.EntrySessionID
+				result.Label = session.Name
+				break
+			}
+		}
+		if result.Consumed == "" {
+			return result, "no_active_session", nil
+		}
+	default:
+		result.Consumed = "entry:1"
+	}
+
+	if windowed && antiPassback && ticket.InsideVenue {
+		inside, err := closeStaleEntries(tx, ticket, result.Consumed, at)
+		if err != nil {
+			return result, "", err
+		}
+		if inside {
+			return result, "already_inside", nil
+		}
+	}
+
+	if windowed {
+		var existing models.TicketEntry
+		if err := tx.Where("ticket_id = ? AND entitlement = ?", ticket.TicketID, result.Consumed).First(&existing).Error; err == nil {
+			return result, "already_used", nil
+		}
+	}
+
+	if err := tx.Create(&models.TicketEntry{
+		TicketEntryID:    utils.GenerateTicketEntryID(),
+		TicketID:         ticket.TicketID,
+		EventID:          ticket.EventID,
+		TicketCategoryID: ticket.TicketCategoryID,
+		Entitlement:      result.Consumed,
+		EntryDate:        date,
+		CheckedInAt:      at,
+		ScannerID:        scannerID,
+		Gate:             gate,
+	}).Error; err != nil {
+		return result, "", err
+	}
+	result.EntriesUsed = used + 1
+
+	first := used == 0
+	updates := map[string]interface{}{"inside_venue": true}
+	ticket.InsideVenue = true
+	if first {
+		ticket.UsedAt = &at
+		updates["used_at"] = at
+	}
+	if err := tx.Model(&models.Ticket{}).Where("ticket_id = ?", ticket.TicketID).Updates(updates).Error; err != nil {
+		return result, "", err
+	}
+
+	if mode == models.EntryModeSingle || (mode == models.EntryModeMultiple && category.MaxEntries > 0 && result.EntriesUsed >= int64(category.MaxEntries)) {
+		if err := ticketLifecycle.transition(tx, ticket.TicketID, ticket.Status, "used", scannerID, "entries consumed", nil); err != nil {
+			return result, "", err
+		}
+		ticket.Status = "used"
+	}
+
+	if first {
+		if err := tx.Model(&models.TicketCategory{}).
+			Where("ticket_category_id = ?", ticket.TicketCategoryID).
+			UpdateColumn("attendant", gorm.Expr("attendant + ?", 1)).Error; err != nil {
+			return result, "", err
+		}
+
+		if err := tx.Model(&models.Event{}).
+			Where("event_id = ?", ticket.EventID).
+			UpdateColumn("total_attendant", gorm.Expr("total_attendant + ?", 1)).Error; err != nil {
+			return result, "", err
+		}
+	}
+
+	remaining, upcoming, err := remainingEntitlements(tx, *ticket, category, event, at)
+	if err != nil {
+		return result, "", err
+	}
+	result.Remaining = remaining
+	result.Upcoming = upcoming
+
+	return result, "", nil
+}
+
+// closeStaleEntries menutup entry terbuka dari hari/sesi lain milik tiket
+// (holder pulang tanpa scan keluar). Mengembalikan true jika holder masih di
+// dalam pada hak masuk yang sama.
+func closeStaleEntries(tx *gorm.DB, ticket *models.Ticket, entitlement string, at time.Time) (bool, error) {
+	var open []models.TicketEntry
+	if err := tx.Where("ticket_id = ? AND checked_out_at IS NULL", ticket.TicketID).Find(&open).Error; err != nil {
+		return false, err
+	}
+
+	var stale []string
+	for _, entry := range open {
+		if entry.Entitlement == entitlement {
+			return true, nil
+		}
+		stale = append(stale, entry.TicketEntryID)
+	}
+
+	if len(stale) > 0 {
+		if err := tx.Model(&models.TicketEntry{}).Where("ticket_entry_id IN ?", stale).Update("checked_out_at", at).Error; err != nil {
+			return false, err
+		}
+	}
+	if err := tx.Model(&models.Ticket{}).Where("ticket_id = ?", ticket.TicketID).Update("inside_venue", false).Error; err != nil {
+		return false, err
+	}
+	ticket.InsideVenue = false
+	return false, nil
+}
+
+// remainingEntitlements - sisa hak masuk tiket setelah waktu at
+func remainingEntitlements(tx *gorm.DB, ticket models.Ticket, category models.TicketCategory, event models.Event, at time.Time) (int, []string, error) {
+	var entries []models.TicketEntry
+	if err := tx.Where("ticket_id = ?", ticket.TicketID).Find(&entries).Error; err != nil {
+		return 0, nil, err
+	}
+	consumed := make(map[string]bool)
+	for _, entry := range entries {
+		consumed[entry.Entitlement] = true
+	}
+
+	switch entryModeOf(category.EntryMode) {
+	case models.EntryModeMultiple:
+		if category.MaxEntries == 0 {
+			return -1, nil, nil
+		}
+		remaining := int(category.MaxEntries) - len(entries)
+		if remaining < 0 {
+			remaining = 0
+		}
+		return remaining, nil, nil
+
+	case models.EntryModeDaily:
+		today := entryDate(at)
+		var upcoming []string
+		for _, day := range eventDays(event) {
+			if day >= today && !consumed["day:"+day] {
+				upcoming = append(upcoming, day)
+			}
+		}
+		return len(upcoming), upcoming, nil
+
+	case models.EntryModeSession:
+		sessions, err := categorySessions(tx, event.EventID, ticket.TicketCategoryID)
+		if err != nil {
+			return 0, nil, err
+		}
+		var upcoming []string
+		for _, session := range sessions {
+			if session.EndsAt.After(at) && !consumed["session:"+session.EntrySessionID] {
+				upcoming = append(upcoming, session.Name)
+			}
+		}
+		return len(upcoming), upcoming, nil
+	}
+
+	if ticket.Status == "used" || len(entries) > 0 {
+		return 0, nil, nil
+	}
+	return 1, nil, nil
+}
+
+// entitlementDenial - pesan untuk alasan penolakan consumeEntitlement
+func entitlementDenial(reason string) (string, string) {
+	switch reason {
+	case "already_inside":
+		return models.CheckInAlreadyInside, "Ticket holder is already inside, check out first before re-entry"
+	case "no_active_session":
+		return models.CheckInNotStarted, "No entry session is open for this ticket right now"
+	}
+	return models.CheckInAlreadyUsed, "Ticket already used"
+}
+
+func CheckOutTicket(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+	eventID := c.Params("event_id")
+	codeEvent := c.Params("id")
+
+	var req struct {
+		DeviceID string `json:"device_id"`
+		Gate     string `json:"gate"`
+	}
+	c.BodyParser(&req)
+	if req.DeviceID == "" {
+		req.DeviceID = c.Get("X-Device-ID")
+	}
+	if req.Gate == "" {
+		req.Gate = c.Get("X-Gate")
+	}
+
+	entry := models.CheckInLog{
+		EventID:   eventID,
+		ScannerID: user.UserID,
+		DeviceID:  req.DeviceID,
+		Gate:      req.Gate,
+		Code:      codeEvent,
+		Source:    "online",
+		ScannedAt: time.Now(),
+	}
+
+	status, body := performCheckOut(user, eventID, codeEvent, &entry)
+	recordCheckIn(entry)
+
+	return c.Status(status).JSON(body)
+}
+
+// performCheckOut menutup entry terakhir tiket yang masih di dalam venue
+func performCheckOut(user models.User, eventID string, codeEvent string, entry *models.CheckInLog) (int, fiber.Map) {
+	now := entry.ScannedAt
+
+	var event models.Event
+	if err := config.DB.First(&event, "event_id = ?", eventID).Error; err != nil {
+		entry.Outcome, entry.Message = models.CheckInInvalid, "event not found"
+		return fiber.StatusNotFound, fiber.Map{
+			"error": "Event not found",
+		}
+	}
+
+	scope, ok := loadScanScope(user, event)
+	if !ok {
+		entry.Outcome, entry.Message = models.CheckInUnauthorized, "not assigned to event"
+		return fiber.StatusForbidden, fiber.Map{
+			"error": "Not authorized to check out tickets for this event",
+		}
+	}
+
+	tx := config.DB.Begin()
+	if err := tx.Error; err != nil {
+		return fiber.StatusInternalServerError, fiber.Map{
+			"error": "Failed to start transaction",
+		}
+	}
+
+	ticket, err := resolveTicketCode(tx, eventID, codeEvent)
+	entry.TicketID = ticket.TicketID
+	entry.TicketCategoryID = ticket.TicketCategoryID
+	if err != nil {
+		tx.Rollback()
+		entry.Outcome, entry.Message = models.CheckInInvalid, "ticket not found"
+		return fiber.StatusNotFound, fiber.Map{
+			"error": "Ticket not found or Ticket code invalid",
+		}
+	}
+
+	if reason := scope.allows(ticket.TicketCategoryID, now); reason != "" {
+		tx.Rollback()
+		entry.Outcome, entry.Message = models.CheckInUnauthorized, reason
+		return fiber.StatusForbidden, fiber.Map{
+			"error":  "You are not assigned to check out this ticket",
+			"status": reason,
+		}
+	}
+
+	var open models.TicketEntry
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("ticket_id = ? AND checked_out_at IS NULL", ticket.TicketID).
+		Order("checked_in_at DESC").
+		First(&open).Error; err != nil {
+		tx.Rollback()
+		entry.Outcome, entry.Message = models.CheckInInvalid, "not_inside"
+		return fiber.StatusNotAcceptable, fiber.Map{
+			"error":  "Ticket holder is not checked in",
+			"status": "not_inside",
+		}
+	}
+
+	open.CheckedOutAt = &now
+	if err := tx.Model(&open).Update("checked_out_at", now).Error; err != nil {
+		tx.Rollback()
+		return fiber.StatusInternalServerError, fiber.Map{
+			"error": "Failed to check out ticket",
+		}
+	}
+
+	if err := tx.Model(&models.Ticket{}).Where("ticket_id = ?", ticket.TicketID).Update("inside_venue", false).Error; err != nil {
+		tx.Rollback()
+		return fiber.StatusInternalServerError, fiber.Map{
+			"error": "Failed to check out ticket",
+		}
+	}
+	ticket.InsideVenue = false
+
+	var ticketCategory models.TicketCategory
+	tx.First(&ticketCategory, "ticket_category_id = ?", ticket.TicketCategoryID)
+
+	remaining, upcoming, err := remainingEntitlements(tx, ticket, ticketCategory, event, now)
+	if err != nil {
+		tx.Rollback()
+		return fiber.StatusInternalServerError, fiber.Map{
+			"error": "Failed to compute remaining entries",
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fiber.StatusInternalServerError, fiber.Map{
+			"error": "Failed to commit transaction",
+		}
+	}
+
+	entry.Outcome, entry.Message = models.CheckOutSuccess, open.Entitlement
+	return fiber.StatusOK, fiber.Map{
+		"message": "Ticket checked out successfully",
+		"status":  "checked_out",
+		"ticket": fiber.Map{
+			"ticket_id":       ticket.TicketID,
+			"status":          ticket.Status,
+			"ticket_category": ticketCategory.Name,
+			"entitlement":     open.Entitlement,
+			"checked_in_at":   open.CheckedInAt,
+			"checked_out_at":  open.CheckedOutAt,
+			"remaining":       remaining,
+			"upcoming":        upcoming,
+		},
+	}
+}
+
+// GetEventAttendance - Rekap kehadiran per hari dan per kategori tiket
+func GetEventAttendance(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("event_id"))
+	if err != nil {
+		return err
+	}
+
+	type attendanceRow struct {
+		EntryDate        string `json:"entry_date"`
+		TicketCategoryID string `json:"ticket_category_id"`
+		Attendees        int64  `json:"attendees"`
+		Entries          int64  `json:"entries"`
+		Inside           int64  `json:"inside"`
+	}
+
+	var rows []attendanceRow
+	if err := config.DB.Model(&models.TicketEntry{}).
+		Select("entry_date, ticket_category_id, COUNT(DISTINCT ticket_id) AS attendees, COUNT(*) AS entries, SUM(checked_out_at IS NULL) AS inside").
+		Where("event_id = ?", event.EventID).
+		Group("entry_date, ticket_category_id").
+		Order("entry_date ASC").
+		Scan(&rows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch attendance",
+		})
+	}
+
+	categoryNames := make(map[string]string)
+	for _, category := range event.TicketCategories {
+		categoryNames[category.TicketCategoryID] = category.Name
+	}
+
+	type dayAttendance struct {
+		Date       string                   `json:"date"`
+		Attendees  int64                    `json:"attendees"`
+		Entries    int64                    `json:"entries"`
+		Inside     int64                    `json:"inside"`
+		Categories []map[string]interface{} `json:"categories"`
+	}
+
+	days := make([]dayAttendance, 0)
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, exists := index[row.EntryDate]
+		if !exists {
+			days = append(days, dayAttendance{Date: row.EntryDate})
+			i = len(days) - 1
+			index[row.EntryDate] = i
+		}
+		days[i].Attendees += row.Attendees
+		days[i].Entries += row.Entries
+		days[i].Inside += row.Inside
+		days[i].Categories = append(days[i].Categories, map[string]interface{}{
+			"ticket_category_id": row.TicketCategoryID,
+			"ticket_category":    categoryNames[row.TicketCategoryID],
+			"attendees":          row.Attendees,
+			"entries":            row.Entries,
+			"inside":             row.Inside,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":         "Attendance retrieved successfully",
+		"event_id":        event.EventID,
+		"total_attendant": event.TotalAttendant,
+		"days":            days,
+	})
+}
+
+// GetEntrySessions - Daftar sesi masuk event
+func GetEntrySessions(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var sessions []models.EntrySession
+	if err := config.DB.Where("event_id = ?", event.EventID).Order("starts_at ASC").Find(&sessions).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch entry sessions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Entry sessions retrieved successfully",
+		"sessions": sessions,
+	})
+}
+
+// CreateEntrySession - Tambah sesi masuk untuk tiket dengan entry mode session
+func CreateEntrySession(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req EntrySessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	if req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session name is required",
+		})
+	}
+
+	startsAt, err := time.Parse(time.RFC3339, req.StartsAt)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid starts_at format. Use RFC3339 format",
+		})
+	}
+
+	endsAt, err := time.Parse(time.RFC3339, req.EndsAt)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ends_at format. Use RFC3339 format",
+		})
+	}
+
+	if !endsAt.After(startsAt) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ends_at must be after starts_at",
+		})
+	}
+
+	validCategories := make(map[string]bool)
+	for _, category := range event.TicketCategories {
+		validCategories[category.TicketCategoryID] = true
+	}
+	for _, id := range req.TicketCategoryIDs {
+		if !validCategories[id] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Ticket category " + id + " does not belong to this event",
+			})
+		}
+	}
+
+	session := models.EntrySession{
+		EntrySessionID:    utils.GenerateEntrySessionID(),
+		EventID:           event.EventID,
+		Name:              req.Name,
+		StartsAt:          startsAt,
+		EndsAt:            endsAt,
+		TicketCategoryIDs: req.TicketCategoryIDs,
+	}
+
+	if err := config.DB.Create(&session).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create entry session",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Entry session created successfully",
+		"session": session,
+	})
+}
+
+// DeleteEntrySession - Hapus sesi masuk yang belum pernah dipakai
+func DeleteEntrySession(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	sessionID := c.Params("session_id")
+
+	var used int64
+	config.DB.Model(&models.TicketEntry{}).Where("entitlement = ?", "session:"+sessionID).Count(&used)
+	if used > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Entry session already has check-ins",
+		})
+	}
+
+	result := config.DB.Where("entry_session_id = ? AND event_id = ?", sessionID, event.EventID).Delete(&models.EntrySession{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete entry session",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Entry session not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Entry session deleted successfully",
+	})
+}
@@ -17,12 +17,15 @@ import (
 )
 
 type TicketCategoryRequest struct {
-	Name          string  `json:"name"`
-	Price         float64 `json:"price"`
-	Quota         uint    `json:"quota"`
-	Description   string  `json:"description"`
-	DateTimeStart string  `json:"date_time_start"`
-	DateTimeEnd   string  `json:"date_time_end"`
+	TicketCategoryID string  `json:"ticket_category_id"`
+	Name             string  `json:"name"`
+	Price            float64 `json:"price"`
+	Quota            uint    `json:"quota"`
+	Description      string  `json:"description"`
+	DateTimeStart    string  `json:"date_time_start"`
+	DateTimeEnd      string  `json:"date_time_end"`
+	EntryMode        string  `json:"entry_mode"`
+	MaxEntries       uint    `json:"max_entries"`
 }
 
 func CreateEvent(c *fiber.Ctx) error {
@@ -38,6 +41,8 @@ func CreateEvent(c *fiber.Ctx) error {
 	category := c.FormValue("category")
 	childCategory := c.FormValue("child_category")
 	ticketCategoriesJSON := c.FormValue("ticket_categories")
+	// draft=true menyimpan event tanpa dikirim ke review admin
+	draft := c.FormValue("draft") == "true"
 
 	var ticketCategories []TicketCategoryRequest
 	if ticketCategoriesJSON != "" {
@@ -48,8 +53,13 @@ func CreateEvent(c *fiber.Ctx) error {
 		}
 	}
 
-	// Validasi required fields
-	if name == "" || dateStartStr == "" || dateEndStr == "" || location == "" || venue == "" || district == "" {
+	// Validasi required fields, draft cukup nama dan tanggal
+	if draft && (name == "" || dateStartStr == "" || dateEndStr == "") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Missing required fields: name, date_start, date_end",
+		})
+	}
+	if !draft && (name == "" || dateStartStr == "" || dateEndStr == "" || location == "" || venue == "" || district == "") {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Missing required fields: name, date_start, date_end, location, venue, district",
 		})
@@ -70,6 +80,29 @@ func CreateEvent(c *fiber.Ctx) error {
 		})
 	}
 
+	publishAt, msg := parsePublishAt(c.FormValue("publish_at"))
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	// Koordinat venue; jika tidak diisi dicoba lewat geocoder
+	latitude, longitude, msg := parseCoordinates(c.FormValue("latitude"), c.FormValue("longitude"))
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+	if latitude == nil {
+		latitude, longitude = geocodeVenue(venue, location, district)
+	}
+
+	status := "pending"
+	if draft {
+		status = "draft"
+	}
+
 	// Handle image upload
 	var imageURL, flyerURL string
 
@@ -116,12 +149,14 @@ func CreateEvent(c *fiber.Ctx) error {
 		EventID:       utils.GenerateEventID(),
 		Name:          name,
 		OwnerID:       user.UserID,
-		Status:        "pending",
+		Status:        status,
 		DateStart:     dateStart,
 		DateEnd:       dateEnd,
 		Location:      location,
 		Venue:         venue,
 		District:      district,
+		Latitude:      latitude,
+		Longitude:     longitude,
 		Description:   description,
 		Rules:         rules,
 		TotalLikes:    0,
@@ -129,6 +164,7 @@ func CreateEvent(c *fiber.Ctx) error {
 		Flyer:         flyerURL,
 		Category:      category,
 		ChildCategory: childCategory,
+		PublishAt:     publishAt,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
@@ -159,6 +195,13 @@ func CreateEvent(c *fiber.Ctx) error {
 					"error": "Invalid date_time_end format in ticket category: " + err.Error(),
 				})
 			}
+
+			if !validEntryMode(tcReq.EntryMode) {
+				tx.Rollback()
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid entry_mode in ticket category: " + tcReq.Name,
+				})
+			}
 			var ticketName models.TicketCategory
 			if err := tx.Model(&ticketName).Where("name = ? && event_id = ?", tcReq.Name, event.EventID).First(&ticketName).Error; err == nil {
 				tx.Rollback()
@@ -176,6 +219,8 @@ func CreateEvent(c *fiber.Ctx) error {
 				Description:      tcReq.Description,
 				DateTimeStart:    dateTimeStart,
 				DateTimeEnd:      dateTimeEnd,
+				EntryMode:        entryModeOf(tcReq.EntryMode),
+				MaxEntries:       tcReq.MaxEntries,
 				CreatedAt:        time.Now(),
 				UpdatedAt:        time.Now(),
 			}
@@ -192,6 +237,13 @@ func CreateEvent(c *fiber.Ctx) error {
 		}
 	}
 
+	if err := refreshEventMinPrice(tx, event.EventID); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update event price: " + err.Error(),
+		})
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
@@ -211,8 +263,13 @@ func CreateEvent(c *fiber.Ctx) error {
 
 	// ScheduleEventEnd(config.DB, event)
 
+	message := "Event created successfully"
+	if draft {
+		message = "Draft saved successfully"
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Event created successfully",
+		"message": message,
 		"event":   eventWithOwner,
 	})
 }
@@ -235,12 +292,14 @@ func UpdateEvent(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if event can be edited (only pending or rejected)
-	if event.Status != "pending" && event.Status != "rejected" {
+	// Check if event can be edited (draft, pending, rejected, atau live lewat change-set)
+	live := containsString(liveEventStatuses, event.Status)
+	if event.Status != "draft" && event.Status != "pending" && event.Status != "rejected" && !live {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Event can only be edited when status is pending or rejected",
+			"error": "Event can only be edited when status is draft, pending, rejected, approved or active",
 		})
 	}
+	original := event
 
 	// Parse form data
 	name := c.FormValue("name")
@@ -255,6 +314,38 @@ func UpdateEvent(c *fiber.Ctx) error {
 	childCategory := c.FormValue("child_category")
 	ticketCategoriesJSON := c.FormValue("ticket_categories")
 
+	if live && ticketCategoriesJSON != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Ticket categories of a published event are managed through the ticket category endpoints",
+		})
+	}
+
+	// Koordinat eksplisit menang; alamat yang berubah tanpa koordinat di-geocode
+	// ulang sebelum transaksi dibuka. Jika geocode gagal, koordinat lama
+	// dihapus karena sudah tidak cocok dengan alamat baru.
+	latitude, longitude, msg := parseCoordinates(c.FormValue("latitude"), c.FormValue("longitude"))
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+	setCoordinates := latitude != nil
+	newVenue, newLocation, newDistrict := event.Venue, event.Location, event.District
+	if venue != "" {
+		newVenue = venue
+	}
+	if location != "" {
+		newLocation = location
+	}
+	if district != "" {
+		newDistrict = district
+	}
+	addressChanged := newVenue != event.Venue || newLocation != event.Location || newDistrict != event.District
+	if !setCoordinates && addressChanged {
+		latitude, longitude = geocodeVenue(newVenue, newLocation, newDistrict)
+		setCoordinates = true
+	}
+
 	// Mulai transaction
 	tx := config.DB.Begin()
 	if tx.Error != nil {
@@ -326,6 +417,25 @@ func UpdateEvent(c *fiber.Ctx) error {
 		event.DateEnd = dateEnd
 	}
 
+	if publishAtStr := c.FormValue("publish_at"); publishAtStr != "" {
+		publishAt, msg := parsePublishAt(publishAtStr)
+		if msg != "" {
+			tx.Rollback()
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": msg,
+			})
+		}
+		updateData["publish_at"] = publishAt
+		event.PublishAt = publishAt
+	}
+
+	if setCoordinates {
+		updateData["latitude"] = latitude
+		updateData["longitude"] = longitude
+		event.Latitude = latitude
+		event.Longitude = longitude
+	}
+
 	// Handle image upload
 	imageFile, err := c.FormFile("image")
 	if err == nil {
@@ -364,7 +474,13 @@ func UpdateEvent(c *fiber.Ctx) error {
 		}
 	}
 
-	updateData["status"] = "pending"
+	changes := eventFieldChanges(original, updateData)
+
+	// event yang sudah tayang tidak langsung diubah; edit disimpan sebagai
+	// change-set dan versi live tetap dipublikasikan selama direview
+	if live {
+		return submitLiveEventChanges(c, tx, original, user, updateData, changes)
+	}
 
 	// Update event
 	if err := tx.Model(&event).Updates(updateData).Error; err != nil {
@@ -374,6 +490,34 @@ func UpdateEvent(c *fiber.Ctx) error {
 		})
 	}
 
+	// event yang ditolak kembali menunggu review setelah diperbaiki; draft
+	// tetap draft sampai dikirim lewat SubmitEvent
+	if event.Status == "rejected" {
+		if err := eventLifecycle.transition(tx, event.EventID, event.Status, "pending", user.UserID, "event resubmitted", nil); err != nil {
+			tx.Rollback()
+			return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+				"error": "Failed to update event status: " + err.Error(),
+			})
+		}
+	}
+	if event.SeriesID != "" {
+		if err := resubmitSeries(tx, event.SeriesID, user.UserID); err != nil {
+			tx.Rollback()
+			return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+				"error": "Failed to update series status: " + err.Error(),
+			})
+		}
+	}
+
+	if len(changes) > 0 {
+		if _, err := createEventRevision(tx, event.EventID, user.UserID, models.RevisionApplied, changes); err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to record event revision",
+			})
+		}
+	}
+
 	// Handle ticket categories update if provided
 	if ticketCategoriesJSON != "" {
 		var ticketCategories []TicketCategoryRequest
@@ -384,54 +528,119 @@ func UpdateEvent(c *fiber.Ctx) error {
 			})
 		}
 
-		// Delete existing ticket categories
-		if err := tx.Where("event_id = ?", event.EventID).Delete(&models.TicketCategory{}).Error; err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to delete existing ticket categories",
-			})
+		// Kategori lama diperbarui di tempat agar ID, Sold dan Attendant
+		// tetap; kategori yang tidak dikirim lagi dihapus jika belum pernah
+		// terjual, selain itu diarsipkan
+		existing := make(map[string]models.TicketCategory)
+		for _, category := range event.TicketCategories {
+			existing[category.TicketCategoryID] = category
 		}
 
-		// Create new ticket categories
+		kept := make(map[string]bool)
 		for _, tcReq := range ticketCategories {
-			dateTimeStart, err := time.Parse(time.RFC3339, tcReq.DateTimeStart)
-			if err != nil {
+			ticketCategory, msg := newTicketCategory(event.EventID, tcReq)
+			if msg != "" {
 				tx.Rollback()
 				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid date_time_start format",
+					"error": msg,
 				})
 			}
 
-			dateTimeEnd, err := time.Parse(time.RFC3339, tcReq.DateTimeEnd)
-			if err != nil {
+			current, ok := existing[tcReq.TicketCategoryID]
+			if !ok {
+				if err := tx.Create(&ticketCategory).Error; err != nil {
+					tx.Rollback()
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+						"error": "Failed to create ticket category",
+					})
+				}
+				kept[ticketCategory.TicketCategoryID] = true
+				continue
+			}
+
+			if tcReq.Quota < minimumQuota(current) {
+				tx.Rollback()
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Quota can not be lower than tickets already sold: " + tcReq.Name,
+				})
+			}
+			if current.Seated && tcReq.Quota != current.Quota {
 				tx.Rollback()
 				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid date_time_end format",
+					"error": "Quota of a seated category follows its seat map: " + tcReq.Name,
 				})
 			}
 
-			ticketCategory := models.TicketCategory{
-				TicketCategoryID: utils.GenerateTicketCategoryID(),
-				EventID:          event.EventID,
-				Name:             tcReq.Name,
-				Price:            tcReq.Price,
-				Quota:            tcReq.Quota,
-				Description:      tcReq.Description,
-				DateTimeStart:    dateTimeStart,
-				DateTimeEnd:      dateTimeEnd,
-				CreatedAt:        time.Now(),
-				UpdatedAt:        time.Now(),
+			repriced := ticketCategory.Price != current.Price
+			if err := tx.Model(&current).Updates(map[string]interface{}{
+				"name":            ticketCategory.Name,
+				"price":           ticketCategory.Price,
+				"quota":           ticketCategory.Quota,
+				"description":     ticketCategory.Description,
+				"date_time_start": ticketCategory.DateTimeStart,
+				"date_time_end":   ticketCategory.DateTimeEnd,
+				"entry_mode":      ticketCategory.EntryMode,
+				"max_entries":     ticketCategory.MaxEntries,
+				"archived":        false,
+				"updated_at":      time.Now(),
+			}).Error; err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to update ticket category",
+				})
+			}
+			if repriced {
+				if err := repriceCarts(tx, current.TicketCategoryID, ticketCategory.Price); err != nil {
+					tx.Rollback()
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+						"error": "Failed to update cart prices",
+					})
+				}
+			}
+			kept[current.TicketCategoryID] = true
+		}
+
+		for id, category := range existing {
+			if kept[id] {
+				continue
 			}
 
-			if err := tx.Create(&ticketCategory).Error; err != nil {
+			var tickets int64
+			if err := tx.Model(&models.Ticket{}).Where("ticket_category_id = ?", id).Count(&tickets).Error; err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to check ticket category sales",
+				})
+			}
+
+			if err := tx.Where("ticket_category_id = ?", id).Delete(&models.Cart{}).Error; err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to clear carts",
+				})
+			}
+
+			if tickets > 0 || minimumQuota(category) > 0 {
+				err = tx.Model(&category).Update("archived", true).Error
+			} else {
+				err = tx.Delete(&category).Error
+			}
+			if err != nil {
 				tx.Rollback()
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to create ticket category",
+					"error": "Failed to remove ticket category",
 				})
 			}
 		}
 	}
 
+	if err := refreshEventMinPrice(tx, event.EventID); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update event price",
+		})
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
@@ -450,51 +659,86 @@ func UpdateEvent(c *fiber.Ctx) error {
 		})
 	}
 
+	// pass wallet yang sudah diterbitkan ikut diperbarui
+	go notifyEventWallets(updatedEvent.EventID)
+
 	return c.JSON(fiber.Map{
 		"message": "Event updated successfully",
 		"event":   updatedEvent,
 	})
 }
 
+// GetApprovedEvents - Listing event publik dengan pencarian, filter dan
+// pagination (?q, category, child_category, district, date_from, date_to,
+// min_price, max_price, free, serta parameter list bersama)
 func GetApprovedEvents(c *fiber.Ctx) error {
-	var events []models.Event
-	if err := config.DB.Preload("Owner").Preload("TicketCategories").Where("status IN (?)", []string{"active", "approved", "ended"}).Find(&events).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch events",
+	query := publicEvents(config.DB.Model(&models.Event{}))
+
+	query, msg := filterEvents(c, query)
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
 		})
 	}
 
-	return c.JSON(events)
+	spec := eventListSpec
+	spec.DefaultSort = "date"
+
+	events, pagination, err := paginate(c, query, spec, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Owner").Preload("TicketCategories")
+	})
+	if err != nil {
+		return listError(c, err, "Failed to fetch events")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Events retrieved successfully",
+		"data":       events,
+		"pagination": pagination,
+	})
 }
 
 func GetMyEvents(c *fiber.Ctx) error {
 	user := c.Locals("user").(models.User)
 
-	var events []models.Event
-	if err := config.DB.Preload("Owner").Preload("TicketCategories").
-		Where("owner_id = ?", user.UserID).
-		Order("created_at DESC").
-		Find(&events).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch your events",
-		})
+	query := config.DB.Model(&models.Event{}).Where("owner_id = ?", user.UserID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	events, pagination, err := paginate(c, query, eventListSpec, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Owner").Preload("TicketCategories")
+	})
+	if err != nil {
+		return listError(c, err, "Failed to fetch your events")
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Events retrieved successfully",
-		"events":  events,
+		"message":    "Events retrieved successfully",
+		"events":     events,
+		"pagination": pagination,
 	})
 }
 
 func GetEvents(c *fiber.Ctx) error {
-	var events []models.Event
-	if err := config.DB.Preload("Owner").Preload("TicketCategories").Preload("LikedBy").Find(&events).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch events",
-		})
+	// draft hanya terlihat oleh pemiliknya
+	query := config.DB.Model(&models.Event{}).Where("status <> ?", "draft")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	events, pagination, err := paginate(c, query, eventListSpec, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Owner").Preload("TicketCategories").Preload("LikedBy")
+	})
+	if err != nil {
+		return listError(c, err, "Failed to fetch events")
 	}
 
-	return c.JSON(events)
+	return c.JSON(fiber.Map{
+		"message":    "Events retrieved successfully",
+		"data":       events,
+		"pagination": pagination,
+	})
 }
 
 func GetEvent(c *fiber.Ctx) error {
@@ -502,7 +746,7 @@ func GetEvent(c *fiber.Ctx) error {
 
 	var event models.Event
 	if err := config.DB.Preload("Owner").Preload("TicketCategories").
-		Where("event_id = ?", eventID).
+		Where("event_id = ? AND status <> ?", eventID, "draft").
 		First(&event).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Event not found",
@@ -513,6 +757,7 @@ func GetEvent(c *fiber.Ctx) error {
 }
 
 func VerifyEvent(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
 	eventID := c.Params("id")
 
 	var event models.Event
@@ -533,15 +778,42 @@ func VerifyEvent(c *fiber.Ctx) error {
 		})
 	}
 
-	event.Status = req.Status
-	event.ApprovalComment = req.ApprovalComment
+	if req.Status != "approved" && req.Status != "rejected" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Status must be approved or rejected",
+		})
+	}
 
-	if err := config.DB.Save(&event).Error; err != nil {
+	// occurrence dari series diverifikasi bersama seluruh series
+	if event.SeriesID != "" {
+		return verifySeries(c, user, event.SeriesID, req.Status, req.ApprovalComment)
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to verify event",
+			"error": "Failed to start transaction",
 		})
 	}
 
+	if err := eventLifecycle.transition(tx, event.EventID, event.Status, req.Status, user.UserID, req.ApprovalComment, map[string]interface{}{
+		"approval_comment": req.ApprovalComment,
+	}); err != nil {
+		tx.Rollback()
+		return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+			"error": "Failed to verify event: " + err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	event.Status = req.Status
+	event.ApprovalComment = req.ApprovalComment
+
 	var eventWithOwner models.Event
 	if err := config.DB.Preload("Owner").Preload("TicketCategories").
 		Where("event_id = ?", event.EventID).
@@ -579,7 +851,31 @@ func DeleteEvent(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := config.DB.Delete(&event).Error; err != nil {
+	// event yang sudah punya penjualan dibatalkan lewat CancelEvent agar
+	// tiket dan transaksinya tidak yatim
+	var sales int64
+	if err := config.DB.Model(&models.TransactionDetail{}).
+		Where("ticket_category_id IN (?)",
+			config.DB.Model(&models.TicketCategory{}).Select("ticket_category_id").Where("event_id = ?", event.EventID)).
+		Count(&sales).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check event sales",
+		})
+	}
+	var tickets int64
+	if err := config.DB.Model(&models.Ticket{}).Where("event_id = ?", event.EventID).Count(&tickets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check event tickets",
+		})
+	}
+	if sales > 0 || tickets > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Event already has sales, cancel it instead of deleting",
+		})
+	}
+
+	// soft delete, admin bisa memulihkan dari trash sampai masa retensi habis
+	if _, err := softDelete(&models.Event{}, user.UserID, "event_id = ?", event.EventID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete event",
 		})
@@ -593,8 +889,8 @@ func DeleteEvent(c *fiber.Ctx) error {
 func GetEventsPopular(c *fiber.Ctx) error {
 
 	var events []models.Event
-	if err := config.DB.Preload("Owner").Preload("TicketCategories").
-		Where("status = ?", "approved").
+	if err := publicEvents(config.DB.Preload("Owner").Preload("TicketCategories")).
+		Where("events.status = ?", "approved").
 		Order("total_likes DESC").
 		Limit(6).
 		Find(&events).Error; err != nil {
@@ -618,6 +914,10 @@ type EventReportResponse struct {
 	TotalCheckins    int                   `json:"total_checkins"`
 	TotalLikes       uint                  `json:"total_likes"`
 	TotalQuota       int                   `json:"total_quota"`
+
+	// Tiket impor (dijual di luar platform) dipisah dari penjualan platform
+	ImportedData         []TicketCategoryStats `json:"imported_data"`
+	TotalTicketsImported int                   `json:"total_tickets_imported"`
 }
 
 type TicketCategoryStats struct {
@@ -652,9 +952,11 @@ func GetEventReport(c *fiber.Ctx) error {
 	purchaseData := make([]TicketCategoryStats, 0)
 	checkinData := make([]TicketCategoryStats, 0)
 	attendantData := make([]TicketCategoryStats, 0)
+	importedData := make([]TicketCategoryStats, 0)
 
 	// Calculate totals
 	var totalSold int64 = 0
+	var totalImported int64 = 0
 	var totalCheckedIn int64 = 0
 	var totalQuota int = 0
 	var totalIncome float64 = 0
@@ -666,6 +968,8 @@ func GetEventReport(c *fiber.Ctx) error {
 	for _, ticketCategory := range event.TicketCategories {
 		// Langsung ambil dari field Sold dan Attendant di TicketCategory
 		soldCount := int64(ticketCategory.Sold)
+		importedCount := int64(ticketCategory.Imported)
+		issuedCount := soldCount + importedCount
 		checkedInCount := int64(ticketCategory.Attendant)
 
 		// Log untuk debug setiap kategori
@@ -678,10 +982,15 @@ func GetEventReport(c *fiber.Ctx) error {
 			soldPercentage = (float64(soldCount) / float64(ticketCategory.Quota)) * 100
 		}
 
-		// Calculate percentage of check-ins for this category
+		importedPercentage := float64(0)
+		if ticketCategory.Quota > 0 {
+			importedPercentage = (float64(importedCount) / float64(ticketCategory.Quota)) * 100
+		}
+
+		// Calculate percentage of check-ins for this category (platform + impor)
 		checkinPercentage := float64(0)
-		if soldCount > 0 {
-			checkinPercentage = (float64(checkedInCount) / float64(soldCount)) * 100
+		if issuedCount > 0 {
+			checkinPercentage = (float64(checkedInCount) / float64(issuedCount)) * 100
 		}
 
 		// Calculate income for this category
@@ -695,10 +1004,18 @@ func GetEventReport(c *fiber.Ctx) error {
 			Percentage: soldPercentage,
 		})
 
+		importedData = append(importedData, TicketCategoryStats{
+			Name:       ticketCategory.Name,
+			Value:      int(importedCount),
+			Quota:      int(ticketCategory.Quota),
+			Price:      ticketCategory.Price,
+			Percentage: importedPercentage,
+		})
+
 		checkinData = append(checkinData, TicketCategoryStats{
 			Name:       ticketCategory.Name,
 			Value:      int(checkedInCount),
-			Quota:      int(soldCount),
+			Quota:      int(issuedCount),
 			Price:      ticketCategory.Price,
 			Percentage: checkinPercentage,
 		})
@@ -712,6 +1029,7 @@ func GetEventReport(c *fiber.Ctx) error {
 		})
 
 		totalSold += soldCount
+		totalImported += importedCount
 		totalCheckedIn += checkedInCount
 		totalQuota += int(ticketCategory.Quota)
 		totalIncome += categoryIncome
@@ -729,8 +1047,8 @@ func GetEventReport(c *fiber.Ctx) error {
 	}
 
 	attendanceRate := "0%"
-	if totalSold > 0 {
-		rate := (float64(totalCheckedIn) / float64(totalSold)) * 100
+	if totalSold+totalImported > 0 {
+		rate := (float64(totalCheckedIn) / float64(totalSold+totalImported)) * 100
 		attendanceRate = fmt.Sprintf("%.1f%%", rate)
 	}
 
@@ -738,6 +1056,7 @@ func GetEventReport(c *fiber.Ctx) error {
 	metrics := fiber.Map{
 		"total_attendant":    totalCheckedIn,
 		"total_tickets_sold": totalSold,
+		"total_imported":     totalImported,
 		"total_sales":        totalIncome,
 		"total_quota":        totalQuota,
 		"sold_percentage":    soldPercentage,
@@ -754,6 +1073,9 @@ func GetEventReport(c *fiber.Ctx) error {
 		TotalTicketsSold: int(totalSold),
 		TotalCheckins:    int(totalCheckedIn),
 		TotalQuota:       totalQuota,
+
+		ImportedData:         importedData,
+		TotalTicketsImported: int(totalImported),
 	}
 
 	return c.JSON(fiber.Map{
@@ -783,9 +1105,10 @@ func DownloadEventReport(c *fiber.Ctx) error {
 	}
 
 	// Generate CSV report
-	csvData := "Kategori_Tiket,Harga_Tiket,Kuota_Tiket,Tiket_Terjual,Persentase_Terjual,Tiket_Check_in,Persentase_Check_in,Pendapatan_Kategori\n"
+	csvData := "Kategori_Tiket,Harga_Tiket,Kuota_Tiket,Tiket_Terjual,Persentase_Terjual,Tiket_Impor,Tiket_Check_in,Persentase_Check_in,Pendapatan_Kategori\n"
 
 	var grandTotalSold int64 = 0
+	var grandTotalImported int64 = 0
 	var grandTotalCheckedIn int64 = 0
 	var grandTotalQuota int64 = 0
 	var grandTotalIncome float64 = 0
@@ -794,6 +1117,7 @@ func DownloadEventReport(c *fiber.Ctx) error {
 	for _, ticketCategory := range event.TicketCategories {
 		// Langsung ambil dari field Sold dan Attendant di TicketCategory
 		soldCount := int64(ticketCategory.Sold)
+		importedCount := int64(ticketCategory.Imported)
 		checkedInCount := int64(ticketCategory.Attendant)
 
 		// Log untuk debug
@@ -807,23 +1131,25 @@ func DownloadEventReport(c *fiber.Ctx) error {
 		}
 
 		checkInPercentage := float64(0)
-		if soldCount > 0 {
-			checkInPercentage = (float64(checkedInCount) / float64(soldCount)) * 100
+		if soldCount+importedCount > 0 {
+			checkInPercentage = (float64(checkedInCount) / float64(soldCount+importedCount)) * 100
 		}
 
 		categoryIncome := float64(soldCount) * ticketCategory.Price
 
-		csvData += fmt.Sprintf("%s,%.0f,%d,%d,%.2f%%,%d,%.2f%%,%.0f\n",
+		csvData += fmt.Sprintf("%s,%.0f,%d,%d,%.2f%%,%d,%d,%.2f%%,%.0f\n",
 			ticketCategory.Name,
 			ticketCategory.Price,
 			ticketCategory.Quota,
 			soldCount,
 			soldPercentage,
+			importedCount,
 			checkedInCount,
 			checkInPercentage,
 			categoryIncome)
 
 		grandTotalSold += soldCount
+		grandTotalImported += importedCount
 		grandTotalCheckedIn += checkedInCount
 		grandTotalQuota += int64(ticketCategory.Quota)
 		grandTotalIncome += categoryIncome
@@ -836,8 +1162,8 @@ func DownloadEventReport(c *fiber.Ctx) error {
 	}
 
 	overallCheckInPercentage := float64(0)
-	if grandTotalSold > 0 {
-		overallCheckInPercentage = (float64(grandTotalCheckedIn) / float64(grandTotalSold)) * 100
+	if grandTotalSold+grandTotalImported > 0 {
+		overallCheckInPercentage = (float64(grandTotalCheckedIn) / float64(grandTotalSold+grandTotalImported)) * 100
 	}
 
 	// Add summary section
@@ -848,6 +1174,7 @@ func DownloadEventReport(c *fiber.Ctx) error {
 	csvData += "\n"
 	csvData += fmt.Sprintf("Total Kuota Tiket:,%d\n", grandTotalQuota)
 	csvData += fmt.Sprintf("Total Tiket Terjual:,%d (%.2f%%)\n", grandTotalSold, overallSoldPercentage)
+	csvData += fmt.Sprintf("Total Tiket Impor:,%d\n", grandTotalImported)
 	csvData += fmt.Sprintf("Total Check-in:,%d (%.2f%%)\n", grandTotalCheckedIn, overallCheckInPercentage)
 	csvData += fmt.Sprintf("Total Pendapatan:,Rp %.0f\n", grandTotalIncome)
 	csvData += fmt.Sprintf("Total Like:,%d\n", event.TotalLikes)
@@ -937,18 +1264,22 @@ func AddLike(c *fiber.Ctx) error {
 func MyLikedEvent(c *fiber.Ctx) error {
 	user := c.Locals("user").(models.User)
 
-	var userFigure models.User
-	if err := config.DB.Model(&models.User{}).Preload("LikedEvents").Where("user_id = ?", user.UserID).Find(&userFigure).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Users event not found",
-		})
+	query := config.DB.Model(&models.Event{}).
+		Where("events.event_id IN (SELECT event_id FROM event_likes WHERE user_id = ?)", user.UserID)
+
+	spec := eventListSpec
+	spec.DefaultSort = "date"
+
+	events, pagination, err := paginate(c, query, spec, nil)
+	if err != nil {
+		return listError(c, err, "Failed to fetch liked events")
 	}
 
-	var number_of_likes = len(userFigure.LikedEvents)
 	return c.JSON(fiber.Map{
 		"message":         "Successfully",
-		"number_of_likes": number_of_likes,
-		"liked_event":     userFigure.LikedEvents,
+		"number_of_likes": pagination["total"],
+		"liked_event":     events,
+		"pagination":      pagination,
 	})
 
 }
@@ -963,12 +1294,10 @@ func ScheduleEventEnd(db *gorm.DB, event models.Event) {
 			time.Sleep(durationStart)
 		}
 
-		if err := db.Model(&event).
-			Where("event_id = ?", event.EventID).
-			Update("status", "active").Error; err != nil {
-
-			log.Println("Failed to update event start:", err)
+		if eventRescheduled(db, event) {
+			return
 		}
+		advanceEventStatus(db, event.EventID, []string{"approved"}, "active", "event started")
 	}()
 
 	go func() {
@@ -977,15 +1306,60 @@ func ScheduleEventEnd(db *gorm.DB, event models.Event) {
 			time.Sleep(durationEnd)
 		}
 
-		if err := db.Model(&event).
-			Where("event_id = ?", event.EventID).
-			Update("status", "ended").Error; err != nil {
-
-			log.Println("Failed to update event end:", err)
+		if eventRescheduled(db, event) {
+			return
 		}
+		advanceEventStatus(db, event.EventID, []string{"approved", "active"}, "ended", "event ended")
 	}()
 }
 
+// eventRescheduled - jadwal event berubah (mis. revisi tanggal disetujui)
+// selama goroutine menunggu; jadwal baru sudah punya goroutine sendiri
+func eventRescheduled(db *gorm.DB, event models.Event) bool {
+	var current models.Event
+	if err := db.Select("event_id", "date_start", "date_end").First(&current, "event_id = ?", event.EventID).Error; err != nil {
+		return false
+	}
+	return !current.DateStart.Equal(event.DateStart) || !current.DateEnd.Equal(event.DateEnd)
+}
+
+// advanceEventStatus - transisi otomatis dari scheduler. Status terbaru dibaca
+// ulang karena event bisa sudah berubah (mis. ditolak) selama goroutine menunggu.
+func advanceEventStatus(db *gorm.DB, eventID string, from []string, to string, reason string) {
+	var current models.Event
+	if err := db.Select("event_id", "status").First(&current, "event_id = ?", eventID).Error; err != nil {
+		log.Println("Failed to load event status:", err)
+		return
+	}
+
+	allowed := false
+	for _, status := range from {
+		if current.Status == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		log.Println("Failed to start transaction:", tx.Error)
+		return
+	}
+
+	if err := eventLifecycle.transition(tx, eventID, current.Status, to, systemActor, reason, nil); err != nil {
+		tx.Rollback()
+		log.Println("Failed to update event status to "+to+":", err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		log.Println("Failed to commit event status:", err)
+	}
+}
+
 func InitialScheduleEventEnd(db *gorm.DB) error {
 	var events []models.Event
 	if err := db.Model(&models.Event{}).Find(&events).Error; err != nil {
@@ -1180,6 +1554,7 @@ func AddEventCategoryAll(c *fiber.Ctx) error {
 }
 
 func DeleteSubCategoryEvent(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
 	var deleteReq map[string]interface{}
 
 	if err := c.BodyParser(&deleteReq); err != nil {
@@ -1188,7 +1563,7 @@ func DeleteSubCategoryEvent(c *fiber.Ctx) error {
 	}
 	deleteName, _ := deleteReq["child_category_event"].(string)
 
-	if err := config.DB.Model(&models.ChildEventCategory{}).Delete(&models.ChildEventCategory{}, "child_event_category_name = ?", deleteName).Error; err != nil {
+	if _, err := softDelete(&models.ChildEventCategory{}, user.UserID, "child_event_category_name = ?", deleteName); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete category: " + err.Error(),
 		})
@@ -1201,6 +1576,7 @@ func DeleteSubCategoryEvent(c *fiber.Ctx) error {
 }
 
 func DeleteCategoryEvent(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
 	var deleteReq map[string]interface{}
 
 	if err := c.BodyParser(&deleteReq); err != nil {
@@ -1222,7 +1598,7 @@ func DeleteCategoryEvent(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := config.DB.Model(&models.EventCategory{}).Delete(&categoryNameDelete, "event_category_id = ?", categoryNameDelete.EventCategoryID).Error; err != nil {
+	if _, err := softDelete(&models.EventCategory{}, user.UserID, "event_category_id = ?", categoryNameDelete.EventCategoryID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete category: " + err.Error(),
 		})
@@ -1252,8 +1628,9 @@ func InitializeDefaultCategories() error {
 		"Fashion & Beauty":     {"Fashion Expo", "Beauty Class", "Makeup Workshop", "Brand Launching"},
 	}
 
+	// kategori yang dihapus admin ikut dihitung agar tidak dibuat ulang
 	var count int64
-	if err := config.DB.Model(&models.EventCategory{}).Count(&count).Error; err != nil {
+	if err := config.DB.Unscoped().Model(&models.EventCategory{}).Count(&count).Error; err != nil {
 		return fmt.Errorf("failed to check existing categories: %w", err)
 	}
 
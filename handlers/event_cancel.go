@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+type EventCancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+type EventRescheduleRequest struct {
+	DateStart string `json:"date_start"`
+	DateEnd   string `json:"date_end"`
+	Reason    string `json:"reason"`
+}
+
+const defaultRefundWindowDays = 7
+
+// refundWindow - lama pemegang tiket masih bisa refund setelah tanggal baru
+// diumumkan (REFUND_WINDOW_DAYS)
+func refundWindow() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("REFUND_WINDOW_DAYS"))
+	if err != nil || days <= 0 {
+		days = defaultRefundWindowDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// eventRefundable - refund pemegang tiket dibuka selama event ditunda dan
+// setelah dijadwalkan ulang sampai refundable_until (paling lambat event mulai)
+func eventRefundable(event models.Event, now time.Time) bool {
+	if event.Status == "postponed" {
+		return true
+	}
+	return (event.Status == "approved" || event.Status == "active") &&
+		event.RefundableUntil != nil && now.Before(*event.RefundableUntil) && now.Before(event.DateStart)
+}
+
+// cancelEventSales membatalkan event beserta semua tiket pending/aktif,
+// mengosongkan keranjang dan membuat refund untuk setiap transaksi paid
+func cancelEventSales(tx *gorm.DB, event models.Event, actorID, reason string) ([]models.Refund, []models.Ticket, error) {
+	if err := eventLifecycle.transition(tx, event.EventID, event.Status, "cancelled", actorID, reason, nil); err != nil {
+		return nil, nil, err
+	}
+
+	var tickets []models.Ticket
+	if err := tx.Where("event_id = ? AND status IN ?", event.EventID, []string{"pending", "active"}).Find(&tickets).Error; err != nil {
+		return nil, nil, err
+	}
+
+	for _, from := range []string{"pending", "active"} {
+		if _, err := ticketLifecycle.transitionWhere(tx, from, "cancelled", actorID, "event cancelled", "event_id = ?", event.EventID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// item keranjang untuk event ini tidak bisa dibayar lagi
+	if err := tx.Where("ticket_category_id IN (?)",
+		tx.Model(&models.TicketCategory{}).Select("ticket_category_id").Where("event_id = ?", event.EventID),
+	).Delete(&models.Cart{}).Error; err != nil {
+		return nil, nil, err
+	}
+
+	active := make([]models.Ticket, 0, len(tickets))
+	for _, ticket := range tickets {
+		if ticket.Status == "active" {
+			active = append(active, ticket)
+		}
+	}
+
+	refunds, err := createRefunds(tx, event.EventID, active, "event cancelled: "+reason)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return refunds, active, nil
+}
+
+// cancelEvent - alur pembatalan bersama untuk event dan occurrence series
+func cancelEvent(c *fiber.Ctx, user models.User, event models.Event, reason, message string) error {
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	refunds, tickets, err := cancelEventSales(tx, event, user.UserID, reason)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+			"error": "Failed to cancel event: " + err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	event.Status = "cancelled"
+
+	text := fmt.Sprintf("%s on %s has been cancelled.", event.Name, event.DateStart.Format("02 Jan 2006"))
+	if reason != "" {
+		text += " Reason: " + reason + "."
+	}
+	text += " Your tickets are no longer valid and paid orders will be refunded automatically."
+
+	go func() {
+		processRefunds(refunds)
+		notifyTicketHolders(tickets, notice{
+			EventID: event.EventID,
+			Type:    models.NotificationEventCancelled,
+			Title:   event.Name + " has been cancelled",
+			Message: text,
+		})
+		notifyEventWallets(event.EventID)
+	}()
+
+	return c.JSON(fiber.Map{
+		"message":           message,
+		"event":             event,
+		"cancelled_tickets": len(tickets),
+		"refunds":           refunds,
+	})
+}
+
+// CancelEvent - Batalkan event: tiket tidak berlaku, transaksi paid direfund
+// lewat Midtrans dan pemegang tiket diberi tahu
+func CancelEvent(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req EventCancelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	return cancelEvent(c, user, event, req.Reason, "Event cancelled successfully")
+}
+
+// PostponeEvent - Tunda event. Tiket tetap berlaku; pemegang tiket bisa
+// mempertahankannya atau meminta refund lewat RefundTicket.
+func PostponeEvent(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req EventCancelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	if err := eventLifecycle.transition(config.DB, event.EventID, event.Status, "postponed", user.UserID, req.Reason, nil); err != nil {
+		return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+			"error": "Failed to postpone event: " + err.Error(),
+		})
+	}
+	event.Status = "postponed"
+
+	var tickets []models.Ticket
+	if err := config.DB.Where("event_id = ? AND status = ?", event.EventID, "active").Find(&tickets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load tickets",
+		})
+	}
+
+	text := fmt.Sprintf("%s has been postponed.", event.Name)
+	if req.Reason != "" {
+		text += " Reason: " + req.Reason + "."
+	}
+	text += " Your ticket stays valid for the new date, or you can request a refund from your ticket page."
+
+	go func() {
+		notifyTicketHolders(tickets, notice{
+			EventID: event.EventID,
+			Type:    models.NotificationEventPostponed,
+			Title:   event.Name + " has been postponed",
+			Message: text,
+		})
+		notifyEventWallets(event.EventID)
+	}()
+
+	return c.JSON(fiber.Map{
+		"message": "Event postponed successfully",
+		"event":   event,
+	})
+}
+
+// RescheduleEvent - Tetapkan tanggal baru event yang ditunda dan tayangkan lagi
+func RescheduleEvent(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req EventRescheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	dateStart, err := time.Parse(time.RFC3339, req.DateStart)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid date_start format. Use RFC3339 format (e.g., 2024-07-01T10:00:00Z)",
+		})
+	}
+	dateEnd, err := time.Parse(time.RFC3339, req.DateEnd)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid date_end format. Use RFC3339 format (e.g., 2024-07-01T23:00:00Z)",
+		})
+	}
+	if !dateEnd.After(dateStart) || !dateStart.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "New dates must be in the future and date_end must be after date_start",
+		})
+	}
+
+	// pemegang tiket baru bisa memutuskan setelah tahu tanggal baru
+	refundableUntil := time.Now().Add(refundWindow())
+	if refundableUntil.After(dateStart) {
+		refundableUntil = dateStart
+	}
+
+	if err := eventLifecycle.transition(config.DB, event.EventID, event.Status, "approved", user.UserID, "event rescheduled", map[string]interface{}{
+		"date_start":       dateStart,
+		"date_end":         dateEnd,
+		"refundable_until": refundableUntil,
+	}); err != nil {
+		return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+			"error": "Failed to reschedule event: " + err.Error(),
+		})
+	}
+
+	event.Status = "approved"
+	event.DateStart = dateStart
+	event.DateEnd = dateEnd
+	event.RefundableUntil = &refundableUntil
+	ScheduleEventEnd(config.DB, event)
+
+	var tickets []models.Ticket
+	if err := config.DB.Where("event_id = ? AND status = ?", event.EventID, "active").Find(&tickets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load tickets",
+		})
+	}
+
+	text := fmt.Sprintf("%s has a new date: %s - %s. Your ticket is valid for the new date, or you can request a refund until %s.",
+		event.Name, dateStart.Format("02 Jan 2006 15:04"), dateEnd.Format("02 Jan 2006 15:04"), refundableUntil.Format("02 Jan 2006 15:04"))
+	if req.Reason != "" {
+		text += " " + req.Reason
+	}
+
+	go func() {
+		notifyTicketHolders(tickets, notice{
+			EventID: event.EventID,
+			Type:    models.NotificationEventRescheduled,
+			Title:   event.Name + " has been rescheduled",
+			Message: text,
+		})
+		notifyEventWallets(event.EventID)
+	}()
+
+	return c.JSON(fiber.Map{
+		"message": "Event rescheduled successfully",
+		"event":   event,
+	})
+}
+
+// RefundTicket - Pemegang tiket event yang ditunda (atau baru dijadwalkan
+// ulang, sampai refundable_until) memilih refund. Tiket dibatalkan, kuota dan
+// kursinya dikembalikan, lalu dana direfund.
+func RefundTicket(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if ticket.Status != "active" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Only active tickets can be refunded",
+		})
+	}
+	if ticket.Source == models.TicketSourceImport {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Imported tickets are refunded by their original seller",
+		})
+	}
+
+	var event models.Event
+	if err := config.DB.Select("event_id", "name", "status", "date_start", "refundable_until").First(&event, "event_id = ?", ticket.EventID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+	if !eventRefundable(event, time.Now()) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Tickets can only be refunded while the event is postponed or until the refund deadline after rescheduling",
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	if err := ticketLifecycle.transition(tx, ticket.TicketID, ticket.Status, "cancelled", user.UserID, "refund requested", nil); err != nil {
+		tx.Rollback()
+		return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+			"error": "Failed to cancel ticket: " + err.Error(),
+		})
+	}
+
+	if err := tx.Model(&models.TicketCategory{}).
+		Where("ticket_category_id = ? AND sold > 0", ticket.TicketCategoryID).
+		Update("sold", gorm.Expr("sold - 1")).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update ticket category",
+		})
+	}
+	if err := tx.Model(&models.Event{}).
+		Where("event_id = ? AND total_tickets_sold > 0", ticket.EventID).
+		Update("total_tickets_sold", gorm.Expr("total_tickets_sold - 1")).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update event sold count",
+		})
+	}
+
+	if ticket.SeatID != "" {
+		if err := tx.Model(&models.EventSeat{}).
+			Where("seat_id = ? AND ticket_id = ?", ticket.SeatID, ticket.TicketID).
+			Updates(map[string]interface{}{
+				"status":          models.SeatAvailable,
+				"held_by":         "",
+				"ticket_id":       "",
+				"hold_expires_at": nil,
+			}).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to release seat",
+			})
+		}
+	}
+
+	refunds, err := createRefunds(tx, ticket.EventID, []models.Ticket{ticket}, "event postponed: "+event.Name)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create refund",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	go func() {
+		processRefunds(refunds)
+		notifyTicketWallets(ticket.TicketID)
+	}()
+
+	ticket.Status = "cancelled"
+
+	return c.JSON(fiber.Map{
+		"message": "Ticket cancelled and refund requested",
+		"ticket":  ticket,
+		"refunds": refunds,
+	})
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PublishAtRequest struct {
+	PublishAt string `json:"publish_at"`
+}
+
+type DuplicateEventRequest struct {
+	Name      string `json:"name"`
+	DateStart string `json:"date_start"`
+	DateEnd   string `json:"date_end"`
+}
+
+// parsePublishAt - publish_at opsional (RFC3339), string kosong berarti
+// event langsung tampil setelah disetujui
+func parsePublishAt(value string) (*time.Time, string) {
+	if value == "" {
+		return nil, ""
+	}
+	publishAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, "Invalid publish_at format. Use RFC3339 format (e.g., 2024-07-01T10:00:00Z)"
+	}
+	return &publishAt, ""
+}
+
+// eventSubmitProblems - daftar field yang masih kurang sebelum draft bisa
+// dikirim ke review admin
+func eventSubmitProblems(event models.Event) []string {
+	problems := []string{}
+
+	if event.Location == "" {
+		problems = append(problems, "location is required")
+	}
+	if event.Venue == "" {
+		problems = append(problems, "venue is required")
+	}
+	if event.District == "" {
+		problems = append(problems, "district is required")
+	}
+	if event.Category == "" {
+		problems = append(problems, "category is required")
+	}
+	if !event.DateEnd.After(event.DateStart) {
+		problems = append(problems, "date_end must be after date_start")
+	}
+	if !event.DateStart.After(time.Now()) {
+		problems = append(problems, "date_start must be in the future")
+	}
+	if event.PublishAt != nil && !event.PublishAt.Before(event.DateEnd) {
+		problems = append(problems, "publish_at must be before date_end")
+	}
+
+	if len(event.TicketCategories) == 0 {
+		problems = append(problems, "at least one ticket category is required")
+	}
+	for _, category := range event.TicketCategories {
+		if category.Quota == 0 {
+			problems = append(problems, "ticket category "+category.Name+" needs a quota")
+		}
+		if !category.DateTimeEnd.After(category.DateTimeStart) {
+			problems = append(problems, "ticket category "+category.Name+" sale end must be after sale start")
+		}
+		if category.DateTimeStart.After(event.DateEnd) {
+			problems = append(problems, "ticket category "+category.Name+" sale must start before the event ends")
+		}
+	}
+
+	return problems
+}
+
+// SubmitEvent - Kirim draft ke review admin setelah lolos validasi
+func SubmitEvent(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	var event models.Event
+	if err := config.DB.Preload("TicketCategories").
+		First(&event, "event_id = ? AND owner_id = ?", c.Params("id"), user.UserID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+
+	if event.Status != "draft" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Only draft events can be submitted",
+		})
+	}
+
+	if problems := eventSubmitProblems(event); len(problems) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":    "Event is not ready for review",
+			"problems": problems,
+		})
+	}
+
+	if err := eventLifecycle.transition(config.DB, event.EventID, event.Status, "pending", user.UserID, "event submitted", nil); err != nil {
+		return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+			"error": "Failed to submit event: " + err.Error(),
+		})
+	}
+	event.Status = "pending"
+
+	return c.JSON(fiber.Map{
+		"message": "Event submitted for review",
+		"event":   event,
+	})
+}
+
+// ScheduleEventPublish - Atur waktu tayang event. publish_at kosong berarti
+// event tampil begitu disetujui.
+func ScheduleEventPublish(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	var event models.Event
+	if err := config.DB.First(&event, "event_id = ? AND owner_id = ?", c.Params("id"), user.UserID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+
+	if !containsString([]string{"draft", "pending", "rejected", "approved"}, event.Status) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Publish time can not be changed for " + event.Status + " events",
+		})
+	}
+
+	var req PublishAtRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	publishAt, msg := parsePublishAt(req.PublishAt)
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+	if publishAt != nil && !publishAt.Before(event.DateEnd) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "publish_at must be before date_end",
+		})
+	}
+
+	// event yang sudah disetujui hanya berubah lewat change-set
+	if containsString(liveEventStatuses, event.Status) {
+		updateData := map[string]interface{}{
+			"publish_at": publishAt,
+			"updated_at": time.Now(),
+		}
+		tx := config.DB.Begin()
+		if tx.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to start transaction",
+			})
+		}
+		return submitLiveEventChanges(c, tx, event, user, updateData, eventFieldChanges(event, updateData))
+	}
+
+	if err := config.DB.Model(&event).Update("publish_at", publishAt).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update publish time",
+		})
+	}
+	event.PublishAt = publishAt
+
+	return c.JSON(fiber.Map{
+		"message": "Publish time updated successfully",
+		"event":   event,
+	})
+}
+
+// DuplicateEvent - Salin event sebagai draft baru dengan tanggal baru. Detail,
+// gambar, kategori tiket (counter direset) dan pertanyaan registrasi ikut
+// disalin; jadwal penjualan kategori digeser sejauh selisih tanggal mulai.
+func DuplicateEvent(c *fiber.Ctx) error {
+	source, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req DuplicateEventRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	dateStart, err := time.Parse(time.RFC3339, req.DateStart)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid date_start format. Use RFC3339 format (e.g., 2024-07-01T10:00:00Z)",
+		})
+	}
+
+	// date_end kosong mempertahankan durasi event asal
+	dateEnd := dateStart.Add(source.DateEnd.Sub(source.DateStart))
+	if req.DateEnd != "" {
+		if dateEnd, err = time.Parse(time.RFC3339, req.DateEnd); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid date_end format. Use RFC3339 format (e.g., 2024-07-01T23:00:00Z)",
+			})
+		}
+	}
+	if !dateEnd.After(dateStart) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "date_end must be after date_start",
+		})
+	}
+
+	name := req.Name
+	if name == "" {
+		name = source.Name
+	}
+	offset := dateStart.Sub(source.DateStart)
+
+	event := models.Event{
+		EventID:            utils.GenerateEventID(),
+		Name:               name,
+		OwnerID:            source.OwnerID,
+		Status:             "draft",
+		DateStart:          dateStart,
+		DateEnd:            dateEnd,
+		Location:           source.Location,
+		Venue:              source.Venue,
+		District:           source.District,
+		Latitude:           source.Latitude,
+		Longitude:          source.Longitude,
+		Description:        source.Description,
+		Rules:              source.Rules,
+		Image:              source.Image,
+		Flyer:              source.Flyer,
+		Category:           source.Category,
+		ChildCategory:      source.ChildCategory,
+		CheckInGraceBefore: source.CheckInGraceBefore,
+		CheckInGraceAfter:  source.CheckInGraceAfter,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	var questions []models.RegistrationQuestion
+	if err := config.DB.Where("event_id = ?", source.EventID).Order("position ASC").Find(&questions).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load registration questions",
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	if err := tx.Create(&event).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create event: " + err.Error(),
+		})
+	}
+
+	// ID kategori lama -> baru, untuk pertanyaan yang khusus satu kategori
+	categoryIDs := make(map[string]string)
+	for _, category := range source.TicketCategories {
+		if category.Archived {
+			continue
+		}
+
+		copied := models.TicketCategory{
+			TicketCategoryID: utils.GenerateTicketCategoryID(),
+			EventID:          event.EventID,
+			Name:             category.Name,
+			Price:            category.Price,
+			Quota:            category.Quota,
+			Description:      category.Description,
+			DateTimeStart:    category.DateTimeStart.Add(offset),
+			DateTimeEnd:      category.DateTimeEnd.Add(offset),
+			EntryMode:        category.EntryMode,
+			MaxEntries:       category.MaxEntries,
+			CreatedAt:        time.Now(),
+			UpdatedAt:        time.Now(),
+		}
+		if err := tx.Create(&copied).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to copy ticket category: " + err.Error(),
+			})
+		}
+		categoryIDs[category.TicketCategoryID] = copied.TicketCategoryID
+	}
+
+	for _, question := range questions {
+		categoryID := ""
+		if question.TicketCategoryID != "" {
+			var ok bool
+			if categoryID, ok = categoryIDs[question.TicketCategoryID]; !ok {
+				continue
+			}
+		}
+
+		question.QuestionID = utils.GenerateQuestionID()
+		question.EventID = event.EventID
+		question.TicketCategoryID = categoryID
+		question.CreatedAt = time.Now()
+		question.UpdatedAt = time.Now()
+		if err := tx.Create(&question).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to copy registration question: " + err.Error(),
+			})
+		}
+	}
+
+	if err := refreshEventMinPrice(tx, event.EventID); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update event price",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	var duplicated models.Event
+	if err := config.DB.Preload("Owner").Preload("TicketCategories").
+		First(&duplicated, "event_id = ?", event.EventID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load event data",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Event duplicated as draft",
+		"event":   duplicated,
+	})
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultNearbyRadiusKm = 10.0
+	maxNearbyRadiusKm     = 200.0
+)
+
+type NearbyEvent struct {
+	models.Event
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// nearbyHit - satu baris hasil pencarian radius sebelum event dimuat
+type nearbyHit struct {
+	EventID    string
+	DistanceKm float64
+}
+
+// parseCoordinates membaca pasangan latitude/longitude dari form. Keduanya
+// kosong berarti tidak diisi; hanya satu yang diisi dianggap tidak valid.
+func parseCoordinates(latStr, lngStr string) (*float64, *float64, string) {
+	if latStr == "" && lngStr == "" {
+		return nil, nil, ""
+	}
+	if latStr == "" || lngStr == "" {
+		return nil, nil, "latitude and longitude must be provided together"
+	}
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil {
+		return nil, nil, "Invalid latitude"
+	}
+	lng, err := strconv.ParseFloat(lngStr, 64)
+	if err != nil {
+		return nil, nil, "Invalid longitude"
+	}
+	if !utils.ValidCoordinates(lat, lng) {
+		return nil, nil, "latitude must be between -90 and 90 and longitude between -180 and 180"
+	}
+	return &lat, &lng, ""
+}
+
+// geocodeVenue mencari koordinat dari alamat venue lewat geocoder yang
+// dikonfigurasi. Gagal geocode tidak menggagalkan request, event hanya
+// tidak punya koordinat.
+func geocodeVenue(venue, location, district string) (*float64, *float64) {
+	if config.Geocoder == nil {
+		return nil, nil
+	}
+
+	var parts []string
+	for _, part := range []string{venue, location, district} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	address := strings.Join(parts, ", ")
+	lat, lng, err := config.Geocoder.Geocode(ctx, address)
+	if err != nil {
+		log.Printf("Failed to geocode %q: %v", address, err)
+		return nil, nil
+	}
+	if !utils.ValidCoordinates(lat, lng) {
+		return nil, nil
+	}
+	return &lat, &lng
+}
+
+// GetNearbyEvents - event publik dalam radius dari titik lat/lng, urut jarak
+// terdekat. Filter listing lain (q, category, tanggal, harga) tetap berlaku.
+func GetNearbyEvents(c *fiber.Ctx) error {
+	lat, lng, msg := parseCoordinates(c.Query("lat"), c.Query("lng"))
+	if msg == "" && lat == nil {
+		msg = "lat and lng are required"
+	}
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	radius := defaultNearbyRadiusKm
+	if raw := c.Query("radius_km"); raw != "" {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value <= 0 || value > maxNearbyRadiusKm {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "radius_km must be greater than 0 and at most 200",
+			})
+		}
+		radius = value
+	}
+
+	query, msg := filterEvents(c, publicEvents(config.DB.Model(&models.Event{})))
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	// bounding box memakai index koordinat, lalu jarak tepat per event
+	minLat, maxLat, minLng, maxLng := utils.BoundingBox(*lat, *lng, radius)
+	distance := utils.HaversineSQL("events.latitude", "events.longitude")
+	query = query.
+		Where("events.latitude BETWEEN ? AND ?", minLat, maxLat).
+		Where("events.longitude BETWEEN ? AND ?", minLng, maxLng).
+		Where(distance+" <= ?", *lat, *lat, *lng, radius)
+
+	// jarak dihitung di subquery sehingga paginate bisa mengurutkan dan
+	// memotong halaman di database; hanya event di halaman ini yang dimuat
+	nearbyQuery := config.DB.Table("(?) AS nearby",
+		query.Select("events.event_id, "+distance+" AS distance_km", *lat, *lat, *lng))
+	hits, pagination, err := paginate(c, nearbyQuery, nearbyListSpec, nil)
+	if err != nil {
+		return listError(c, err, "Failed to fetch events")
+	}
+
+	nearby := make([]NearbyEvent, 0, len(hits))
+	if len(hits) > 0 {
+		ids := make([]string, 0, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, hit.EventID)
+		}
+		var events []models.Event
+		if err := config.DB.Preload("Owner").Preload("TicketCategories").
+			Where("event_id IN ?", ids).
+			Find(&events).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch events",
+			})
+		}
+		byID := make(map[string]models.Event, len(events))
+		for _, event := range events {
+			byID[event.EventID] = event
+		}
+		for _, hit := range hits {
+			if event, ok := byID[hit.EventID]; ok {
+				nearby = append(nearby, NearbyEvent{Event: event, DistanceKm: hit.DistanceKm})
+			}
+		}
+	}
+
+	pagination["radius_km"] = radius
+	return c.JSON(fiber.Map{
+		"message":    "Events retrieved successfully",
+		"data":       nearby,
+		"pagination": pagination,
+	})
+}
@@ -0,0 +1,625 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Status event yang sudah tayang; edit-nya lewat change-set yang direview
+var liveEventStatuses = []string{"approved", "active"}
+
+// Field low-risk default yang langsung diterapkan tanpa review admin.
+// Bisa diganti lewat env EVENT_AUTO_APPROVE_FIELDS (dipisah koma).
+var defaultAutoApproveFields = []string{"description", "rules", "image", "flyer"}
+
+// Field tanggal di change-set, disimpan dalam RFC3339
+var revisionTimeFields = map[string]bool{"date_start": true, "date_end": true, "publish_at": true}
+
+var revisionFloatFields = map[string]bool{"latitude": true, "longitude": true}
+
+// Field change-set untuk kategori tiket event live: "ticket_category:<id>.<field>"
+// untuk harga dan jadwal penjualan, "ticket_category:new" (New berisi JSON
+// TicketCategoryRequest) untuk kategori baru.
+const (
+	categoryChangePrefix = "ticket_category:"
+	newCategoryChange    = categoryChangePrefix + "new"
+)
+
+// Kolom kategori tiket yang bisa diubah lewat change-set
+var revisionCategoryFields = []string{"price", "date_time_start", "date_time_end"}
+
+func categoryFieldChange(categoryID, field string) string {
+	return categoryChangePrefix + categoryID + "." + field
+}
+
+func categoryPriceChange(categoryID string) string {
+	return categoryFieldChange(categoryID, "price")
+}
+
+type ReviewRevisionRequest struct {
+	Status        string `json:"status"`
+	ReviewComment string `json:"review_comment"`
+}
+
+func autoApproveFields() []string {
+	value := os.Getenv("EVENT_AUTO_APPROVE_FIELDS")
+	if value == "" {
+		return defaultAutoApproveFields
+	}
+
+	fields := []string{}
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// revisionValue - nilai field sebagai string untuk disimpan di change-set
+func revisionValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// eventFieldValue - nilai live satu field event yang bisa diubah lewat UpdateEvent
+func eventFieldValue(event models.Event, field string) string {
+	switch field {
+	case "name":
+		return event.Name
+	case "location":
+		return event.Location
+	case "venue":
+		return event.Venue
+	case "district":
+		return event.District
+	case "description":
+		return event.Description
+	case "rules":
+		return event.Rules
+	case "category":
+		return event.Category
+	case "child_category":
+		return event.ChildCategory
+	case "image":
+		return event.Image
+	case "flyer":
+		return event.Flyer
+	case "date_start":
+		return revisionValue(event.DateStart)
+	case "date_end":
+		return revisionValue(event.DateEnd)
+	case "publish_at":
+		return revisionValue(event.PublishAt)
+	case "latitude":
+		return revisionValue(event.Latitude)
+	case "longitude":
+		return revisionValue(event.Longitude)
+	}
+
+	// kolom kategori dari TicketCategories yang sudah di-preload
+	for _, category := range event.TicketCategories {
+		switch field {
+		case categoryPriceChange(category.TicketCategoryID):
+			return strconv.FormatFloat(category.Price, 'f', -1, 64)
+		case categoryFieldChange(category.TicketCategoryID, "date_time_start"):
+			return revisionValue(category.DateTimeStart)
+		case categoryFieldChange(category.TicketCategoryID, "date_time_end"):
+			return revisionValue(category.DateTimeEnd)
+		}
+	}
+	return ""
+}
+
+// eventFieldChanges membandingkan data update dengan versi live dan hanya
+// mengembalikan field yang benar-benar berubah, urut nama field
+func eventFieldChanges(event models.Event, updateData map[string]interface{}) []models.FieldChange {
+	changes := []models.FieldChange{}
+	for field, value := range updateData {
+		if field == "updated_at" {
+			continue
+		}
+		old := eventFieldValue(event, field)
+		if next := revisionValue(value); next != old {
+			changes = append(changes, models.FieldChange{Field: field, Old: old, New: next})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// lowRiskChanges - true jika semua field di change-set boleh auto-approve
+func lowRiskChanges(changes []models.FieldChange) bool {
+	allowed := autoApproveFields()
+	for _, change := range changes {
+		if !containsString(allowed, change.Field) {
+			return false
+		}
+	}
+	return true
+}
+
+// revisionUpdates mengubah change-set kembali menjadi kolom update event
+func revisionUpdates(changes []models.FieldChange) (map[string]interface{}, error) {
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	for _, change := range changes {
+		if strings.HasPrefix(change.Field, categoryChangePrefix) {
+			continue
+		}
+		if revisionFloatFields[change.Field] {
+			if change.New == "" {
+				updates[change.Field] = nil
+				continue
+			}
+			value, err := strconv.ParseFloat(change.New, 64)
+			if err != nil {
+				return nil, err
+			}
+			updates[change.Field] = value
+			continue
+		}
+		if !revisionTimeFields[change.Field] {
+			updates[change.Field] = change.New
+			continue
+		}
+		if change.New == "" {
+			updates[change.Field] = nil
+			continue
+		}
+		value, err := time.Parse(time.RFC3339, change.New)
+		if err != nil {
+			return nil, err
+		}
+		updates[change.Field] = value
+	}
+	return updates, nil
+}
+
+// staleRevisionFields - field change-set yang nilai Old-nya sudah tidak sama
+// dengan versi live (berubah lewat jalur lain sejak change-set dibuat)
+func staleRevisionFields(event models.Event, changes []models.FieldChange) []string {
+	stale := []string{}
+	for _, change := range changes {
+		if change.Field == newCategoryChange {
+			continue
+		}
+		if eventFieldValue(event, change.Field) != change.Old {
+			stale = append(stale, change.Field)
+		}
+	}
+	return stale
+}
+
+// applyRevision menerapkan change-set yang disetujui: kolom event, harga
+// kategori dan kategori baru
+func applyRevision(tx *gorm.DB, eventID string, changes []models.FieldChange) error {
+	updates, err := revisionUpdates(changes)
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Event{}).Where("event_id = ?", eventID).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	categoriesChanged := false
+	for _, change := range changes {
+		switch {
+		case change.Field == newCategoryChange:
+			var req TicketCategoryRequest
+			if err := json.Unmarshal([]byte(change.New), &req); err != nil {
+				return err
+			}
+			category, msg := newTicketCategory(eventID, req)
+			if msg != "" {
+				return errors.New(msg)
+			}
+			if ticketCategoryNameTaken(tx, eventID, category.Name, "") {
+				return errors.New("duplicate ticket category name: " + category.Name)
+			}
+			if err := tx.Create(&category).Error; err != nil {
+				return err
+			}
+			categoriesChanged = true
+
+		case strings.HasPrefix(change.Field, categoryChangePrefix):
+			target := strings.TrimPrefix(change.Field, categoryChangePrefix)
+			dot := strings.LastIndex(target, ".")
+			if dot < 0 || !containsString(revisionCategoryFields, target[dot+1:]) {
+				return errors.New("unknown ticket category change: " + change.Field)
+			}
+			categoryID, column := target[:dot], target[dot+1:]
+
+			var value interface{}
+			if column == "price" {
+				price, err := strconv.ParseFloat(change.New, 64)
+				if err != nil {
+					return err
+				}
+				value = price
+				categoriesChanged = true
+			} else {
+				at, err := time.Parse(time.RFC3339, change.New)
+				if err != nil {
+					return err
+				}
+				value = at
+			}
+			if err := tx.Model(&models.TicketCategory{}).
+				Where("ticket_category_id = ? AND event_id = ?", categoryID, eventID).
+				Updates(map[string]interface{}{column: value, "updated_at": time.Now()}).Error; err != nil {
+				return err
+			}
+			if column == "price" {
+				if err := repriceCarts(tx, categoryID, value.(float64)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if categoriesChanged {
+		return refreshEventMinPrice(tx, eventID)
+	}
+	return nil
+}
+
+// revisionChangesSchedule - change-set mengubah jadwal mulai/selesai event
+func revisionChangesSchedule(changes []models.FieldChange) bool {
+	for _, change := range changes {
+		if change.Field == "date_start" || change.Field == "date_end" {
+			return true
+		}
+	}
+	return false
+}
+
+// createEventRevision menyimpan change-set dengan nomor versi berikutnya
+func createEventRevision(tx *gorm.DB, eventID, authorID, status string, changes []models.FieldChange) (models.EventRevision, error) {
+	var version uint
+	if err := tx.Model(&models.EventRevision{}).
+		Where("event_id = ?", eventID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error; err != nil {
+		return models.EventRevision{}, err
+	}
+
+	revision := models.EventRevision{
+		RevisionID: utils.GenerateRevisionID(),
+		EventID:    eventID,
+		Version:    version + 1,
+		AuthorID:   authorID,
+		Status:     status,
+		Changes:    changes,
+	}
+	if status == models.RevisionAutoApproved {
+		now := time.Now()
+		revision.ReviewerID = systemActor
+		revision.ReviewedAt = &now
+	}
+
+	return revision, tx.Create(&revision).Error
+}
+
+// pendingRevisionExists - satu event hanya boleh punya satu change-set yang
+// menunggu review
+func pendingRevisionExists(tx *gorm.DB, eventID string) bool {
+	var count int64
+	tx.Model(&models.EventRevision{}).
+		Where("event_id = ? AND status = ?", eventID, models.RevisionPending).
+		Count(&count)
+	return count > 0
+}
+
+// errRevisionPending - event live masih punya change-set yang menunggu review
+var errRevisionPending = errors.New("another change to this event is still under review")
+
+// recordLiveEventChanges menyimpan change-set untuk event live di dalam tx.
+// Change-set yang seluruhnya low-risk langsung diterapkan (auto_approved),
+// selain itu disimpan pending tanpa menyentuh event.
+func recordLiveEventChanges(tx *gorm.DB, eventID, authorID string, updateData map[string]interface{}, changes []models.FieldChange) (models.EventRevision, error) {
+	if pendingRevisionExists(tx, eventID) {
+		return models.EventRevision{}, errRevisionPending
+	}
+
+	status := models.RevisionPending
+	if lowRiskChanges(changes) {
+		status = models.RevisionAutoApproved
+		if err := tx.Model(&models.Event{}).Where("event_id = ?", eventID).Updates(updateData).Error; err != nil {
+			return models.EventRevision{}, err
+		}
+	}
+
+	return createEventRevision(tx, eventID, authorID, status, changes)
+}
+
+// submitLiveEventChanges - edit event live lewat change-set; versi yang
+// dipublikasikan tetap tayang sampai admin menyetujui perubahan.
+func submitLiveEventChanges(c *fiber.Ctx, tx *gorm.DB, event models.Event, user models.User, updateData map[string]interface{}, changes []models.FieldChange) error {
+	if len(changes) == 0 {
+		tx.Rollback()
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No changes to submit",
+		})
+	}
+
+	revision, err := recordLiveEventChanges(tx, event.EventID, user.UserID, updateData, changes)
+	if errors.Is(err, errRevisionPending) {
+		tx.Rollback()
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Another change to this event is still under review",
+		})
+	}
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to record event changes: " + err.Error(),
+		})
+	}
+	status := revision.Status
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	if status == models.RevisionPending {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message":  "Changes submitted for review, the published version stays live",
+			"revision": revision,
+		})
+	}
+
+	var updatedEvent models.Event
+	if err := config.DB.Preload("Owner").Preload("TicketCategories").
+		First(&updatedEvent, "event_id = ?", event.EventID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load updated event data",
+		})
+	}
+
+	go notifyEventWallets(updatedEvent.EventID)
+
+	return c.JSON(fiber.Map{
+		"message":  "Event updated successfully",
+		"event":    updatedEvent,
+		"revision": revision,
+	})
+}
+
+// GetEventRevisions - Riwayat change-set event, terbaru dulu (?status=)
+func GetEventRevisions(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	query := config.DB.Model(&models.EventRevision{}).Where("event_id = ?", event.EventID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	revisions, pagination, err := paginate(c, query, eventRevisionListSpec, nil)
+	if err != nil {
+		return listError(c, err, "Failed to fetch event revisions")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Event revisions retrieved successfully",
+		"revisions":  revisions,
+		"pagination": pagination,
+	})
+}
+
+// GetPendingRevisions - Antrian change-set yang menunggu review admin
+func GetPendingRevisions(c *fiber.Ctx) error {
+	query := config.DB.Model(&models.EventRevision{}).Where("status = ?", models.RevisionPending)
+
+	revisions, pagination, err := paginate(c, query, revisionQueueListSpec, nil)
+	if err != nil {
+		return listError(c, err, "Failed to fetch pending revisions")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Pending revisions retrieved successfully",
+		"revisions":  revisions,
+		"pagination": pagination,
+	})
+}
+
+// GetEventRevision - Detail change-set. Field "current" menunjukkan nilai
+// live saat ini, berguna jika event berubah setelah change-set dibuat.
+func GetEventRevision(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var revision models.EventRevision
+	if err := config.DB.First(&revision, "revision_id = ? AND event_id = ?", c.Params("revision_id"), event.EventID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Revision not found",
+		})
+	}
+
+	diff := make([]fiber.Map, 0, len(revision.Changes))
+	for _, change := range revision.Changes {
+		diff = append(diff, fiber.Map{
+			"field":    change.Field,
+			"old":      change.Old,
+			"new":      change.New,
+			"current":  eventFieldValue(event, change.Field),
+			"low_risk": containsString(autoApproveFields(), change.Field),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Event revision retrieved successfully",
+		"revision": revision,
+		"diff":     diff,
+	})
+}
+
+// ReviewEventRevision - Admin menyetujui atau menolak change-set. Change-set
+// yang disetujui diterapkan ke event live tanpa mengubah status event.
+func ReviewEventRevision(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	var req ReviewRevisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+	if req.Status != models.RevisionApproved && req.Status != models.RevisionRejected {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Status must be approved or rejected",
+		})
+	}
+
+	var revision models.EventRevision
+	if err := config.DB.First(&revision, "revision_id = ? AND event_id = ?", c.Params("revision_id"), c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Revision not found",
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	now := time.Now()
+	result := tx.Model(&models.EventRevision{}).
+		Where("revision_id = ? AND status = ?", revision.RevisionID, models.RevisionPending).
+		Updates(map[string]interface{}{
+			"status":         req.Status,
+			"reviewer_id":    user.UserID,
+			"review_comment": req.ReviewComment,
+			"reviewed_at":    now,
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to review revision",
+		})
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Revision is no longer pending",
+		})
+	}
+
+	if req.Status == models.RevisionApproved {
+		var current models.Event
+		if err := tx.Preload("TicketCategories").First(&current, "event_id = ?", revision.EventID).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to load event data",
+			})
+		}
+		if stale := staleRevisionFields(current, revision.Changes); len(stale) > 0 {
+			tx.Rollback()
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":        "Event has changed since this revision was submitted, reject it and ask for a new one",
+				"stale_fields": stale,
+			})
+		}
+
+		if err := applyRevision(tx, revision.EventID, revision.Changes); err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": "Failed to apply revision: " + err.Error(),
+			})
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	revision.Status = req.Status
+	revision.ReviewerID = user.UserID
+	revision.ReviewComment = req.ReviewComment
+	revision.ReviewedAt = &now
+
+	var event models.Event
+	if err := config.DB.Preload("Owner").Preload("TicketCategories").
+		First(&event, "event_id = ?", revision.EventID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load event data",
+		})
+	}
+
+	if req.Status == models.RevisionApproved {
+		if containsString(liveEventStatuses, event.Status) && revisionChangesSchedule(revision.Changes) {
+			ScheduleEventEnd(config.DB, event)
+		}
+		go notifyEventWallets(event.EventID)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Revision " + req.Status,
+		"revision": revision,
+		"event":    event,
+	})
+}
+
+// WithdrawEventRevision - Organizer menarik change-set yang belum direview
+func WithdrawEventRevision(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	result := config.DB.Model(&models.EventRevision{}).
+		Where("revision_id = ? AND event_id = ? AND status = ?", c.Params("revision_id"), event.EventID, models.RevisionPending).
+		Update("status", models.RevisionWithdrawn)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to withdraw revision",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Pending revision not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Revision withdrawn successfully",
+	})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Panjang kata minimum yang diindeks FULLTEXT InnoDB (innodb_ft_min_token_size)
+const fulltextMinToken = 3
+
+// Status event yang tampil di listing publik
+var publicEventStatuses = []string{"active", "approved", "ended", "postponed"}
+
+// publicEvents - event yang boleh tampil ke publik: status publik dan sudah
+// melewati publish_at jika organizer menjadwalkan publikasi
+func publicEvents(query *gorm.DB) *gorm.DB {
+	return query.Where("events.status IN ?", publicEventStatuses).
+		Where("(events.publish_at IS NULL OR events.publish_at <= ?)", time.Now())
+}
+
+// parseFilterDate menerima YYYY-MM-DD atau RFC3339. Tanggal tanpa jam
+// untuk batas akhir dihitung sampai akhir hari.
+func parseFilterDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return t, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// searchEvents menambahkan pencarian teks. Kata yang cukup panjang memakai
+// FULLTEXT (boolean mode, prefix match); kata pendek yang tidak diindeks
+// dicari dengan LIKE di nama, venue dan district.
+func searchEvents(query *gorm.DB, q string) *gorm.DB {
+	var fulltext []string
+	for _, term := range strings.Fields(q) {
+		term = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`+-<>()~*"@`, r) {
+				return -1
+			}
+			return r
+		}, term)
+		if term == "" {
+			continue
+		}
+
+		if utf8.RuneCountInString(term) >= fulltextMinToken {
+			fulltext = append(fulltext, "+"+term+"*")
+			continue
+		}
+
+		like := "%" + term + "%"
+		query = query.Where("(events.name LIKE ? OR events.venue LIKE ? OR events.district LIKE ?)", like, like, like)
+	}
+
+	if len(fulltext) > 0 {
+		query = query.Where("MATCH(events.name, events.venue, events.district, events.description) AGAINST (? IN BOOLEAN MODE)", strings.Join(fulltext, " "))
+	}
+	return query
+}
+
+// filterEvents menerapkan filter listing publik dari query string.
+// Mengembalikan pesan error untuk parameter yang tidak valid.
+func filterEvents(c *fiber.Ctx, query *gorm.DB) (*gorm.DB, string) {
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		query = searchEvents(query, q)
+	}
+
+	if category := c.Query("category"); category != "" {
+		query = query.Where("events.category = ?", category)
+	}
+	if childCategory := c.Query("child_category"); childCategory != "" {
+		query = query.Where("events.child_category = ?", childCategory)
+	}
+	if district := c.Query("district"); district != "" {
+		query = query.Where("events.district = ?", district)
+	}
+
+	// rentang tanggal: event yang berlangsung (sebagian) di dalam rentang
+	if from := c.Query("date_from"); from != "" {
+		t, err := parseFilterDate(from, false)
+		if err != nil {
+			return nil, "Invalid date_from, use YYYY-MM-DD or RFC3339"
+		}
+		query = query.Where("events.date_end >= ?", t)
+	}
+	if to := c.Query("date_to"); to != "" {
+		t, err := parseFilterDate(to, true)
+		if err != nil {
+			return nil, "Invalid date_to, use YYYY-MM-DD or RFC3339"
+		}
+		query = query.Where("events.date_start <= ?", t)
+	}
+
+	// rentang harga: event yang punya minimal satu kategori tiket di rentang
+	if c.Query("free") == "true" {
+		query = query.Where("EXISTS (SELECT 1 FROM ticket_categories tc WHERE " + pricedCategorySQL + " AND tc.price = 0)")
+	}
+	minPrice, maxPrice := c.Query("min_price"), c.Query("max_price")
+	if minPrice != "" || maxPrice != "" {
+		condition := "EXISTS (SELECT 1 FROM ticket_categories tc WHERE " + pricedCategorySQL
+		var args []interface{}
+		if minPrice != "" {
+			value, err := strconv.ParseFloat(minPrice, 64)
+			if err != nil || value < 0 {
+				return nil, "Invalid min_price"
+			}
+			condition += " AND tc.price >= ?"
+			args = append(args, value)
+		}
+		if maxPrice != "" {
+			value, err := strconv.ParseFloat(maxPrice, 64)
+			if err != nil || value < 0 {
+				return nil, "Invalid max_price"
+			}
+			condition += " AND tc.price <= ?"
+			args = append(args, value)
+		}
+		query = query.Where(condition+")", args...)
+	}
+
+	return query, ""
+}
+
+// pricedCategorySQL - kategori (alias tc) yang dihitung untuk harga event:
+// milik event dan belum diarsipkan. Dipakai filter harga dan kolom min_price.
+const pricedCategorySQL = "tc.event_id = events.event_id AND tc.archived = false"
+
+// eventMinPriceSQL - harga termurah event, 0 jika tidak ada kategori
+const eventMinPriceSQL = "COALESCE((SELECT MIN(tc.price) FROM ticket_categories tc WHERE " + pricedCategorySQL + "), 0)"
+
+// refreshEventMinPrice menghitung ulang harga termurah event dari kategori tiketnya
+func refreshEventMinPrice(tx *gorm.DB, eventID string) error {
+	return tx.Model(&models.Event{}).
+		Where("event_id = ?", eventID).
+		UpdateColumn("min_price", gorm.Expr(eventMinPriceSQL)).Error
+}
+
+// BackfillEventMinPrice mengisi min_price semua event; dipanggil sekali saat
+// kolom baru ditambahkan
+func BackfillEventMinPrice(db *gorm.DB) error {
+	return db.Exec("UPDATE events SET min_price = " + eventMinPriceSQL).Error
+}
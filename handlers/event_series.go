@@ -0,0 +1,698 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Scope edit occurrence
+const (
+	occurrenceScopeThis   = "this"
+	occurrenceScopeFuture = "future"
+)
+
+type OccurrenceUpdateRequest struct {
+	Scope           string `json:"scope"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Rules           string `json:"rules"`
+	Location        string `json:"location"`
+	Venue           string `json:"venue"`
+	District        string `json:"district"`
+	Category        string `json:"category"`
+	ChildCategory   string `json:"child_category"`
+	StartTime       string `json:"start_time"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+// eventSaleProblem - tiket hanya dijual untuk event yang sudah disetujui dan
+// tayang (publish_at kosong atau sudah lewat); string kosong berarti boleh dibeli
+func eventSaleProblem(eventID string) string {
+	var event models.Event
+	if err := config.DB.Select("event_id", "status", "publish_at").First(&event, "event_id = ?", eventID).Error; err != nil {
+		return "Event not found"
+	}
+	if event.Status == "cancelled" {
+		return "Event has been cancelled"
+	}
+	if !containsString(liveEventStatuses, event.Status) || (event.PublishAt != nil && event.PublishAt.After(time.Now())) {
+		return "Event is not on sale"
+	}
+	return ""
+}
+
+// uploadEventAssets mengunggah image dan flyer dari form jika ada
+func uploadEventAssets(c *fiber.Ctx, user models.User) (string, string, error) {
+	var imageURL, flyerURL string
+
+	if imageFile, err := c.FormFile("image"); err == nil {
+		file, err := imageFile.Open()
+		if err == nil {
+			defer file.Close()
+			folder := fmt.Sprintf("ticketing-app/events/%s/images", user.UserID)
+			if imageURL, err = config.UploadImage(context.Background(), file, folder); err != nil {
+				return "", "", fmt.Errorf("failed to upload event image")
+			}
+		}
+	}
+
+	if flyerFile, err := c.FormFile("flyer"); err == nil {
+		file, err := flyerFile.Open()
+		if err == nil {
+			defer file.Close()
+			folder := fmt.Sprintf("ticketing-app/events/%s/flyers", user.UserID)
+			if flyerURL, err = config.UploadImage(context.Background(), file, folder); err != nil {
+				return "", "", fmt.Errorf("failed to upload event flyer")
+			}
+		}
+	}
+
+	return imageURL, flyerURL, nil
+}
+
+// parseTicketCategoryTemplates memvalidasi kategori tiket dari request.
+// Hasilnya template tanpa ID/EventID yang disalin ke tiap occurrence.
+func parseTicketCategoryTemplates(requests []TicketCategoryRequest) ([]models.TicketCategory, error) {
+	seen := make(map[string]bool)
+	templates := make([]models.TicketCategory, 0, len(requests))
+	for _, tcReq := range requests {
+		if seen[tcReq.Name] {
+			return nil, fmt.Errorf("duplicate ticket category name : %s", tcReq.Name)
+		}
+		seen[tcReq.Name] = true
+
+		dateTimeStart, err := time.Parse(time.RFC3339, tcReq.DateTimeStart)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date_time_start format in ticket category: %s", tcReq.Name)
+		}
+		dateTimeEnd, err := time.Parse(time.RFC3339, tcReq.DateTimeEnd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date_time_end format in ticket category: %s", tcReq.Name)
+		}
+		if !validEntryMode(tcReq.EntryMode) {
+			return nil, fmt.Errorf("invalid entry_mode in ticket category: %s", tcReq.Name)
+		}
+
+		templates = append(templates, models.TicketCategory{
+			Name:          tcReq.Name,
+			Price:         tcReq.Price,
+			Quota:         tcReq.Quota,
+			Description:   tcReq.Description,
+			DateTimeStart: dateTimeStart,
+			DateTimeEnd:   dateTimeEnd,
+			EntryMode:     entryModeOf(tcReq.EntryMode),
+			MaxEntries:    tcReq.MaxEntries,
+		})
+	}
+	return templates, nil
+}
+
+// resubmitSeries - series yang ditolak kembali pending setelah salah satu
+// occurrence diperbaiki; occurrence lain yang ditolak ikut pending.
+func resubmitSeries(tx *gorm.DB, seriesID, actorID string) error {
+	var series models.EventSeries
+	if err := tx.First(&series, "series_id = ?", seriesID).Error; err != nil {
+		return err
+	}
+	if series.Status != "rejected" {
+		return nil
+	}
+
+	if err := seriesLifecycle.transition(tx, series.SeriesID, series.Status, "pending", actorID, "series resubmitted", nil); err != nil {
+		return err
+	}
+	_, err := eventLifecycle.transitionWhere(tx, "rejected", "pending", actorID, "series resubmitted", "series_id = ?", seriesID)
+	return err
+}
+
+// CreateEventSeries - Membuat event berulang. Form sama dengan CreateEvent
+// ditambah `rrule` (mis. FREQ=WEEKLY;BYDAY=SA;COUNT=8). date_start/date_end
+// dan jadwal kategori tiket menjadi template yang digeser ke tiap occurrence.
+func CreateEventSeries(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+	name := c.FormValue("name")
+	dateStartStr := c.FormValue("date_start")
+	dateEndStr := c.FormValue("date_end")
+	location := c.FormValue("location")
+	venue := c.FormValue("venue")
+	district := c.FormValue("district")
+	rruleStr := c.FormValue("rrule")
+
+	if name == "" || dateStartStr == "" || dateEndStr == "" || location == "" || venue == "" || district == "" || rruleStr == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Missing required fields: name, date_start, date_end, location, venue, district, rrule",
+		})
+	}
+
+	dateStart, err := time.Parse(time.RFC3339, dateStartStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid date_start format. Use RFC3339 format (e.g., 2024-07-01T18:00:00Z)",
+		})
+	}
+	dateEnd, err := time.Parse(time.RFC3339, dateEndStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid date_end format. Use RFC3339 format (e.g., 2024-07-01T23:00:00Z)",
+		})
+	}
+	if !dateEnd.After(dateStart) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "date_end must be after date_start",
+		})
+	}
+
+	rule, err := utils.ParseRRule(rruleStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid rrule: " + err.Error(),
+		})
+	}
+	if rule.Count > utils.MaxRRuleOccurrences {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("A series can have at most %d occurrences", utils.MaxRRuleOccurrences),
+		})
+	}
+	occurrences := rule.Occurrences(dateStart)
+	if len(occurrences) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "rrule does not produce any occurrence",
+		})
+	}
+
+	var ticketCategories []TicketCategoryRequest
+	if raw := c.FormValue("ticket_categories"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &ticketCategories); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid ticket categories JSON format: " + err.Error(),
+			})
+		}
+	}
+	templates, err := parseTicketCategoryTemplates(ticketCategories)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	imageURL, flyerURL, err := uploadEventAssets(c, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	series := models.EventSeries{
+		SeriesID:   utils.GenerateSeriesID(),
+		OwnerID:    user.UserID,
+		Name:       name,
+		RRule:      rruleStr,
+		FirstStart: occurrences[0],
+		Status:     "pending",
+	}
+	if err := tx.Create(&series).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create event series: " + err.Error(),
+		})
+	}
+
+	duration := dateEnd.Sub(dateStart)
+	for _, start := range occurrences {
+		start := start
+		offset := start.Sub(dateStart)
+
+		event := models.Event{
+			EventID:         utils.GenerateEventID(),
+			Name:            name,
+			OwnerID:         user.UserID,
+			Status:          "pending",
+			DateStart:       start,
+			DateEnd:         start.Add(duration),
+			Location:        location,
+			Venue:           venue,
+			District:        district,
+			Description:     c.FormValue("description"),
+			Rules:           c.FormValue("rules"),
+			Image:           imageURL,
+			Flyer:           flyerURL,
+			Category:        c.FormValue("category"),
+			ChildCategory:   c.FormValue("child_category"),
+			SeriesID:        series.SeriesID,
+			OccurrenceStart: &start,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create occurrence: " + err.Error(),
+			})
+		}
+
+		for _, template := range templates {
+			ticketCategory := template
+			ticketCategory.TicketCategoryID = utils.GenerateTicketCategoryID()
+			ticketCategory.EventID = event.EventID
+			ticketCategory.DateTimeStart = template.DateTimeStart.Add(offset)
+			ticketCategory.DateTimeEnd = template.DateTimeEnd.Add(offset)
+			ticketCategory.CreatedAt = time.Now()
+			ticketCategory.UpdatedAt = time.Now()
+
+			if err := tx.Create(&ticketCategory).Error; err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to create ticket category: " + err.Error(),
+				})
+			}
+		}
+
+		if err := refreshEventMinPrice(tx, event.EventID); err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update event price: " + err.Error(),
+			})
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction: " + err.Error(),
+		})
+	}
+
+	var events []models.Event
+	if err := config.DB.Preload("TicketCategories").
+		Where("series_id = ?", series.SeriesID).
+		Order("date_start ASC").
+		Find(&events).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load occurrences",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":     "Event series created successfully",
+		"series":      series,
+		"occurrences": events,
+	})
+}
+
+// GetEventSeries - Detail series beserta occurrence. Selain owner/admin
+// hanya melihat series yang sudah disetujui dan occurrence publiknya.
+func GetEventSeries(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	var series models.EventSeries
+	if err := config.DB.First(&series, "series_id = ?", c.Params("series_id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event series not found",
+		})
+	}
+
+	manager := series.OwnerID == user.UserID || user.Role == "admin"
+	if !manager && series.Status != "approved" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event series not found",
+		})
+	}
+
+	query := config.DB.Preload("TicketCategories").Where("series_id = ?", series.SeriesID)
+	if !manager {
+		query = publicEvents(query)
+	}
+
+	var events []models.Event
+	if err := query.Order("date_start ASC").Find(&events).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load occurrences",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":     "Event series retrieved successfully",
+		"series":      series,
+		"occurrences": events,
+	})
+}
+
+// UpdateOccurrence - Edit satu occurrence (scope=this) atau occurrence ini dan
+// semua sesudahnya (scope=future). start_time "HH:MM" memindahkan jam mulai di
+// tanggal masing-masing occurrence; jadwal kategori tiket ikut digeser.
+// Occurrence yang sudah tayang tidak langsung diubah: perubahannya disimpan
+// sebagai change-set dan dikembalikan di `revisions`.
+func UpdateOccurrence(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if event.SeriesID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Event is not part of a series",
+		})
+	}
+
+	var req OccurrenceUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+	if req.Scope == "" {
+		req.Scope = occurrenceScopeThis
+	}
+	if req.Scope != occurrenceScopeThis && req.Scope != occurrenceScopeFuture {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Scope must be this or future",
+		})
+	}
+	if req.DurationMinutes < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "duration_minutes must be positive",
+		})
+	}
+
+	var startClock time.Time
+	if req.StartTime != "" {
+		startClock, err = time.Parse("15:04", req.StartTime)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid start_time format. Use HH:MM",
+			})
+		}
+	}
+
+	// occurrence yang sudah tayang diedit lewat change-set seperti UpdateEvent
+	editable := append([]string{"pending", "rejected"}, liveEventStatuses...)
+	if !containsString(editable, event.Status) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Occurrence can only be edited when status is pending, rejected, approved or active",
+		})
+	}
+
+	var targets []models.Event
+	if req.Scope == occurrenceScopeThis {
+		targets = []models.Event{event}
+	} else if err := config.DB.Preload("TicketCategories").
+		Where("series_id = ? AND date_start >= ? AND status IN ?", event.SeriesID, event.DateStart, editable).
+		Order("date_start ASC").
+		Find(&targets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load occurrences",
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	fields := map[string]string{
+		"name":           req.Name,
+		"description":    req.Description,
+		"rules":          req.Rules,
+		"location":       req.Location,
+		"venue":          req.Venue,
+		"district":       req.District,
+		"category":       req.Category,
+		"child_category": req.ChildCategory,
+	}
+
+	updatedIDs := make([]string, 0, len(targets))
+	revisions := []models.EventRevision{}
+	autoApplied := []string{}
+	resubmitted := false
+	for _, target := range targets {
+		updateData := map[string]interface{}{
+			"updated_at": time.Now(),
+		}
+		for column, value := range fields {
+			if value != "" {
+				updateData[column] = value
+			}
+		}
+
+		start := target.DateStart
+		if req.StartTime != "" {
+			start = time.Date(start.Year(), start.Month(), start.Day(), startClock.Hour(), startClock.Minute(), 0, 0, start.Location())
+		}
+		end := start.Add(target.DateEnd.Sub(target.DateStart))
+		if req.DurationMinutes > 0 {
+			end = start.Add(time.Duration(req.DurationMinutes) * time.Minute)
+		}
+		updateData["date_start"] = start
+		updateData["date_end"] = end
+		shift := start.Sub(target.DateStart)
+
+		// occurrence live: perubahan (termasuk pergeseran jadwal penjualan)
+		// menjadi change-set, occurrence tetap tayang selama direview
+		if containsString(liveEventStatuses, target.Status) {
+			changes := eventFieldChanges(target, updateData)
+			if shift != 0 {
+				for _, tc := range target.TicketCategories {
+					changes = append(changes,
+						models.FieldChange{
+							Field: categoryFieldChange(tc.TicketCategoryID, "date_time_start"),
+							Old:   revisionValue(tc.DateTimeStart),
+							New:   revisionValue(tc.DateTimeStart.Add(shift)),
+						},
+						models.FieldChange{
+							Field: categoryFieldChange(tc.TicketCategoryID, "date_time_end"),
+							Old:   revisionValue(tc.DateTimeEnd),
+							New:   revisionValue(tc.DateTimeEnd.Add(shift)),
+						})
+				}
+			}
+			if len(changes) == 0 {
+				continue
+			}
+
+			revision, err := recordLiveEventChanges(tx, target.EventID, user.UserID, updateData, changes)
+			if errors.Is(err, errRevisionPending) {
+				tx.Rollback()
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": "Another change to occurrence " + target.EventID + " is still under review",
+				})
+			}
+			if err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to record occurrence changes: " + err.Error(),
+				})
+			}
+			if revision.Status == models.RevisionAutoApproved {
+				autoApplied = append(autoApplied, target.EventID)
+			}
+			revisions = append(revisions, revision)
+			updatedIDs = append(updatedIDs, target.EventID)
+			continue
+		}
+
+		if err := tx.Model(&models.Event{}).Where("event_id = ?", target.EventID).Updates(updateData).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update occurrence: " + err.Error(),
+			})
+		}
+
+		// penjualan tiket ikut bergeser bersama jam mulai occurrence
+		if shift != 0 {
+			for _, tc := range target.TicketCategories {
+				if err := tx.Model(&models.TicketCategory{}).
+					Where("ticket_category_id = ?", tc.TicketCategoryID).
+					Updates(map[string]interface{}{
+						"date_time_start": tc.DateTimeStart.Add(shift),
+						"date_time_end":   tc.DateTimeEnd.Add(shift),
+						"updated_at":      time.Now(),
+					}).Error; err != nil {
+					tx.Rollback()
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+						"error": "Failed to update ticket category schedule",
+					})
+				}
+			}
+		}
+
+		if err := eventLifecycle.transition(tx, target.EventID, target.Status, "pending", user.UserID, "occurrence resubmitted", nil); err != nil {
+			tx.Rollback()
+			return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+				"error": "Failed to update occurrence status: " + err.Error(),
+			})
+		}
+
+		resubmitted = true
+		updatedIDs = append(updatedIDs, target.EventID)
+	}
+
+	if len(updatedIDs) == 0 {
+		tx.Rollback()
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No changes to submit",
+		})
+	}
+
+	if resubmitted {
+		if err := resubmitSeries(tx, event.SeriesID, user.UserID); err != nil {
+			tx.Rollback()
+			return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+				"error": "Failed to update series status: " + err.Error(),
+			})
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	for _, eventID := range autoApplied {
+		go notifyEventWallets(eventID)
+	}
+
+	var events []models.Event
+	if err := config.DB.Preload("TicketCategories").
+		Where("event_id IN ?", updatedIDs).
+		Order("date_start ASC").
+		Find(&events).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load updated occurrences",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":     "Occurrence updated successfully",
+		"scope":       req.Scope,
+		"occurrences": events,
+		"revisions":   revisions,
+	})
+}
+
+// CancelOccurrence - Membatalkan satu occurrence; tiket terjual direfund seperti CancelEvent
+func CancelOccurrence(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if event.SeriesID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Event is not part of a series",
+		})
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	return cancelEvent(c, user, event, req.Reason, "Occurrence cancelled successfully")
+}
+
+// verifySeries - approval dipakai bersama: series dan semua occurrence yang
+// belum dibatalkan/berjalan ikut disetujui atau ditolak.
+func verifySeries(c *fiber.Ctx, user models.User, seriesID, status, comment string) error {
+	var series models.EventSeries
+	if err := config.DB.First(&series, "series_id = ?", seriesID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event series not found",
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	if err := seriesLifecycle.transition(tx, series.SeriesID, series.Status, status, user.UserID, comment, map[string]interface{}{
+		"approval_comment": comment,
+	}); err != nil {
+		tx.Rollback()
+		return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+			"error": "Failed to verify event series: " + err.Error(),
+		})
+	}
+
+	for _, from := range []string{"pending", "approved"} {
+		if !eventLifecycle.allows(from, status) {
+			continue
+		}
+		if _, err := eventLifecycle.transitionWhere(tx, from, status, user.UserID, comment, "series_id = ?", series.SeriesID); err != nil {
+			tx.Rollback()
+			return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+				"error": "Failed to verify occurrences: " + err.Error(),
+			})
+		}
+	}
+
+	if err := tx.Model(&models.Event{}).
+		Where("series_id = ? AND status = ?", series.SeriesID, status).
+		Update("approval_comment", comment).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update approval comment",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	series.Status = status
+	series.ApprovalComment = comment
+
+	var events []models.Event
+	if err := config.DB.Where("series_id = ?", series.SeriesID).
+		Order("date_start ASC").
+		Find(&events).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load occurrences",
+		})
+	}
+
+	if status == "approved" {
+		for _, event := range events {
+			if event.Status == "approved" {
+				ScheduleEventEnd(config.DB, event)
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":     "Event series verification updated",
+		"series":      series,
+		"occurrences": events,
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 
+	"gorm.io/gorm"
+
 	"github.com/Tsaniii18/Ticketing-Backend/config"
 	"github.com/Tsaniii18/Ticketing-Backend/models"
 	"github.com/Tsaniii18/Ticketing-Backend/utils"
@@ -68,16 +70,25 @@ func GetAllFeedbacks(c *fiber.Ctx) error {
 		})
 	}
 
-	var feedbacks []models.Feedback
-	if err := config.DB.Model(&feedbacks).Preload("User").Find(&feedbacks).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Feedback Not Fount",
-		})
+	query := config.DB.Model(&models.Feedback{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if category := c.Query("feedback_category"); category != "" {
+		query = query.Where("feedback_category = ?", category)
+	}
+
+	feedbacks, pagination, err := paginate(c, query, feedbackListSpec, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("User")
+	})
+	if err != nil {
+		return listError(c, err, "Failed to fetch feedback")
 	}
 
 	return c.JSON(fiber.Map{
-		"message":  "Success fetch all feedback",
-		"feedback": feedbacks,
+		"message":    "Success fetch all feedback",
+		"feedback":   feedbacks,
+		"pagination": pagination,
 	})
 
 }
@@ -162,3 +173,31 @@ func UpdateStatusFeedback(c *fiber.Ctx) error {
 	})
 
 }
+
+// DeleteFeedback - Hapus feedback milik sendiri, atau feedback siapa saja oleh admin
+func DeleteFeedback(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	var feedback models.Feedback
+	if err := config.DB.First(&feedback, "feedback_id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Feedback Not Fount",
+		})
+	}
+
+	if feedback.OwnerID != user.UserID && user.Role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Not authorized to delete this feedback",
+		})
+	}
+
+	if _, err := softDelete(&models.Feedback{}, user.UserID, "feedback_id = ?", feedback.FeedbackID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete feedback",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Feedback deleted successfully",
+	})
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Actor untuk perubahan status yang tidak dilakukan user
+const (
+	systemActor   = "system"
+	midtransActor = "midtrans"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrStatusChanged     = errors.New("status was changed concurrently")
+)
+
+// lifecycle - state machine status satu entitas. Semua perubahan status
+// tiket, event dan transaksi harus lewat transition agar tercatat di history.
+type lifecycle struct {
+	entity      string
+	model       interface{}
+	key         string
+	column      string
+	transitions map[string][]string
+}
+
+var ticketLifecycle = lifecycle{
+	entity: models.EntityTicket,
+	model:  &models.Ticket{},
+	key:    "ticket_id",
+	column: "status",
+	transitions: map[string][]string{
+		"pending": {"active", "payment_failed", "cancelled"},
+		"active":  {"used", "cancelled"},
+	},
+}
+
+var eventLifecycle = lifecycle{
+	entity: models.EntityEvent,
+	model:  &models.Event{},
+	key:    "event_id",
+	column: "status",
+	transitions: map[string][]string{
+		"draft":     {"pending", "cancelled"},
+		"pending":   {"approved", "rejected", "cancelled"},
+		"rejected":  {"pending", "cancelled"},
+		"approved":  {"rejected", "active", "ended", "postponed", "cancelled"},
+		"active":    {"ended", "postponed", "cancelled"},
+		"postponed": {"approved", "cancelled"},
+	},
+}
+
+var seriesLifecycle = lifecycle{
+	entity: models.EntitySeries,
+	model:  &models.EventSeries{},
+	key:    "series_id",
+	column: "status",
+	transitions: map[string][]string{
+		"pending":  {"approved", "rejected"},
+		"rejected": {"pending"},
+		"approved": {"rejected"},
+	},
+}
+
+var transactionLifecycle = lifecycle{
+	entity: models.EntityTransaction,
+	model:  &models.TransactionHistory{},
+	key:    "transaction_id",
+	column: "transaction_status",
+	transitions: map[string][]string{
+		"pending": {"paid", "failed", "expired"},
+		"paid":    {"refunded"},
+	},
+}
+
+func (l lifecycle) allows(from, to string) bool {
+	for _, next := range l.transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transition memindahkan status satu entitas dari `from` ke `to` dan mencatat
+// history. Update memakai kondisi status lama sehingga perubahan yang balapan
+// dengan request lain gagal dengan ErrStatusChanged. from == to tidak dicatat.
+func (l lifecycle) transition(tx *gorm.DB, id, from, to, actorID, reason string, extra map[string]interface{}) error {
+	if from == to {
+		return nil
+	}
+	if !l.allows(from, to) {
+		return fmt.Errorf("%w: %s %s -> %s", ErrInvalidTransition, l.entity, from, to)
+	}
+
+	updates := map[string]interface{}{l.column: to}
+	for column, value := range extra {
+		updates[column] = value
+	}
+
+	result := tx.Model(l.model).
+		Where(l.key+" = ? AND "+l.column+" = ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s %s", ErrStatusChanged, l.entity, id)
+	}
+
+	return tx.Create(&models.StatusHistory{
+		StatusHistoryID: utils.GenerateStatusHistoryID(),
+		EntityType:      l.entity,
+		EntityID:        id,
+		FromStatus:      from,
+		ToStatus:        to,
+		ActorID:         actorID,
+		Reason:          reason,
+	}).Error
+}
+
+// transitionWhere - transition massal untuk semua entitas berstatus `from`
+// yang cocok dengan kondisi query. Mengembalikan ID yang dipindahkan.
+func (l lifecycle) transitionWhere(tx *gorm.DB, from, to, actorID, reason string, query string, args ...interface{}) ([]string, error) {
+	if !l.allows(from, to) {
+		return nil, fmt.Errorf("%w: %s %s -> %s", ErrInvalidTransition, l.entity, from, to)
+	}
+
+	var ids []string
+	if err := tx.Model(l.model).
+		Where(query, args...).
+		Where(l.column+" = ?", from).
+		Pluck(l.key, &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	if err := tx.Model(l.model).
+		Where(l.key+" IN ? AND "+l.column+" = ?", ids, from).
+		Update(l.column, to).Error; err != nil {
+		return nil, err
+	}
+
+	history := make([]models.StatusHistory, 0, len(ids))
+	for _, id := range ids {
+		history = append(history, models.StatusHistory{
+			StatusHistoryID: utils.GenerateStatusHistoryID(),
+			EntityType:      l.entity,
+			EntityID:        id,
+			FromStatus:      from,
+			ToStatus:        to,
+			ActorID:         actorID,
+			Reason:          reason,
+		})
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// transitionStatusCode - status HTTP untuk error dari transition
+func transitionStatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrStatusChanged):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func statusHistory(entity, id string) ([]models.StatusHistory, error) {
+	var history []models.StatusHistory
+	err := config.DB.Where("entity_type = ? AND entity_id = ?", entity, id).
+		Order("created_at ASC").
+		Find(&history).Error
+	return history, err
+}
+
+func historyResponse(c *fiber.Ctx, entity, id, status string) error {
+	history, err := statusHistory(entity, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch status history",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":        "Status history retrieved successfully",
+		"entity_type":    entity,
+		"entity_id":      id,
+		"current_status": status,
+		"history":        history,
+	})
+}
+
+// GetStatusTransitions - Daftar transisi status yang diizinkan per entitas
+func GetStatusTransitions(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"message": "Status transitions retrieved successfully",
+		"transitions": fiber.Map{
+			models.EntityTicket:      ticketLifecycle.transitions,
+			models.EntityEvent:       eventLifecycle.transitions,
+			models.EntityTransaction: transactionLifecycle.transitions,
+			models.EntitySeries:      seriesLifecycle.transitions,
+		},
+	})
+}
+
+// GetTicketHistory - Riwayat status tiket
+func GetTicketHistory(c *fiber.Ctx) error {
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return historyResponse(c, models.EntityTicket, ticket.TicketID, ticket.Status)
+}
+
+// GetEventHistory - Riwayat status event (owner/admin)
+func GetEventHistory(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return historyResponse(c, models.EntityEvent, event.EventID, event.Status)
+}
+
+// GetTransactionStatusHistory - Riwayat status transaksi milik user (admin boleh semua)
+func GetTransactionStatusHistory(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	var transaction models.TransactionHistory
+	if err := config.DB.First(&transaction, "transaction_id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Transaction not found",
+		})
+	}
+
+	if transaction.OwnerID != user.UserID && user.Role != "admin" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Transaction not found",
+		})
+	}
+
+	return historyResponse(c, models.EntityTransaction, transaction.TransactionID, transaction.TransactionStatus)
+}
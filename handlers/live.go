@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	liveChannelPrefix    = "checkin:"
+	liveRecentRejections = 20
+	liveRateWindow       = time.Minute
+	liveStatsInterval    = 2 * time.Second
+	liveHeartbeat        = 15 * time.Second
+	liveBuffer           = 64
+)
+
+// liveScan - satu scan yang disiarkan ke dashboard
+type liveScan struct {
+	EventID          string    `json:"event_id"`
+	TicketID         string    `json:"ticket_id"`
+	TicketCategoryID string    `json:"ticket_category_id"`
+	Gate             string    `json:"gate"`
+	DeviceID         string    `json:"device_id"`
+	Outcome          string    `json:"outcome"`
+	Message          string    `json:"message"`
+	Source           string    `json:"source"`
+	ScannedAt        time.Time `json:"scanned_at"`
+}
+
+// liveStats - agregat check-in satu event yang dikirim ke dashboard
+type liveStats struct {
+	EventID          string                      `json:"event_id"`
+	Categories       map[string]int64            `json:"categories"`
+	Gates            map[string]map[string]int64 `json:"gates"`
+	ScansPerMinute   int                         `json:"scans_per_minute"`
+	RecentRejections []liveScan                  `json:"recent_rejections"`
+	UpdatedAt        time.Time                   `json:"updated_at"`
+}
+
+type liveEvent struct {
+	stats       liveStats
+	recentScans []time.Time
+	version     int64
+	subscribers map[chan []byte]struct{}
+}
+
+// liveHub - agregator per event di instance ini. Hanya event yang sedang
+// ditonton dashboard yang disimpan di memori.
+type liveHub struct {
+	mu     sync.Mutex
+	events map[string]*liveEvent
+}
+
+var hub = &liveHub{events: make(map[string]*liveEvent)}
+
+func isRejection(outcome string) bool {
+	return outcome != models.CheckInSuccess && outcome != models.CheckOutSuccess
+}
+
+// publishLiveScan dipanggil dari jalur check-in. Dengan Redis, scan disiarkan ke
+// semua instance (termasuk instance ini lewat relay); tanpa Redis langsung lokal.
+func publishLiveScan(entry models.CheckInLog) {
+	scan := liveScan{
+		EventID:          entry.EventID,
+		TicketID:         entry.TicketID,
+		TicketCategoryID: entry.TicketCategoryID,
+		Gate:             entry.Gate,
+		DeviceID:         entry.DeviceID,
+		Outcome:          entry.Outcome,
+		Message:          entry.Message,
+		Source:           entry.Source,
+		ScannedAt:        entry.ScannedAt,
+	}
+
+	if config.Redis != nil {
+		payload, err := json.Marshal(scan)
+		if err == nil {
+			err = config.Redis.Publish(context.Background(), liveChannelPrefix+scan.EventID, payload).Err()
+		}
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to publish live scan for event %s: %v", scan.EventID, err)
+	}
+
+	hub.dispatch(scan)
+}
+
+// StartLiveRelay meneruskan scan dari Redis ke dashboard yang terhubung di instance ini
+func StartLiveRelay() {
+	if config.Redis == nil {
+		return
+	}
+
+	go func() {
+		pubsub := config.Redis.PSubscribe(context.Background(), liveChannelPrefix+"*")
+		defer pubsub.Close()
+
+		for msg := range pubsub.Channel() {
+			var scan liveScan
+			if err := json.Unmarshal([]byte(msg.Payload), &scan); err != nil {
+				log.Println("Invalid live scan payload:", err)
+				continue
+			}
+			hub.dispatch(scan)
+		}
+	}()
+}
+
+func (h *liveHub) dispatch(scan liveScan) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ev, exists := h.events[scan.EventID]
+	if !exists {
+		return
+	}
+	ev.apply(scan)
+
+	message := sseMessage("scan", scan)
+	for subscriber := range ev.subscribers {
+		// dashboard yang lambat melewatkan scan, stats tetap menyusul
+		select {
+		case subscriber <- message:
+		default:
+		}
+	}
+}
+
+func (ev *liveEvent) apply(scan liveScan) {
+	if ev.stats.Gates[scan.Gate] == nil {
+		ev.stats.Gates[scan.Gate] = make(map[string]int64)
+	}
+	ev.stats.Gates[scan.Gate][scan.Outcome]++
+
+	if scan.Outcome == models.CheckInSuccess {
+		ev.stats.Categories[scan.TicketCategoryID]++
+	}
+
+	if isRejection(scan.Outcome) {
+		ev.stats.RecentRejections = append([]liveScan{scan}, ev.stats.RecentRejections...)
+		if len(ev.stats.RecentRejections) > liveRecentRejections {
+			ev.stats.RecentRejections = ev.stats.RecentRejections[:liveRecentRejections]
+		}
+	}
+
+	ev.recentScans = append(ev.recentScans, scan.ScannedAt)
+	ev.stats.UpdatedAt = time.Now()
+	ev.version++
+}
+
+// prune membuang scan di luar jendela scan rate
+func (ev *liveEvent) prune() {
+	cutoff := time.Now().Add(-liveRateWindow)
+	recent := ev.recentScans[:0]
+	for _, at := range ev.recentScans {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	ev.recentScans = recent
+	ev.stats.ScansPerMinute = len(recent)
+}
+
+func (h *liveHub) subscribe(eventID string) (chan []byte, []byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ev, exists := h.events[eventID]
+	if !exists {
+		stats, recentScans, err := loadLiveStats(eventID)
+		if err != nil {
+			return nil, nil, err
+		}
+		ev = &liveEvent{
+			stats:       stats,
+			recentScans: recentScans,
+			subscribers: make(map[chan []byte]struct{}),
+		}
+		h.events[eventID] = ev
+	}
+
+	subscriber := make(chan []byte, liveBuffer)
+	ev.subscribers[subscriber] = struct{}{}
+
+	ev.prune()
+	return subscriber, sseMessage("snapshot", ev.stats), nil
+}
+
+func (h *liveHub) unsubscribe(eventID string, subscriber chan []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ev, exists := h.events[eventID]
+	if !exists {
+		return
+	}
+	delete(ev.subscribers, subscriber)
+	if len(ev.subscribers) == 0 {
+		delete(h.events, eventID)
+	}
+}
+
+// stats mengembalikan pesan stats jika ada scan baru atau scan rate berubah
+// sejak pesan terakhir yang dikirim ke koneksi (version, rate)
+func (h *liveHub) stats(eventID string, version int64, rate int) ([]byte, int64, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ev, exists := h.events[eventID]
+	if !exists {
+		return nil, version, rate
+	}
+
+	ev.prune()
+	if ev.version == version && ev.stats.ScansPerMinute == rate {
+		return nil, version, rate
+	}
+	return sseMessage("stats", ev.stats), ev.version, ev.stats.ScansPerMinute
+}
+
+// loadLiveStats - kondisi awal dari check-in log, hanya saat dashboard pertama terhubung
+func loadLiveStats(eventID string) (liveStats, []time.Time, error) {
+	stats := liveStats{
+		EventID:          eventID,
+		Categories:       make(map[string]int64),
+		Gates:            make(map[string]map[string]int64),
+		RecentRejections: []liveScan{},
+		UpdatedAt:        time.Now(),
+	}
+
+	type gateRow struct {
+		Gate    string
+		Outcome string
+		Total   int64
+	}
+	var gates []gateRow
+	if err := config.DB.Model(&models.CheckInLog{}).
+		Select("gate, outcome, COUNT(*) AS total").
+		Where("event_id = ?", eventID).
+		Group("gate, outcome").
+		Scan(&gates).Error; err != nil {
+		return stats, nil, err
+	}
+	for _, row := range gates {
+		if stats.Gates[row.Gate] == nil {
+			stats.Gates[row.Gate] = make(map[string]int64)
+		}
+		stats.Gates[row.Gate][row.Outcome] = row.Total
+	}
+
+	type categoryRow struct {
+		TicketCategoryID string
+		Total            int64
+	}
+	var categories []categoryRow
+	if err := config.DB.Model(&models.CheckInLog{}).
+		Select("ticket_category_id, COUNT(*) AS total").
+		Where("event_id = ? AND outcome = ?", eventID, models.CheckInSuccess).
+		Group("ticket_category_id").
+		Scan(&categories).Error; err != nil {
+		return stats, nil, err
+	}
+	for _, row := range categories {
+		stats.Categories[row.TicketCategoryID] = row.Total
+	}
+
+	var rejections []models.CheckInLog
+	if err := config.DB.
+		Where("event_id = ? AND outcome NOT IN ?", eventID, []string{models.CheckInSuccess, models.CheckOutSuccess}).
+		Order("scanned_at DESC").
+		Limit(liveRecentRejections).
+		Find(&rejections).Error; err != nil {
+		return stats, nil, err
+	}
+	for _, entry := range rejections {
+		stats.RecentRejections = append(stats.RecentRejections, liveScan{
+			EventID:          entry.EventID,
+			TicketID:         entry.TicketID,
+			TicketCategoryID: entry.TicketCategoryID,
+			Gate:             entry.Gate,
+			DeviceID:         entry.DeviceID,
+			Outcome:          entry.Outcome,
+			Message:          entry.Message,
+			Source:           entry.Source,
+			ScannedAt:        entry.ScannedAt,
+		})
+	}
+
+	var recentScans []time.Time
+	if err := config.DB.Model(&models.CheckInLog{}).
+		Where("event_id = ? AND scanned_at >= ?", eventID, time.Now().Add(-liveRateWindow)).
+		Pluck("scanned_at", &recentScans).Error; err != nil {
+		return stats, nil, err
+	}
+
+	return stats, recentScans, nil
+}
+
+func sseMessage(name string, data interface{}) []byte {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload = []byte("{}")
+	}
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", name, payload))
+}
+
+// StreamCheckInLive - Dashboard kehadiran live (Server-Sent Events).
+// Mengirim "snapshot" saat terhubung, "scan" untuk setiap scan, dan "stats"
+// secara berkala selama ada perubahan.
+func StreamCheckInLive(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("event_id"))
+	if err != nil {
+		return err
+	}
+
+	subscriber, snapshot, err := hub.subscribe(event.EventID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load live stats",
+		})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	eventID := event.EventID
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer hub.unsubscribe(eventID, subscriber)
+
+		if _, err := w.Write(snapshot); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		statsTicker := time.NewTicker(liveStatsInterval)
+		defer statsTicker.Stop()
+		heartbeat := time.NewTicker(liveHeartbeat)
+		defer heartbeat.Stop()
+
+		var version int64
+		rate := -1
+		for {
+			var message []byte
+			select {
+			case message = <-subscriber:
+			case <-statsTicker.C:
+				message, version, rate = hub.stats(eventID, version, rate)
+				if message == nil {
+					continue
+				}
+			case <-heartbeat.C:
+				message = []byte(": ping\n\n")
+			}
+
+			if _, err := w.Write(message); err != nil {
+				return
+			}
+			// flush gagal berarti dashboard sudah menutup koneksi
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
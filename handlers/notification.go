@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// notice - isi satu pemberitahuan yang dikirim ke banyak penerima
+type notice struct {
+	EventID string
+	Type    string
+	Title   string
+	Message string
+}
+
+// notifyUsers menyimpan notifikasi in-app dan mengirim email jika SMTP aktif
+func notifyUsers(userIDs []string, n notice) {
+	userIDs = uniqueStrings(userIDs)
+	if len(userIDs) == 0 {
+		return
+	}
+
+	notifications := make([]models.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notifications = append(notifications, models.Notification{
+			NotificationID: utils.GenerateNotificationID(),
+			UserID:         userID,
+			EventID:        n.EventID,
+			Type:           n.Type,
+			Title:          n.Title,
+			Message:        n.Message,
+		})
+	}
+	if err := config.DB.Create(&notifications).Error; err != nil {
+		log.Println("Failed to save notifications:", err)
+	}
+
+	if config.SMTP == nil {
+		return
+	}
+
+	var users []models.User
+	if err := config.DB.Select("user_id", "email").Where("user_id IN ?", userIDs).Find(&users).Error; err != nil {
+		log.Println("Failed to load notification recipients:", err)
+		return
+	}
+	for _, user := range users {
+		if err := config.SendMail(user.Email, n.Title, n.Message); err != nil {
+			log.Println("Failed to send notification email to "+user.Email+":", err)
+		}
+	}
+}
+
+// notifyTicketHolders memberi tahu pemegang tiket. Tiket impor tanpa akun
+// hanya bisa dihubungi lewat email pemegangnya.
+func notifyTicketHolders(tickets []models.Ticket, n notice) {
+	userIDs := []string{}
+	for _, ticket := range tickets {
+		if ticket.Source == models.TicketSourceImport && ticket.HolderEmail != "" {
+			if err := config.SendMail(ticket.HolderEmail, n.Title, n.Message); err != nil {
+				log.Println("Failed to send notification email to "+ticket.HolderEmail+":", err)
+			}
+			continue
+		}
+		userIDs = append(userIDs, ticket.OwnerID)
+	}
+
+	notifyUsers(userIDs, n)
+}
+
+// GetMyNotifications - Notifikasi user login, terbaru dulu (?unread=true)
+func GetMyNotifications(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	query := config.DB.Model(&models.Notification{}).Where("user_id = ?", user.UserID)
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	notifications, pagination, err := paginate(c, query, notificationListSpec, func(db *gorm.DB) *gorm.DB {
+		return db
+	})
+	if err != nil {
+		return listError(c, err, "Failed to fetch notifications")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Notifications retrieved successfully",
+		"data":       notifications,
+		"pagination": pagination,
+	})
+}
+
+// MarkNotificationRead - Tandai notifikasi sudah dibaca; id "all" menandai semuanya
+func MarkNotificationRead(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	query := config.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", user.UserID)
+	if id := c.Params("id"); id != "all" {
+		query = query.Where("notification_id = ?", id)
+	}
+
+	if err := query.Update("read_at", time.Now()).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update notification",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Notification marked as read",
+	})
+}
@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidListQuery = errors.New("invalid list query")
+)
+
+// pageLimit membaca query ?limit= dengan batas default dan maksimum
+func pageLimit(c *fiber.Ctx) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		return defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
+// keysetCursor - posisi baris terakhir halaman sebelumnya: nilai kolom sort
+// dan ID sebagai tiebreaker. Sort ikut disimpan agar cursor tidak dipakai
+// dengan urutan lain.
+type keysetCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeCursor(cursor keysetCursor) string {
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func decodeCursor(raw string) (keysetCursor, error) {
+	var cursor keysetCursor
+	payload, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID == "" {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// keysetSort - satu urutan untuk pagination berbasis cursor
+type keysetSort struct {
+	Name     string
+	Column   string
+	IDColumn string
+	Desc     bool
+	parse    func(string) (interface{}, error)
+}
+
+// apply menambahkan ORDER BY dan, jika ada cursor, kondisi "setelah cursor"
+func (s keysetSort) apply(query *gorm.DB, rawCursor string) (*gorm.DB, error) {
+	direction, compare := "ASC", ">"
+	if s.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if rawCursor != "" {
+		cursor, err := decodeCursor(rawCursor)
+		if err != nil || cursor.Sort != s.Name {
+			return nil, ErrInvalidCursor
+		}
+		value, err := s.parse(cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		query = query.Where(
+			"("+s.Column+" "+compare+" ?) OR ("+s.Column+" = ? AND "+s.IDColumn+" "+compare+" ?)",
+			value, value, cursor.ID,
+		)
+	}
+
+	return query.Order(s.Column + " " + direction).Order(s.IDColumn + " " + direction), nil
+}
+
+// listSort - kolom yang boleh dipakai di ?sort= untuk satu resource
+type listSort[T any] struct {
+	Column      string
+	DefaultDesc bool
+	parse       func(string) (interface{}, error)
+	value       func(T) string
+}
+
+// listSpec - allowlist sort satu resource beserta kolom ID sebagai tiebreaker
+type listSpec[T any] struct {
+	IDColumn    string
+	DefaultSort string
+	Sorts       map[string]listSort[T]
+	id          func(T) string
+}
+
+func (spec listSpec[T]) sortNames() string {
+	names := make([]string, 0, len(spec.Sorts))
+	for name := range spec.Sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// paginate menjalankan query list dengan parameter bersama:
+//
+//	limit  - jumlah per halaman (default 20, maks 100)
+//	sort   - kolom dari allowlist resource; awalan "-" berarti descending
+//	order  - asc/desc, menimpa arah default kolom
+//	page   - halaman berbasis offset (default 1), atau
+//	cursor - next_cursor dari response sebelumnya (keyset, tidak bisa digabung dengan page)
+//
+// query harus sudah memakai Model dan filter; preload dipasang terpisah agar
+// tidak ikut ke query COUNT.
+func paginate[T any](c *fiber.Ctx, query *gorm.DB, spec listSpec[T], preload func(*gorm.DB) *gorm.DB) ([]T, fiber.Map, error) {
+	sortName := c.Query("sort", spec.DefaultSort)
+	desc := false
+	if strings.HasPrefix(sortName, "-") {
+		sortName, desc = strings.TrimPrefix(sortName, "-"), true
+	}
+	column, ok := spec.Sorts[sortName]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: sort must be one of %s", ErrInvalidListQuery, spec.sortNames())
+	}
+	if !desc {
+		desc = column.DefaultDesc
+	}
+
+	switch c.Query("order") {
+	case "":
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	default:
+		return nil, nil, fmt.Errorf("%w: order must be asc or desc", ErrInvalidListQuery)
+	}
+
+	limit := pageLimit(c)
+	cursor := c.Query("cursor")
+	page := 1
+	if raw := c.Query("page"); raw != "" {
+		if cursor != "" {
+			return nil, nil, fmt.Errorf("%w: use either page or cursor", ErrInvalidListQuery)
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 {
+			return nil, nil, fmt.Errorf("%w: page must be a positive number", ErrInvalidListQuery)
+		}
+		page = value
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	direction := "asc"
+	if desc {
+		direction = "desc"
+	}
+	keyset := keysetSort{
+		Name:     sortName + ":" + direction,
+		Column:   column.Column,
+		IDColumn: spec.IDColumn,
+		Desc:     desc,
+		parse:    column.parse,
+	}
+	query, err := keyset.apply(query, cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor == "" {
+		query = query.Offset((page - 1) * limit)
+	}
+	if preload != nil {
+		query = preload(query)
+	}
+
+	var rows []T
+	if err := query.Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	nextCursor := ""
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		nextCursor = encodeCursor(keysetCursor{
+			Sort:  keyset.Name,
+			Value: column.value(last),
+			ID:    spec.id(last),
+		})
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+
+	pagination := fiber.Map{
+		"limit":       limit,
+		"total":       total,
+		"sort":        sortName,
+		"order":       direction,
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != "",
+	}
+	if cursor == "" {
+		pagination["page"] = page
+		pagination["total_pages"] = (total + int64(limit) - 1) / int64(limit)
+	}
+
+	return rows, pagination, nil
+}
+
+// listError - 400 untuk parameter list yang tidak valid, selain itu 500
+func listError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, ErrInvalidListQuery) || errors.Is(err, ErrInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
+
+func parseTimeCursor(value string) (interface{}, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+func parseFloatCursor(value string) (interface{}, error) {
+	return strconv.ParseFloat(value, 64)
+}
+
+func parseUintCursor(value string) (interface{}, error) {
+	return strconv.ParseUint(value, 10, 64)
+}
+
+func parseStringCursor(value string) (interface{}, error) {
+	return value, nil
+}
+
+func formatTimeCursor(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// Allowlist sort per resource
+
+var eventListSpec = listSpec[models.Event]{
+	IDColumn:    "events.event_id",
+	DefaultSort: "created_at",
+	id:          func(e models.Event) string { return e.EventID },
+	Sorts: map[string]listSort[models.Event]{
+		"created_at": {Column: "events.created_at", DefaultDesc: true, parse: parseTimeCursor, value: func(e models.Event) string { return formatTimeCursor(e.CreatedAt) }},
+		"date":       {Column: "events.date_start", parse: parseTimeCursor, value: func(e models.Event) string { return formatTimeCursor(e.DateStart) }},
+		"name":       {Column: "events.name", parse: parseStringCursor, value: func(e models.Event) string { return e.Name }},
+		"popularity": {Column: "events.total_likes", DefaultDesc: true, parse: parseUintCursor, value: func(e models.Event) string { return strconv.FormatUint(uint64(e.TotalLikes), 10) }},
+		"sold":       {Column: "events.total_tickets_sold", DefaultDesc: true, parse: parseUintCursor, value: func(e models.Event) string { return strconv.FormatUint(uint64(e.TotalTicketsSold), 10) }},
+		"price":      {Column: "events.min_price", parse: parseFloatCursor, value: func(e models.Event) string { return strconv.FormatFloat(e.MinPrice, 'f', -1, 64) }},
+	},
+}
+
+var userListSpec = listSpec[models.User]{
+	IDColumn:    "user_id",
+	DefaultSort: "created_at",
+	id:          func(u models.User) string { return u.UserID },
+	Sorts: map[string]listSort[models.User]{
+		"created_at": {Column: "created_at", DefaultDesc: true, parse: parseTimeCursor, value: func(u models.User) string { return formatTimeCursor(u.CreatedAt) }},
+		"name":       {Column: "name", parse: parseStringCursor, value: func(u models.User) string { return u.Name }},
+		"username":   {Column: "username", parse: parseStringCursor, value: func(u models.User) string { return u.Username }},
+		"email":      {Column: "email", parse: parseStringCursor, value: func(u models.User) string { return u.Email }},
+	},
+}
+
+var ticketListSpec = listSpec[models.Ticket]{
+	IDColumn:    "ticket_id",
+	DefaultSort: "created_at",
+	id:          func(t models.Ticket) string { return t.TicketID },
+	Sorts: map[string]listSort[models.Ticket]{
+		"created_at": {Column: "created_at", DefaultDesc: true, parse: parseTimeCursor, value: func(t models.Ticket) string { return formatTimeCursor(t.CreatedAt) }},
+		"status":     {Column: "status", parse: parseStringCursor, value: func(t models.Ticket) string { return t.Status }},
+	},
+}
+
+var transactionListSpec = listSpec[models.TransactionHistory]{
+	IDColumn:    "transaction_id",
+	DefaultSort: "transaction_time",
+	id:          func(t models.TransactionHistory) string { return t.TransactionID },
+	Sorts: map[string]listSort[models.TransactionHistory]{
+		"transaction_time": {Column: "transaction_time", DefaultDesc: true, parse: parseTimeCursor, value: func(t models.TransactionHistory) string { return formatTimeCursor(t.TransactionTime) }},
+		"created_at":       {Column: "created_at", DefaultDesc: true, parse: parseTimeCursor, value: func(t models.TransactionHistory) string { return formatTimeCursor(t.CreatedAt) }},
+		"price_total":      {Column: "price_total", DefaultDesc: true, parse: parseFloatCursor, value: func(t models.TransactionHistory) string { return strconv.FormatFloat(t.PriceTotal, 'f', -1, 64) }},
+	},
+}
+
+var feedbackListSpec = listSpec[models.Feedback]{
+	IDColumn:    "feedback_id",
+	DefaultSort: "created_at",
+	id:          func(f models.Feedback) string { return f.FeedbackID },
+	Sorts: map[string]listSort[models.Feedback]{
+		"created_at": {Column: "created_at", DefaultDesc: true, parse: parseTimeCursor, value: func(f models.Feedback) string { return formatTimeCursor(f.CreatedAt) }},
+		"updated_at": {Column: "updated_at", DefaultDesc: true, parse: parseTimeCursor, value: func(f models.Feedback) string { return formatTimeCursor(f.UpdatedAt) }},
+		"status":     {Column: "status", parse: parseStringCursor, value: func(f models.Feedback) string { return f.Status }},
+	},
+}
+
+var checkInLogListSpec = listSpec[models.CheckInLog]{
+	IDColumn:    "check_in_log_id",
+	DefaultSort: "scanned_at",
+	id:          func(l models.CheckInLog) string { return l.CheckInLogID },
+	Sorts: map[string]listSort[models.CheckInLog]{
+		"scanned_at": {Column: "scanned_at", DefaultDesc: true, parse: parseTimeCursor, value: func(l models.CheckInLog) string { return formatTimeCursor(l.ScannedAt) }},
+	},
+}
+
+var eventRevisionListSpec = listSpec[models.EventRevision]{
+	IDColumn:    "revision_id",
+	DefaultSort: "version",
+	id:          func(r models.EventRevision) string { return r.RevisionID },
+	Sorts: map[string]listSort[models.EventRevision]{
+		"version":    {Column: "version", DefaultDesc: true, parse: parseUintCursor, value: func(r models.EventRevision) string { return strconv.FormatUint(uint64(r.Version), 10) }},
+		"created_at": {Column: "created_at", DefaultDesc: true, parse: parseTimeCursor, value: func(r models.EventRevision) string { return formatTimeCursor(r.CreatedAt) }},
+	},
+}
+
+// antrian review admin: change-set terlama dulu
+var revisionQueueListSpec = listSpec[models.EventRevision]{
+	IDColumn:    "revision_id",
+	DefaultSort: "created_at",
+	id:          func(r models.EventRevision) string { return r.RevisionID },
+	Sorts: map[string]listSort[models.EventRevision]{
+		"created_at": {Column: "created_at", parse: parseTimeCursor, value: func(r models.EventRevision) string { return formatTimeCursor(r.CreatedAt) }},
+	},
+}
+
+var notificationListSpec = listSpec[models.Notification]{
+	IDColumn:    "notification_id",
+	DefaultSort: "created_at",
+	id:          func(n models.Notification) string { return n.NotificationID },
+	Sorts: map[string]listSort[models.Notification]{
+		"created_at": {Column: "created_at", DefaultDesc: true, parse: parseTimeCursor, value: func(n models.Notification) string { return formatTimeCursor(n.CreatedAt) }},
+	},
+}
+
+var refundListSpec = listSpec[models.Refund]{
+	IDColumn:    "refund_id",
+	DefaultSort: "created_at",
+	id:          func(r models.Refund) string { return r.RefundID },
+	Sorts: map[string]listSort[models.Refund]{
+		"created_at": {Column: "created_at", DefaultDesc: true, parse: parseTimeCursor, value: func(r models.Refund) string { return formatTimeCursor(r.CreatedAt) }},
+		"amount":     {Column: "amount", DefaultDesc: true, parse: parseFloatCursor, value: func(r models.Refund) string { return strconv.FormatFloat(r.Amount, 'f', -1, 64) }},
+		"status":     {Column: "status", parse: parseStringCursor, value: func(r models.Refund) string { return r.Status }},
+	},
+}
+
+// nearbyListSpec - hasil GetNearbyEvents dari subquery "nearby" berisi
+// event_id dan distance_km
+var nearbyListSpec = listSpec[nearbyHit]{
+	IDColumn:    "nearby.event_id",
+	DefaultSort: "distance",
+	id:          func(h nearbyHit) string { return h.EventID },
+	Sorts: map[string]listSort[nearbyHit]{
+		"distance": {Column: "nearby.distance_km", parse: parseFloatCursor, value: func(h nearbyHit) string { return strconv.FormatFloat(h.DistanceKm, 'f', -1, 64) }},
+	},
+}
+
+// trashListSpec - baris trash diurutkan menurut waktu hapus
+func trashListSpec[T any](idColumn string, id func(T) string, deletedAt func(T) gorm.DeletedAt) listSpec[T] {
+	return listSpec[T]{
+		IDColumn:    idColumn,
+		DefaultSort: "deleted_at",
+		id:          id,
+		Sorts: map[string]listSort[T]{
+			"deleted_at": {Column: "deleted_at", DefaultDesc: true, parse: parseTimeCursor, value: func(row T) string { return formatTimeCursor(deletedAt(row).Time) }},
+		},
+	}
+}
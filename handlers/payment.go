@@ -48,6 +48,7 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 	// Calculate total dan validasi quota
 	var total float64
 	var transactionDetails []models.TransactionDetail
+	seatsByCategory := make(map[string][]string)
 
 	for _, item := range cartItems {
 		// Validasi quota tersedia
@@ -58,14 +59,38 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 			})
 		}
 
+		if problem := eventSaleProblem(ticketCategory.EventID); problem != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": problem + ": " + ticketCategory.Name,
+			})
+		}
+
+		if ticketCategory.Archived {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Ticket category is no longer on sale: " + ticketCategory.Name,
+			})
+		}
+
 		// Cek ketersediaan quota
-		if ticketCategory.Sold+item.Quantity > ticketCategory.Quota {
+		if ticketCategory.Sold+ticketCategory.Imported+item.Quantity > ticketCategory.Quota {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Not enough quota for ticket category: " + ticketCategory.Name,
 			})
 		}
 
-		total += item.PriceTotal
+		if ticketCategory.Seated {
+			if uint(len(item.SeatIDs)) != item.Quantity {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Select seats for ticket category: " + ticketCategory.Name,
+				})
+			}
+			seatsByCategory[item.TicketCategoryID] = item.SeatIDs
+		}
+
+		// subtotal dihitung dari harga kategori saat checkout, bukan dari
+		// total cart yang bisa tertinggal setelah harga berubah
+		subtotal := ticketCategory.Price * float64(item.Quantity)
+		total += subtotal
 
 		// Prepare transaction detail
 		transactionDetail := models.TransactionDetail{
@@ -73,7 +98,7 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 			TicketCategoryID:    item.TicketCategoryID,
 			OwnerID:             user.UserID,
 			Quantity:            item.Quantity,
-			Subtotal:            item.PriceTotal,
+			Subtotal:            subtotal,
 		}
 		transactionDetails = append(transactionDetails, transactionDetail)
 	}
@@ -96,7 +121,8 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 		TransactionStatus: "pending",
 	}
 
-	if err := config.DB.Create(&transaction).Error; err != nil {
+	if err := tx.Create(&transaction).Error; err != nil {
+		tx.Rollback()
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create transaction: " + err.Error(),
 		})
@@ -106,7 +132,6 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 	for _, detail := range transactionDetails {
 		// Set transaction ID untuk detail
 		detail.TransactionID = transaction.TransactionID
-		var statusTicket string = "pending"
 
 		if err := tx.Create(&detail).Error; err != nil {
 			tx.Rollback()
@@ -125,8 +150,6 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 		}
 
 		if detail.Subtotal == 0 {
-			statusTicket = "active"
-
 			if err := tx.Model(&models.TicketCategory{}).
 				Where("ticket_category_id = ?", detail.TicketCategoryID).
 				Update("sold", gorm.Expr("sold + ?", detail.Quantity)).Error; err != nil {
@@ -167,7 +190,8 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 				EventID:          ticketCategory.EventID,
 				TicketCategoryID: detail.TicketCategoryID,
 				OwnerID:          user.UserID,
-				Status:           statusTicket,
+				TransactionID:    transaction.TransactionID,
+				Status:           "pending",
 				Code:             utils.GenerateTicketCode(), // GENERATE UNIQUE CODE
 				CreatedAt:        time.Now(),
 				UpdatedAt:        time.Now(),
@@ -175,6 +199,18 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 				Tag:              "My Ticket",
 			}
 
+			if seats := seatsByCategory[detail.TicketCategoryID]; i < len(seats) {
+				label, err := claimSeat(tx, seats[i], user.UserID, ticket.TicketID, detail.Subtotal == 0)
+				if err != nil {
+					tx.Rollback()
+					return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+						"error": "Failed to reserve seat: " + err.Error(),
+					})
+				}
+				ticket.SeatID = seats[i]
+				ticket.SeatLabel = label
+			}
+
 			if err := tx.Create(&ticket).Error; err != nil {
 				tx.Rollback()
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -182,6 +218,27 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 				})
 			}
 		}
+
+		// tiket gratis langsung aktif lewat lifecycle agar tercatat di history
+		if detail.Subtotal == 0 {
+			if _, err := ticketLifecycle.transitionWhere(tx, "pending", "active", user.UserID, "free ticket",
+				"transaction_id = ? AND ticket_category_id = ?", transaction.TransactionID, detail.TicketCategoryID); err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to activate free ticket: " + err.Error(),
+				})
+			}
+		}
+	}
+
+	// checkout gratis tidak lewat Midtrans, transaksi langsung paid
+	if total == 0 {
+		if err := transactionLifecycle.transition(tx, transaction.TransactionID, "pending", "paid", user.UserID, "free checkout", nil); err != nil {
+			tx.Rollback()
+			return c.Status(transitionStatusCode(err)).JSON(fiber.Map{
+				"error": "Failed obtain free ticket: " + err.Error(),
+			})
+		}
 	}
 
 	// Clear cart
@@ -230,13 +287,6 @@ func PaymentMidtrans(c *fiber.Ctx) error {
 	}
 
 	if req.TransactionDetails.GrossAmt == 0 {
-
-		if err := config.DB.Model(&transaction).Where("transaction_id = ?", transaction.TransactionID).Update("transaction_status", "paid").Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed obtain free ticket: " + err.Error(),
-			})
-		}
-
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"message":        "Free ticket added successfully",
 			"transaction_id": transaction.TransactionID,
@@ -316,24 +366,30 @@ func handleSettlement(c *fiber.Ctx, orderID string, notifPayload map[string]inte
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to start transaction"})
 	}
 
-	// Update transaction status
-	result := tx.Model(&models.TransactionHistory{}).
-		Where("transaction_id = ?", orderID).
-		Updates(map[string]interface{}{
-			"transaction_status": "paid",
-			"transaction_time":   time.Now(), // Gunakan waktu server sebagai fallback
-		})
+	var transaction models.TransactionHistory
+	if err := tx.First(&transaction, "transaction_id = ?", orderID).Error; err != nil {
+		tx.Rollback()
+		log.Printf("No transaction found with ID: %s", orderID)
+		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
+	}
 
-	if result.Error != nil {
+	// Midtrans bisa mengirim ulang notifikasi settlement
+	if transaction.TransactionStatus == "paid" {
 		tx.Rollback()
-		log.Printf("Failed to update transaction status: %v", result.Error)
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update transaction status"})
+		return c.JSON(fiber.Map{
+			"message": "Payment already processed",
+			"orderID": orderID,
+			"status":  "paid",
+		})
 	}
 
-	if result.RowsAffected == 0 {
+	// Update transaction status
+	if err := transactionLifecycle.transition(tx, orderID, transaction.TransactionStatus, "paid", midtransActor, "settlement", map[string]interface{}{
+		"transaction_time": time.Now(), // Gunakan waktu server sebagai fallback
+	}); err != nil {
 		tx.Rollback()
-		log.Printf("No transaction found with ID: %s", orderID)
-		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
+		log.Printf("Failed to update transaction status: %v", err)
+		return c.Status(transitionStatusCode(err)).JSON(fiber.Map{"error": "Failed to update transaction status"})
 	}
 
 	// Get transaction details
@@ -379,12 +435,8 @@ func handleSettlement(c *fiber.Ctx, orderID string, notifPayload map[string]inte
 		}
 
 		// Update tickets status from pending to active
-		if err := tx.Model(&models.Ticket{}).
-			Where("ticket_category_id = ? AND owner_id = ? AND status = ?",
-				detail.TicketCategoryID, detail.OwnerID, "pending").
-			Updates(map[string]interface{}{
-				"status": "active",
-			}).Error; err != nil {
+		if _, err := ticketLifecycle.transitionWhere(tx, "pending", "active", midtransActor, "payment settled",
+			"transaction_id = ? AND ticket_category_id = ? AND owner_id = ?", orderID, detail.TicketCategoryID, detail.OwnerID); err != nil {
 			tx.Rollback()
 			log.Printf("Failed to update tickets status: %v", err)
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to update tickets"})
@@ -408,12 +460,46 @@ func handleSettlement(c *fiber.Ctx, orderID string, notifPayload map[string]inte
 		}
 	}
 
+	if err := markTransactionSeatsSold(tx, orderID); err != nil {
+		tx.Rollback()
+		log.Printf("Failed to update seats: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update seats"})
+	}
+
+	// pembayaran yang selesai setelah event dibatalkan langsung direfund
+	var cancelledTickets []models.Ticket
+	if err := tx.Where("transaction_id = ? AND status = ? AND event_id IN (?)", orderID, "cancelled",
+		tx.Model(&models.Event{}).Select("event_id").Where("status = ?", "cancelled")).
+		Find(&cancelledTickets).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Failed to check cancelled tickets: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check cancelled tickets"})
+	}
+	byEvent := make(map[string][]models.Ticket)
+	for _, ticket := range cancelledTickets {
+		byEvent[ticket.EventID] = append(byEvent[ticket.EventID], ticket)
+	}
+	var lateRefunds []models.Refund
+	for eventID, tickets := range byEvent {
+		refunds, err := createRefunds(tx, eventID, tickets, "event cancelled")
+		if err != nil {
+			tx.Rollback()
+			log.Printf("Failed to create refunds: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to create refunds"})
+		}
+		lateRefunds = append(lateRefunds, refunds...)
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		log.Printf("Failed to commit transaction: %v", err)
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to commit transaction"})
 	}
 
+	if len(lateRefunds) > 0 {
+		go processRefunds(lateRefunds)
+	}
+
 	log.Printf("Successfully processed settlement for OrderID: %s", orderID)
 	return c.JSON(fiber.Map{
 		"message": "Payment successful and processed",
@@ -440,19 +526,17 @@ func handleFailure(c *fiber.Ctx, orderID string, status string) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to start transaction"})
 	}
 
-	// Update transaction status
-	result := tx.Model(&models.TransactionHistory{}).
-		Where("transaction_id = ?", orderID).
-		Update("transaction_status", newStatus)
-
-	if result.Error != nil {
+	var transaction models.TransactionHistory
+	if err := tx.First(&transaction, "transaction_id = ?", orderID).Error; err != nil {
 		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update transaction status"})
+		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
 	}
 
-	if result.RowsAffected == 0 {
+	// Update transaction status
+	if err := transactionLifecycle.transition(tx, orderID, transaction.TransactionStatus, newStatus, midtransActor, status, nil); err != nil {
 		tx.Rollback()
-		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
+		log.Printf("Failed to update transaction status: %v", err)
+		return c.Status(transitionStatusCode(err)).JSON(fiber.Map{"error": "Failed to update transaction status"})
 	}
 
 	// Update tickets status to payment_failed
@@ -463,15 +547,18 @@ func handleFailure(c *fiber.Ctx, orderID string, status string) error {
 	}
 
 	for _, detail := range transactionDetails {
-		if err := tx.Model(&models.Ticket{}).
-			Where("ticket_category_id = ? AND owner_id = ? AND status = ?",
-				detail.TicketCategoryID, detail.OwnerID, "pending").
-			Update("status", "payment_failed").Error; err != nil {
+		if _, err := ticketLifecycle.transitionWhere(tx, "pending", "payment_failed", midtransActor, "payment "+status,
+			"transaction_id = ? AND ticket_category_id = ? AND owner_id = ?", orderID, detail.TicketCategoryID, detail.OwnerID); err != nil {
 			tx.Rollback()
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to update ticket status"})
 		}
 	}
 
+	if err := releaseTransactionSeats(tx, orderID); err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to release seats"})
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to commit transaction"})
 	}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// createRefunds membuat refund pending untuk tiket yang dibatalkan dari transaksi
+// paid, satu refund per transaksi. Nominal per tiket diambil dari subtotal
+// transaksi agar perubahan harga setelah pembelian tidak berpengaruh.
+func createRefunds(tx *gorm.DB, eventID string, tickets []models.Ticket, reason string) ([]models.Refund, error) {
+	byTransaction := make(map[string][]models.Ticket)
+	for _, ticket := range tickets {
+		if ticket.TransactionID == "" || ticket.Source == models.TicketSourceImport {
+			continue
+		}
+		byTransaction[ticket.TransactionID] = append(byTransaction[ticket.TransactionID], ticket)
+	}
+
+	refunds := []models.Refund{}
+	for transactionID, transactionTickets := range byTransaction {
+		var transaction models.TransactionHistory
+		if err := tx.First(&transaction, "transaction_id = ?", transactionID).Error; err != nil {
+			return nil, err
+		}
+		if transaction.TransactionStatus != "paid" {
+			continue
+		}
+
+		var details []models.TransactionDetail
+		if err := tx.Where("transaction_id = ?", transactionID).Find(&details).Error; err != nil {
+			return nil, err
+		}
+		unitPrice := make(map[string]float64)
+		for _, detail := range details {
+			if detail.Quantity > 0 {
+				unitPrice[detail.TicketCategoryID] = detail.Subtotal / float64(detail.Quantity)
+			}
+		}
+
+		refund := models.Refund{
+			RefundID:      utils.GenerateRefundID(),
+			TransactionID: transactionID,
+			EventID:       eventID,
+			OwnerID:       transaction.OwnerID,
+			Reason:        reason,
+			Status:        models.RefundPending,
+		}
+		for _, ticket := range transactionTickets {
+			refund.TicketIDs = append(refund.TicketIDs, ticket.TicketID)
+			refund.Amount += unitPrice[ticket.TicketCategoryID]
+		}
+		if refund.Amount <= 0 {
+			continue
+		}
+
+		if err := tx.Create(&refund).Error; err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, nil
+}
+
+// refundGateway meminta refund ke Midtrans untuk order = transaction_id
+func refundGateway(refund models.Refund) (string, error) {
+	var client coreapi.Client
+	client.New(os.Getenv("MIDTRANS_SERVER_KEY"), midtrans.Sandbox)
+
+	resp, mErr := client.RefundTransaction(refund.TransactionID, &coreapi.RefundReq{
+		RefundKey: refund.RefundID,
+		Amount:    int64(refund.Amount),
+		Reason:    refund.Reason,
+	})
+	if mErr != nil {
+		return mErr.Message, mErr
+	}
+	if resp.StatusCode != "200" && resp.StatusCode != "201" {
+		return resp.StatusMessage, errors.New(resp.StatusMessage)
+	}
+	return resp.StatusMessage, nil
+}
+
+// processRefund mengirim satu refund ke gateway dan mencatat hasilnya.
+// Transaksi menjadi refunded setelah seluruh nominalnya dikembalikan.
+func processRefund(refundID string) {
+	var refund models.Refund
+	if err := config.DB.First(&refund, "refund_id = ? AND status IN ?", refundID, []string{models.RefundPending, models.RefundFailed}).Error; err != nil {
+		return
+	}
+
+	message, err := refundGateway(refund)
+	status := models.RefundSucceeded
+	if err != nil {
+		log.Println("Refund "+refund.RefundID+" failed:", err)
+		status = models.RefundFailed
+	}
+
+	if err := config.DB.Model(&refund).Updates(map[string]interface{}{
+		"status":          status,
+		"gateway_message": message,
+	}).Error; err != nil {
+		log.Println("Failed to save refund status:", err)
+		return
+	}
+
+	if status == models.RefundFailed {
+		return
+	}
+
+	var transaction models.TransactionHistory
+	var refunded float64
+	if err := config.DB.First(&transaction, "transaction_id = ?", refund.TransactionID).Error; err == nil {
+		config.DB.Model(&models.Refund{}).
+			Where("transaction_id = ? AND status = ?", refund.TransactionID, models.RefundSucceeded).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&refunded)
+		if transaction.TransactionStatus == "paid" && refunded >= transaction.PriceTotal {
+			if err := transactionLifecycle.transition(config.DB, transaction.TransactionID, "paid", "refunded", midtransActor, "refunded", nil); err != nil {
+				log.Println("Failed to mark transaction refunded:", err)
+			}
+		}
+	}
+
+	notifyUsers([]string{refund.OwnerID}, notice{
+		EventID: refund.EventID,
+		Type:    models.NotificationRefund,
+		Title:   "Refund processed",
+		Message: fmt.Sprintf("Your refund of Rp %.0f for order %s has been processed.", refund.Amount, refund.TransactionID),
+	})
+}
+
+// processRefunds dijalankan di background setelah transaksi DB di-commit
+func processRefunds(refunds []models.Refund) {
+	for _, refund := range refunds {
+		processRefund(refund.RefundID)
+	}
+}
+
+// ResumePendingRefunds memproses ulang refund yang belum sempat dikirim
+// (mis. server restart sebelum goroutine selesai)
+func ResumePendingRefunds() {
+	var refunds []models.Refund
+	if err := config.DB.Where("status = ?", models.RefundPending).Find(&refunds).Error; err != nil {
+		log.Println("Failed to load pending refunds:", err)
+		return
+	}
+	if len(refunds) > 0 {
+		log.Printf(" --  Resuming %d pending refunds", len(refunds))
+		go processRefunds(refunds)
+	}
+}
+
+// GetEventRefunds - Daftar refund satu event untuk organizer/admin
+func GetEventRefunds(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	query := config.DB.Model(&models.Refund{}).Where("event_id = ?", event.EventID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	refunds, pagination, err := paginate(c, query, refundListSpec, nil)
+	if err != nil {
+		return listError(c, err, "Failed to fetch refunds")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Refunds retrieved successfully",
+		"refunds":    refunds,
+		"pagination": pagination,
+	})
+}
+
+// RetryRefund - Admin mengirim ulang refund yang gagal ke gateway
+func RetryRefund(c *fiber.Ctx) error {
+	var refund models.Refund
+	if err := config.DB.First(&refund, "refund_id = ? AND event_id = ?", c.Params("refund_id"), c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Refund not found",
+		})
+	}
+
+	if refund.Status != models.RefundFailed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Only failed refunds can be retried",
+		})
+	}
+
+	processRefund(refund.RefundID)
+
+	if err := config.DB.First(&refund, "refund_id = ?", refund.RefundID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load refund",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Refund retried",
+		"refund":  refund,
+	})
+}
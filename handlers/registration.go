@@ -0,0 +1,664 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 \-]{5,19}$`)
+
+type RegistrationQuestionRequest struct {
+	TicketCategoryID string   `json:"ticket_category_id"`
+	Label            string   `json:"label"`
+	HelpText         string   `json:"help_text"`
+	FieldType        string   `json:"field_type"`
+	Options          []string `json:"options"`
+	Required         bool     `json:"required"`
+	MinLength        *int     `json:"min_length"`
+	MaxLength        *int     `json:"max_length"`
+	MinValue         *float64 `json:"min_value"`
+	MaxValue         *float64 `json:"max_value"`
+	Pattern          string   `json:"pattern"`
+	ShowOnCheckIn    bool     `json:"show_on_check_in"`
+	Position         int      `json:"position"`
+}
+
+type ticketAnswerRequest struct {
+	QuestionID string          `json:"question_id"`
+	Value      json.RawMessage `json:"value"`
+}
+
+// applyQuestionRequest memvalidasi definisi pertanyaan dan menyalinnya ke model
+func applyQuestionRequest(event models.Event, req RegistrationQuestionRequest, question *models.RegistrationQuestion) string {
+	if strings.TrimSpace(req.Label) == "" {
+		return "Label is required"
+	}
+
+	switch req.FieldType {
+	case models.FieldText, models.FieldTextarea, models.FieldNumber, models.FieldEmail,
+		models.FieldPhone, models.FieldDate, models.FieldCheckbox:
+	case models.FieldSelect, models.FieldMultiSelect:
+		if len(req.Options) == 0 {
+			return "Options are required for " + req.FieldType + " fields"
+		}
+	default:
+		return "Invalid field_type"
+	}
+
+	if req.TicketCategoryID != "" {
+		found := false
+		for _, category := range event.TicketCategories {
+			if category.TicketCategoryID == req.TicketCategoryID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "Ticket category does not belong to this event"
+		}
+	}
+
+	if req.Pattern != "" {
+		if _, err := regexp.Compile(req.Pattern); err != nil {
+			return "Invalid pattern: " + err.Error()
+		}
+	}
+
+	if req.MinLength != nil && req.MaxLength != nil && *req.MinLength > *req.MaxLength {
+		return "min_length must not exceed max_length"
+	}
+	if req.MinValue != nil && req.MaxValue != nil && *req.MinValue > *req.MaxValue {
+		return "min_value must not exceed max_value"
+	}
+
+	question.TicketCategoryID = req.TicketCategoryID
+	question.Label = req.Label
+	question.HelpText = req.HelpText
+	question.FieldType = req.FieldType
+	question.Options = req.Options
+	question.Required = req.Required
+	question.MinLength = req.MinLength
+	question.MaxLength = req.MaxLength
+	question.MinValue = req.MinValue
+	question.MaxValue = req.MaxValue
+	question.Pattern = req.Pattern
+	question.ShowOnCheckIn = req.ShowOnCheckIn
+	question.Position = req.Position
+	return ""
+}
+
+// ticketQuestions - pertanyaan yang berlaku untuk tiket (level event + kategorinya)
+func ticketQuestions(db *gorm.DB, ticket models.Ticket) ([]models.RegistrationQuestion, error) {
+	var questions []models.RegistrationQuestion
+	err := db.Where("event_id = ? AND (ticket_category_id = '' OR ticket_category_id IS NULL OR ticket_category_id = ?)", ticket.EventID, ticket.TicketCategoryID).
+		Order("position ASC, created_at ASC").
+		Find(&questions).Error
+	return questions, err
+}
+
+func ticketAnswerMap(db *gorm.DB, ticketIDs []string) (map[string]map[string]string, error) {
+	var answers []models.TicketAnswer
+	if err := db.Where("ticket_id IN ?", ticketIDs).Find(&answers).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]string)
+	for _, answer := range answers {
+		if result[answer.TicketID] == nil {
+			result[answer.TicketID] = make(map[string]string)
+		}
+		result[answer.TicketID][answer.QuestionID] = answer.Value
+	}
+	return result, nil
+}
+
+// registrationComplete - semua pertanyaan wajib sudah dijawab
+func registrationComplete(questions []models.RegistrationQuestion, answers map[string]string) bool {
+	for _, question := range questions {
+		if question.Required && answers[question.QuestionID] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeAnswer memvalidasi jawaban sesuai tipe field dan mengembalikan nilai
+// yang disimpan. Jawaban kosong berarti jawaban dihapus.
+func normalizeAnswer(question models.RegistrationQuestion, raw json.RawMessage) (string, string) {
+	if question.FieldType == models.FieldMultiSelect {
+		var values []string
+		if len(raw) > 0 && string(raw) != "null" {
+			if err := json.Unmarshal(raw, &values); err != nil {
+				return "", question.Label + " must be a list of options"
+			}
+		}
+		if len(values) == 0 {
+			return "", ""
+		}
+		for _, value := range values {
+			if !containsString(question.Options, value) {
+				return "", question.Label + ": invalid option " + value
+			}
+		}
+		encoded, _ := json.Marshal(values)
+		return string(encoded), ""
+	}
+
+	var value string
+	if len(raw) > 0 && string(raw) != "null" {
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return "", question.Label + " has an invalid value"
+		}
+		switch v := decoded.(type) {
+		case string:
+			value = strings.TrimSpace(v)
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			return "", question.Label + " has an invalid value"
+		}
+	}
+	if value == "" {
+		return "", ""
+	}
+
+	switch question.FieldType {
+	case models.FieldNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", question.Label + " must be a number"
+		}
+		if question.MinValue != nil && number < *question.MinValue {
+			return "", fmt.Sprintf("%s must be at least %g", question.Label, *question.MinValue)
+		}
+		if question.MaxValue != nil && number > *question.MaxValue {
+			return "", fmt.Sprintf("%s must be at most %g", question.Label, *question.MaxValue)
+		}
+	case models.FieldEmail:
+		if _, err := mail.ParseAddress(value); err != nil {
+			return "", question.Label + " must be a valid email address"
+		}
+	case models.FieldPhone:
+		if !phonePattern.MatchString(value) {
+			return "", question.Label + " must be a valid phone number"
+		}
+	case models.FieldDate:
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", question.Label + " must be a date in YYYY-MM-DD format"
+		}
+	case models.FieldSelect:
+		if !containsString(question.Options, value) {
+			return "", question.Label + ": invalid option " + value
+		}
+	case models.FieldCheckbox:
+		checked, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", question.Label + " must be true or false"
+		}
+		// checkbox wajib berarti harus dicentang (misal persetujuan syarat)
+		if !checked {
+			return "", ""
+		}
+		value = "true"
+	}
+
+	length := len([]rune(value))
+	if question.MinLength != nil && length < *question.MinLength {
+		return "", fmt.Sprintf("%s must be at least %d characters", question.Label, *question.MinLength)
+	}
+	if question.MaxLength != nil && length > *question.MaxLength {
+		return "", fmt.Sprintf("%s must be at most %d characters", question.Label, *question.MaxLength)
+	}
+	if question.Pattern != "" {
+		if pattern, err := regexp.Compile(question.Pattern); err == nil && !pattern.MatchString(value) {
+			return "", question.Label + " has an invalid format"
+		}
+	}
+
+	return value, ""
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInAttendee - jawaban yang ditandai tampil di scanner saat check-in
+func checkInAttendee(db *gorm.DB, ticket models.Ticket) fiber.Map {
+	questions, err := ticketQuestions(db, ticket)
+	if err != nil || len(questions) == 0 {
+		return nil
+	}
+
+	answers, err := ticketAnswerMap(db, []string{ticket.TicketID})
+	if err != nil {
+		return nil
+	}
+
+	details := make([]fiber.Map, 0)
+	for _, question := range questions {
+		if !question.ShowOnCheckIn {
+			continue
+		}
+		details = append(details, fiber.Map{
+			"question_id": question.QuestionID,
+			"label":       question.Label,
+			"value":       answers[ticket.TicketID][question.QuestionID],
+		})
+	}
+
+	return fiber.Map{
+		"registration_complete": registrationComplete(questions, answers[ticket.TicketID]),
+		"details":               details,
+	}
+}
+
+// GetRegistrationQuestions - Daftar pertanyaan registrasi event
+func GetRegistrationQuestions(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var questions []models.RegistrationQuestion
+	if err := config.DB.Where("event_id = ?", event.EventID).
+		Order("position ASC, created_at ASC").
+		Find(&questions).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch questions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":   "Questions retrieved successfully",
+		"questions": questions,
+	})
+}
+
+// CreateRegistrationQuestion - Tambah pertanyaan ke form registrasi event
+func CreateRegistrationQuestion(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req RegistrationQuestionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	question := models.RegistrationQuestion{
+		QuestionID: utils.GenerateQuestionID(),
+		EventID:    event.EventID,
+	}
+	if msg := applyQuestionRequest(event, req, &question); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	if err := config.DB.Create(&question).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create question",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":  "Question created successfully",
+		"question": question,
+	})
+}
+
+// UpdateRegistrationQuestion - Ubah pertanyaan registrasi
+func UpdateRegistrationQuestion(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var question models.RegistrationQuestion
+	if err := config.DB.Where("question_id = ? AND event_id = ?", c.Params("question_id"), event.EventID).First(&question).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Question not found",
+		})
+	}
+
+	var req RegistrationQuestionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	if msg := applyQuestionRequest(event, req, &question); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	if err := config.DB.Save(&question).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update question",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Question updated successfully",
+		"question": question,
+	})
+}
+
+// DeleteRegistrationQuestion - Hapus pertanyaan beserta jawabannya
+func DeleteRegistrationQuestion(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	questionID := c.Params("question_id")
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	result := tx.Where("question_id = ? AND event_id = ?", questionID, event.EventID).Delete(&models.RegistrationQuestion{})
+	if result.Error != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete question",
+		})
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Question not found",
+		})
+	}
+
+	if err := tx.Where("question_id = ?", questionID).Delete(&models.TicketAnswer{}).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete answers",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Question deleted successfully",
+	})
+}
+
+// GetTicketAnswers - Form registrasi tiket beserta jawaban yang sudah diisi
+func GetTicketAnswers(c *fiber.Ctx) error {
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	questions, err := ticketQuestions(config.DB, ticket)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch questions",
+		})
+	}
+
+	answers, err := ticketAnswerMap(config.DB, []string{ticket.TicketID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch answers",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":               "Registration form retrieved successfully",
+		"ticket_id":             ticket.TicketID,
+		"questions":             questions,
+		"answers":               answers[ticket.TicketID],
+		"registration_complete": registrationComplete(questions, answers[ticket.TicketID]),
+	})
+}
+
+// SaveTicketAnswers - Isi/ubah data peserta untuk satu tiket, sebelum atau
+// sesudah pembayaran. Jawaban boleh dicicil; kelengkapan dilaporkan di respons.
+func SaveTicketAnswers(c *fiber.Ctx) error {
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if ticket.Status != "pending" && ticket.Status != "active" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Answers can only be changed for pending or active tickets",
+		})
+	}
+
+	var req struct {
+		Answers []ticketAnswerRequest `json:"answers"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	questions, err := ticketQuestions(config.DB, ticket)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch questions",
+		})
+	}
+	questionMap := make(map[string]models.RegistrationQuestion)
+	for _, question := range questions {
+		questionMap[question.QuestionID] = question
+	}
+
+	values := make(map[string]string)
+	var validationErrors []string
+	for _, answer := range req.Answers {
+		question, ok := questionMap[answer.QuestionID]
+		if !ok {
+			validationErrors = append(validationErrors, "Unknown question "+answer.QuestionID)
+			continue
+		}
+		value, msg := normalizeAnswer(question, answer.Value)
+		if msg != "" {
+			validationErrors = append(validationErrors, msg)
+			continue
+		}
+		values[answer.QuestionID] = value
+	}
+
+	if len(validationErrors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":  "Invalid answers",
+			"errors": validationErrors,
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	for questionID, value := range values {
+		if value == "" {
+			if err := tx.Where("ticket_id = ? AND question_id = ?", ticket.TicketID, questionID).Delete(&models.TicketAnswer{}).Error; err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to save answers",
+				})
+			}
+			continue
+		}
+
+		var existing models.TicketAnswer
+		err := tx.Where("ticket_id = ? AND question_id = ?", ticket.TicketID, questionID).First(&existing).Error
+		if err == nil {
+			err = tx.Model(&existing).Update("value", value).Error
+		} else {
+			err = tx.Create(&models.TicketAnswer{
+				TicketAnswerID: utils.GenerateTicketAnswerID(),
+				TicketID:       ticket.TicketID,
+				QuestionID:     questionID,
+				EventID:        ticket.EventID,
+				Value:          value,
+			}).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to save answers",
+			})
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	answers, _ := ticketAnswerMap(config.DB, []string{ticket.TicketID})
+
+	return c.JSON(fiber.Map{
+		"message":               "Answers saved successfully",
+		"answers":               answers[ticket.TicketID],
+		"registration_complete": registrationComplete(questions, answers[ticket.TicketID]),
+	})
+}
+
+// csvCell menetralkan isi sel yang bisa dibaca spreadsheet sebagai formula
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func writeCSVRow(writer *csv.Writer, cells []string) error {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		row[i] = csvCell(cell)
+	}
+	return writer.Write(row)
+}
+
+// ExportTicketAnswers - Unduh data peserta event sebagai CSV
+func ExportTicketAnswers(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var questions []models.RegistrationQuestion
+	if err := config.DB.Where("event_id = ?", event.EventID).
+		Order("position ASC, created_at ASC").
+		Find(&questions).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch questions",
+		})
+	}
+
+	var tickets []models.Ticket
+	if err := config.DB.Preload("Owner").
+		Where("event_id = ? AND status IN ?", event.EventID, []string{"pending", "active", "used"}).
+		Order("created_at ASC").
+		Find(&tickets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch tickets",
+		})
+	}
+
+	ticketIDs := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		ticketIDs = append(ticketIDs, ticket.TicketID)
+	}
+	answers, err := ticketAnswerMap(config.DB, ticketIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch answers",
+		})
+	}
+
+	categoryNames := make(map[string]string)
+	for _, category := range event.TicketCategories {
+		categoryNames[category.TicketCategoryID] = category.Name
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"Ticket_ID", "Kategori_Tiket", "Status", "Nama_Pembeli", "Email_Pembeli"}
+	for _, question := range questions {
+		header = append(header, question.Label)
+	}
+	writeCSVRow(writer, header)
+
+	for _, ticket := range tickets {
+		holderEmail := ticket.HolderEmail
+		if holderEmail == "" {
+			holderEmail = ticket.Owner.Email
+		}
+		row := []string{
+			ticket.TicketID,
+			categoryNames[ticket.TicketCategoryID],
+			ticket.Status,
+			ticketHolderName(ticket),
+			holderEmail,
+		}
+		for _, question := range questions {
+			// pertanyaan kategori lain dikosongkan
+			if question.TicketCategoryID != "" && question.TicketCategoryID != ticket.TicketCategoryID {
+				row = append(row, "")
+				continue
+			}
+			value := answers[ticket.TicketID][question.QuestionID]
+			if question.FieldType == models.FieldMultiSelect && value != "" {
+				var values []string
+				if json.Unmarshal([]byte(value), &values) == nil {
+					value = strings.Join(values, "; ")
+				}
+			}
+			row = append(row, value)
+		}
+		writeCSVRow(writer, row)
+	}
+	writer.Flush()
+
+	c.Set("Content-Type", "text/csv; charset=utf-8")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=peserta_%s_%s.csv", event.Name, time.Now().Format("2006-01-02")))
+	return c.Send(buf.Bytes())
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+)
+
+func TestNormalizeAnswer(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	floatPtr := func(n float64) *float64 { return &n }
+	question := func(fieldType string) models.RegistrationQuestion {
+		return models.RegistrationQuestion{Label: "Field", FieldType: fieldType}
+	}
+
+	tests := []struct {
+		name     string
+		question models.RegistrationQuestion
+		raw      string
+		want     string
+		wantErr  bool
+	}{
+		{name: "text is trimmed", question: question(models.FieldText), raw: `"  Budi  "`, want: "Budi"},
+		{name: "missing answer clears", question: question(models.FieldText), raw: ``, want: ""},
+		{name: "null clears", question: question(models.FieldText), raw: `null`, want: ""},
+		{name: "blank clears", question: question(models.FieldText), raw: `"   "`, want: ""},
+		{name: "json number as text", question: question(models.FieldText), raw: `42`, want: "42"},
+		{name: "object rejected", question: question(models.FieldText), raw: `{"a":1}`, wantErr: true},
+		{name: "malformed json", question: question(models.FieldText), raw: `"unterminated`, wantErr: true},
+		{
+			name:     "max length counts runes",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldText, MaxLength: intPtr(3)},
+			raw:      `"日本語"`,
+			want:     "日本語",
+		},
+		{
+			name:     "too long",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldText, MaxLength: intPtr(3)},
+			raw:      `"日本語x"`,
+			wantErr:  true,
+		},
+		{
+			name:     "too short",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldTextarea, MinLength: intPtr(5)},
+			raw:      `"abc"`,
+			wantErr:  true,
+		},
+		{
+			name:     "pattern mismatch",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldText, Pattern: `^[A-Z]{3}$`},
+			raw:      `"abc"`,
+			wantErr:  true,
+		},
+		{name: "number from string", question: question(models.FieldNumber), raw: `"12.5"`, want: "12.5"},
+		{name: "not a number", question: question(models.FieldNumber), raw: `"abc"`, wantErr: true},
+		{
+			name:     "number below min",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldNumber, MinValue: floatPtr(10)},
+			raw:      `5`,
+			wantErr:  true,
+		},
+		{
+			name:     "number above max",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldNumber, MaxValue: floatPtr(10)},
+			raw:      `11`,
+			wantErr:  true,
+		},
+		{name: "email", question: question(models.FieldEmail), raw: `"budi@example.com"`, want: "budi@example.com"},
+		{name: "invalid email", question: question(models.FieldEmail), raw: `"budi@"`, wantErr: true},
+		{name: "phone", question: question(models.FieldPhone), raw: `"+62 812-3456-7890"`, want: "+62 812-3456-7890"},
+		{name: "invalid phone", question: question(models.FieldPhone), raw: `"12ab"`, wantErr: true},
+		{name: "leap day", question: question(models.FieldDate), raw: `"2024-02-29"`, want: "2024-02-29"},
+		{name: "invalid date", question: question(models.FieldDate), raw: `"2023-02-29"`, wantErr: true},
+		{
+			name:     "select option",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldSelect, Options: []string{"S", "M"}},
+			raw:      `"M"`,
+			want:     "M",
+		},
+		{
+			name:     "unknown select option",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldSelect, Options: []string{"S", "M"}},
+			raw:      `"XL"`,
+			wantErr:  true,
+		},
+		{
+			name:     "multiselect is stored as json",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldMultiSelect, Options: []string{"A", "B"}},
+			raw:      `["A","B"]`,
+			want:     `["A","B"]`,
+		},
+		{
+			name:     "empty multiselect clears",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldMultiSelect, Options: []string{"A"}},
+			raw:      `[]`,
+			want:     "",
+		},
+		{
+			name:     "unknown multiselect option",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldMultiSelect, Options: []string{"A"}},
+			raw:      `["A","X"]`,
+			wantErr:  true,
+		},
+		{
+			name:     "multiselect needs a list",
+			question: models.RegistrationQuestion{Label: "Field", FieldType: models.FieldMultiSelect, Options: []string{"A"}},
+			raw:      `"A"`,
+			wantErr:  true,
+		},
+		{name: "checked checkbox", question: question(models.FieldCheckbox), raw: `true`, want: "true"},
+		{name: "checkbox from string", question: question(models.FieldCheckbox), raw: `"1"`, want: "true"},
+		{name: "unchecked checkbox clears", question: question(models.FieldCheckbox), raw: `false`, want: ""},
+		{name: "invalid checkbox", question: question(models.FieldCheckbox), raw: `"yes"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, msg := normalizeAnswer(tt.question, json.RawMessage(tt.raw))
+			if tt.wantErr {
+				if msg == "" {
+					t.Fatalf("normalizeAnswer(%s) = %q, want an error", tt.raw, got)
+				}
+				return
+			}
+			if msg != "" {
+				t.Fatalf("normalizeAnswer(%s) error: %s", tt.raw, msg)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeAnswer(%s) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Budi", want: "Budi"},
+		{value: "a=b", want: "a=b"},
+		{value: "=HYPERLINK(\"http://x\")", want: "'=HYPERLINK(\"http://x\")"},
+		{value: "+62 812", want: "'+62 812"},
+		{value: "-1", want: "'-1"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "\t=1", want: "'\t=1"},
+		{value: "\r=1", want: "'\r=1"},
+	}
+
+	for _, tt := range tests {
+		if got := csvCell(tt.value); got != tt.want {
+			t.Errorf("csvCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,868 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// Lama kursi ditahan untuk pembeli sebelum checkout
+	seatHoldDuration = 10 * time.Minute
+
+	maxSeatsPerRow     = 500
+	maxSeatsPerMap     = 20000
+	maxSeatsPerRequest = 20
+)
+
+var ErrSeatUnavailable = errors.New("seat hold expired or seat is no longer available")
+
+type SeatMapRequest struct {
+	Sections []SeatSectionRequest `json:"sections"`
+}
+
+type SeatSectionRequest struct {
+	Name             string           `json:"name"`
+	TicketCategoryID string           `json:"ticket_category_id"`
+	Position         int              `json:"position"`
+	Rows             []SeatRowRequest `json:"rows"`
+}
+
+// SeatRowRequest - satu baris kursi bernomor StartNumber.. (default 1);
+// nomor di Skip dilewati (lorong, kursi yang tidak ada)
+type SeatRowRequest struct {
+	Row         string `json:"row"`
+	Seats       int    `json:"seats"`
+	StartNumber int    `json:"start_number"`
+	Skip        []int  `json:"skip"`
+}
+
+type seatSelectionRequest struct {
+	SeatIDs []string `json:"seat_ids"`
+}
+
+// seatFree - kursi bisa diambil: tersedia, atau hold pembeli yang sudah lewat
+func seatFree(query *gorm.DB, now time.Time) *gorm.DB {
+	return query.Where("(status = ? OR (status = ? AND ticket_id = '' AND hold_expires_at < ?))",
+		models.SeatAvailable, models.SeatHeld, now)
+}
+
+// seatStatus - status kursi untuk ditampilkan; hold yang lewat dianggap tersedia
+func seatStatus(seat models.EventSeat, now time.Time) string {
+	if seat.Status == models.SeatHeld && seat.TicketID == "" && seat.HoldExpiresAt != nil && seat.HoldExpiresAt.Before(now) {
+		return models.SeatAvailable
+	}
+	return seat.Status
+}
+
+func uniqueStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" && !containsString(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func loadSeatSections(eventID string) ([]models.SeatSection, error) {
+	var sections []models.SeatSection
+	err := config.DB.Preload("Seats", func(db *gorm.DB) *gorm.DB {
+		return db.Order("`row` ASC, number ASC")
+	}).Where("event_id = ?", eventID).
+		Order("position ASC, name ASC").
+		Find(&sections).Error
+	return sections, err
+}
+
+// claimSeat memasang kursi yang ditahan user ke tiket saat checkout. Tiket
+// gratis langsung sold; tiket berbayar tetap held sampai notifikasi Midtrans.
+// Mengembalikan label kursi untuk disimpan di tiket.
+func claimSeat(tx *gorm.DB, seatID, userID, ticketID string, sold bool) (string, error) {
+	status := models.SeatHeld
+	if sold {
+		status = models.SeatSold
+	}
+
+	result := tx.Model(&models.EventSeat{}).
+		Where("seat_id = ? AND held_by = ? AND status = ? AND ticket_id = '' AND hold_expires_at > ?",
+			seatID, userID, models.SeatHeld, time.Now()).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"ticket_id":       ticketID,
+			"hold_expires_at": nil,
+		})
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", ErrSeatUnavailable
+	}
+
+	return seatLabel(tx, seatID)
+}
+
+// seatLabel - label lengkap kursi untuk tiket, mis. "Tribun A A-12"
+func seatLabel(tx *gorm.DB, seatID string) (string, error) {
+	var seat models.EventSeat
+	if err := tx.First(&seat, "seat_id = ?", seatID).Error; err != nil {
+		return "", err
+	}
+	var section models.SeatSection
+	if err := tx.First(&section, "section_id = ?", seat.SectionID).Error; err != nil {
+		return "", err
+	}
+	return section.Name + " " + seat.Label, nil
+}
+
+// markTransactionSeatsSold - kursi tiket transaksi yang lunas menjadi sold
+func markTransactionSeatsSold(tx *gorm.DB, transactionID string) error {
+	return tx.Model(&models.EventSeat{}).
+		Where("ticket_id IN (?) AND status = ?",
+			tx.Model(&models.Ticket{}).Select("ticket_id").Where("transaction_id = ?", transactionID),
+			models.SeatHeld).
+		Update("status", models.SeatSold).Error
+}
+
+// releaseTransactionSeats - kursi dari tiket yang pembayarannya gagal dilepas
+func releaseTransactionSeats(tx *gorm.DB, transactionID string) error {
+	return tx.Model(&models.EventSeat{}).
+		Where("ticket_id IN (?) AND status = ?",
+			tx.Model(&models.Ticket{}).Select("ticket_id").Where("transaction_id = ? AND status = ?", transactionID, "payment_failed"),
+			models.SeatHeld).
+		Updates(map[string]interface{}{
+			"status":          models.SeatAvailable,
+			"held_by":         "",
+			"ticket_id":       "",
+			"hold_expires_at": nil,
+		}).Error
+}
+
+// releaseHeldSeats melepas hold user (yang belum jadi tiket) atas kursi tertentu
+func releaseHeldSeats(tx *gorm.DB, userID string, seatIDs []string) error {
+	if len(seatIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.EventSeat{}).
+		Where("seat_id IN ? AND held_by = ? AND status = ? AND ticket_id = ''", seatIDs, userID, models.SeatHeld).
+		Updates(map[string]interface{}{
+			"status":          models.SeatAvailable,
+			"held_by":         "",
+			"hold_expires_at": nil,
+		}).Error
+}
+
+// syncSeatCart menyimpan kursi pilihan user sebagai item keranjang per
+// kategori; quantity mengikuti jumlah kursi dan item kosong dihapus
+func syncSeatCart(tx *gorm.DB, userID string, category models.TicketCategory, seatIDs []string) (*models.Cart, error) {
+	var cart models.Cart
+	err := tx.Where("owner_id = ? AND ticket_category_id = ?", userID, category.TicketCategoryID).First(&cart).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	exists := err == nil
+
+	if len(seatIDs) == 0 {
+		if exists {
+			return nil, tx.Delete(&cart).Error
+		}
+		return nil, nil
+	}
+
+	if !exists {
+		cart = models.Cart{
+			CartID:           utils.GenerateCartID(),
+			TicketCategoryID: category.TicketCategoryID,
+			OwnerID:          userID,
+			CreatedAt:        time.Now(),
+		}
+	}
+	cart.SeatIDs = seatIDs
+	cart.Quantity = uint(len(seatIDs))
+	cart.PriceTotal = float64(cart.Quantity) * category.Price
+	cart.UpdatedAt = time.Now()
+
+	if err := tx.Save(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// GetSeatMap - Denah kursi publik dengan status ketersediaan
+func GetSeatMap(c *fiber.Ctx) error {
+	var event models.Event
+	if err := publicEvents(config.DB).Where("event_id = ?", c.Params("id")).First(&event).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+
+	sections, err := loadSeatSections(event.EventID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch seat map",
+		})
+	}
+
+	now := time.Now()
+	summary := map[string]int{}
+	result := make([]fiber.Map, 0, len(sections))
+	for _, section := range sections {
+		seats := make([]fiber.Map, 0, len(section.Seats))
+		for _, seat := range section.Seats {
+			status := seatStatus(seat, now)
+			summary[status]++
+			seats = append(seats, fiber.Map{
+				"seat_id": seat.SeatID,
+				"row":     seat.Row,
+				"number":  seat.Number,
+				"label":   seat.Label,
+				"status":  status,
+			})
+		}
+		result = append(result, fiber.Map{
+			"section_id":         section.SectionID,
+			"name":               section.Name,
+			"ticket_category_id": section.TicketCategoryID,
+			"seats":              seats,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Seat map retrieved successfully",
+		"event_id": event.EventID,
+		"sections": result,
+		"summary":  summary,
+	})
+}
+
+// GetManagedSeatMap - Denah kursi lengkap dengan hold dan tiket (owner/admin)
+func GetManagedSeatMap(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	sections, err := loadSeatSections(event.EventID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch seat map",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Seat map retrieved successfully",
+		"sections": sections,
+	})
+}
+
+// SaveSeatMap - Buat/ganti denah kursi event. Kategori yang dipetakan menjadi
+// kategori bernomor kursi dengan quota sama dengan jumlah kursinya. Denah
+// tidak bisa diganti setelah ada kursi yang terjual atau sedang dibayar.
+func SaveSeatMap(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req SeatMapRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	categories := make(map[string]models.TicketCategory)
+	for _, category := range event.TicketCategories {
+		categories[category.TicketCategoryID] = category
+	}
+
+	type rowKey struct{ section, row string }
+	mapped := make(map[string]bool)
+	seenRows := make(map[rowKey]bool)
+	total := 0
+	for _, section := range req.Sections {
+		if strings.TrimSpace(section.Name) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Section name is required",
+			})
+		}
+		category, ok := categories[section.TicketCategoryID]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Ticket category does not belong to this event: " + section.TicketCategoryID,
+			})
+		}
+		if category.Sold+category.Imported > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Ticket category already has tickets and cannot be seated: " + category.Name,
+			})
+		}
+
+		for _, row := range section.Rows {
+			key := rowKey{section.Name, strings.TrimSpace(row.Row)}
+			if key.row == "" || row.Seats < 1 || row.Seats > maxSeatsPerRow {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": fmt.Sprintf("Each row needs a name and 1-%d seats", maxSeatsPerRow),
+				})
+			}
+			if seenRows[key] {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Duplicate row " + key.row + " in section " + section.Name,
+				})
+			}
+			seenRows[key] = true
+			mapped[section.TicketCategoryID] = true
+			total += row.Seats
+		}
+	}
+	if total > maxSeatsPerMap {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("A seat map can have at most %d seats", maxSeatsPerMap),
+		})
+	}
+
+	var locked int64
+	if err := config.DB.Model(&models.EventSeat{}).
+		Where("event_id = ? AND (status = ? OR ticket_id <> '')", event.EventID, models.SeatSold).
+		Count(&locked).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check seats",
+		})
+	}
+	if locked > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Seat map cannot be replaced after seats have been sold",
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	// keranjang kategori yang sebelumnya atau sekarang bernomor kursi dibuang
+	affected := make([]string, 0)
+	for id, category := range categories {
+		if category.Seated || mapped[id] {
+			affected = append(affected, id)
+		}
+	}
+	if len(affected) > 0 {
+		if err := tx.Where("ticket_category_id IN ?", affected).Delete(&models.Cart{}).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to clear carts",
+			})
+		}
+	}
+
+	if err := tx.Where("event_id = ?", event.EventID).Delete(&models.EventSeat{}).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete seats",
+		})
+	}
+	if err := tx.Where("event_id = ?", event.EventID).Delete(&models.SeatSection{}).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete sections",
+		})
+	}
+	if err := tx.Model(&models.TicketCategory{}).Where("event_id = ?", event.EventID).Update("seated", false).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update ticket categories",
+		})
+	}
+
+	for _, sectionReq := range req.Sections {
+		section := models.SeatSection{
+			SectionID:        utils.GenerateSeatSectionID(),
+			EventID:          event.EventID,
+			TicketCategoryID: sectionReq.TicketCategoryID,
+			Name:             strings.TrimSpace(sectionReq.Name),
+			Position:         sectionReq.Position,
+		}
+		if err := tx.Create(&section).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create section",
+			})
+		}
+
+		var seats []models.EventSeat
+		for _, row := range sectionReq.Rows {
+			rowName := strings.TrimSpace(row.Row)
+			start := row.StartNumber
+			if start < 1 {
+				start = 1
+			}
+			for number := start; number < start+row.Seats; number++ {
+				skipped := false
+				for _, skip := range row.Skip {
+					if skip == number {
+						skipped = true
+						break
+					}
+				}
+				if skipped {
+					continue
+				}
+				seats = append(seats, models.EventSeat{
+					SeatID:           utils.GenerateSeatID(),
+					EventID:          event.EventID,
+					SectionID:        section.SectionID,
+					TicketCategoryID: section.TicketCategoryID,
+					Row:              rowName,
+					Number:           number,
+					Label:            fmt.Sprintf("%s-%d", rowName, number),
+					Status:           models.SeatAvailable,
+				})
+			}
+		}
+		if len(seats) > 0 {
+			if err := tx.CreateInBatches(&seats, 500).Error; err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to create seats",
+				})
+			}
+		}
+	}
+
+	for categoryID := range mapped {
+		var count int64
+		if err := tx.Model(&models.EventSeat{}).Where("ticket_category_id = ?", categoryID).Count(&count).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to count seats",
+			})
+		}
+		if err := tx.Model(&models.TicketCategory{}).
+			Where("ticket_category_id = ?", categoryID).
+			Updates(map[string]interface{}{"seated": true, "quota": count}).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update ticket categories",
+			})
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	sections, err := loadSeatSections(event.EventID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load seat map",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Seat map saved successfully",
+		"sections": sections,
+	})
+}
+
+// HoldSeats - Pembeli memilih kursi. Kursi ditahan selama seatHoldDuration dan
+// masuk keranjang sebagai item kategori tiketnya.
+func HoldSeats(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	if user.Role == "admin" || user.Role == "organizer" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Only buyers can hold seats",
+		})
+	}
+
+	var req seatSelectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+	seatIDs := uniqueStrings(req.SeatIDs)
+	if len(seatIDs) == 0 || len(seatIDs) > maxSeatsPerRequest {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Select between 1 and %d seats", maxSeatsPerRequest),
+		})
+	}
+
+	eventID := c.Params("id")
+	if problem := eventSaleProblem(eventID); problem != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": problem,
+		})
+	}
+
+	now := time.Now()
+	expires := now.Add(seatHoldDuration)
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	for _, seatID := range seatIDs {
+		result := tx.Model(&models.EventSeat{}).
+			Where("seat_id = ? AND event_id = ?", seatID, eventID).
+			Where("(status = ? OR (status = ? AND ticket_id = '' AND (hold_expires_at < ? OR held_by = ?)))",
+				models.SeatAvailable, models.SeatHeld, now, user.UserID).
+			Updates(map[string]interface{}{
+				"status":          models.SeatHeld,
+				"held_by":         user.UserID,
+				"hold_expires_at": expires,
+			})
+		if result.Error != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to hold seat",
+			})
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   "Seat is no longer available",
+				"seat_id": seatID,
+			})
+		}
+	}
+
+	var seats []models.EventSeat
+	if err := tx.Where("seat_id IN ?", seatIDs).Find(&seats).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load seats",
+		})
+	}
+
+	byCategory := make(map[string][]string)
+	for _, seat := range seats {
+		byCategory[seat.TicketCategoryID] = append(byCategory[seat.TicketCategoryID], seat.SeatID)
+	}
+
+	carts := make([]models.Cart, 0, len(byCategory))
+	for categoryID, ids := range byCategory {
+		var category models.TicketCategory
+		if err := tx.First(&category, "ticket_category_id = ?", categoryID).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to load ticket category",
+			})
+		}
+		if category.Archived {
+			tx.Rollback()
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Ticket category is no longer on sale: " + category.Name,
+			})
+		}
+
+		var existing models.Cart
+		if err := tx.Where("owner_id = ? AND ticket_category_id = ?", user.UserID, categoryID).First(&existing).Error; err == nil {
+			ids = uniqueStrings(append(existing.SeatIDs, ids...))
+		}
+
+		// hold kursi lain di keranjang yang sama ikut diperpanjang
+		if err := tx.Model(&models.EventSeat{}).
+			Where("seat_id IN ? AND held_by = ? AND status = ? AND ticket_id = ''", ids, user.UserID, models.SeatHeld).
+			Update("hold_expires_at", expires).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to extend seat hold",
+			})
+		}
+
+		cart, err := syncSeatCart(tx, user.UserID, category, ids)
+		if err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update cart",
+			})
+		}
+		carts = append(carts, *cart)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":         "Seats held successfully",
+		"seat_ids":        seatIDs,
+		"hold_expires_at": expires,
+		"carts":           carts,
+	})
+}
+
+// ReleaseSeats - Pembeli melepas kursi yang ditahan
+func ReleaseSeats(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	var req seatSelectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+	seatIDs := uniqueStrings(req.SeatIDs)
+	if len(seatIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "seat_ids is required",
+		})
+	}
+
+	var seats []models.EventSeat
+	if err := config.DB.Where("seat_id IN ? AND event_id = ?", seatIDs, c.Params("id")).Find(&seats).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load seats",
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	released := make([]string, 0, len(seats))
+	categories := make(map[string]bool)
+	for _, seat := range seats {
+		released = append(released, seat.SeatID)
+		categories[seat.TicketCategoryID] = true
+	}
+
+	if err := releaseHeldSeats(tx, user.UserID, released); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to release seats",
+		})
+	}
+
+	for categoryID := range categories {
+		var cart models.Cart
+		if err := tx.Where("owner_id = ? AND ticket_category_id = ?", user.UserID, categoryID).First(&cart).Error; err != nil {
+			continue
+		}
+		remaining := make([]string, 0, len(cart.SeatIDs))
+		for _, id := range cart.SeatIDs {
+			if !containsString(released, id) {
+				remaining = append(remaining, id)
+			}
+		}
+
+		var category models.TicketCategory
+		if err := tx.First(&category, "ticket_category_id = ?", categoryID).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to load ticket category",
+			})
+		}
+		if _, err := syncSeatCart(tx, user.UserID, category, remaining); err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update cart",
+			})
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Seats released successfully",
+		"seat_ids": released,
+	})
+}
+
+// BlockSeats - Organizer memblokir kursi (mis. untuk kru/kamera) atau membukanya lagi
+func BlockSeats(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		SeatIDs []string `json:"seat_ids"`
+		Blocked bool     `json:"blocked"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+	seatIDs := uniqueStrings(req.SeatIDs)
+	if len(seatIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "seat_ids is required",
+		})
+	}
+
+	query := config.DB.Model(&models.EventSeat{}).Where("seat_id IN ? AND event_id = ?", seatIDs, event.EventID)
+	var result *gorm.DB
+	if req.Blocked {
+		result = seatFree(query, time.Now()).Updates(map[string]interface{}{
+			"status":          models.SeatBlocked,
+			"held_by":         "",
+			"hold_expires_at": nil,
+		})
+	} else {
+		result = query.Where("status = ?", models.SeatBlocked).Update("status", models.SeatAvailable)
+	}
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update seats",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Seats updated successfully",
+		"updated": result.RowsAffected,
+		"skipped": int64(len(seatIDs)) - result.RowsAffected,
+	})
+}
+
+// MoveAttendee - Organizer memindahkan pemegang tiket ke kursi lain di
+// kategori yang sama. Kursi tujuan boleh tersedia atau diblokir.
+func MoveAttendee(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		TicketID string `json:"ticket_id"`
+		SeatID   string `json:"seat_id"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	var ticket models.Ticket
+	if err := config.DB.Where("ticket_id = ? AND event_id = ?", req.TicketID, event.EventID).First(&ticket).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ticket not found",
+		})
+	}
+	if ticket.Status != "active" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Only active tickets can be moved",
+		})
+	}
+
+	var seat models.EventSeat
+	if err := config.DB.Where("seat_id = ? AND event_id = ?", req.SeatID, event.EventID).First(&seat).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Seat not found",
+		})
+	}
+	if seat.TicketCategoryID != ticket.TicketCategoryID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Seat belongs to another ticket category",
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	query := tx.Model(&models.EventSeat{}).Where("seat_id = ?", seat.SeatID)
+	result := query.Where("(status = ? OR status = ? OR (status = ? AND ticket_id = '' AND hold_expires_at < ?))",
+		models.SeatAvailable, models.SeatBlocked, models.SeatHeld, time.Now()).
+		Updates(map[string]interface{}{
+			"status":          models.SeatSold,
+			"held_by":         ticket.OwnerID,
+			"ticket_id":       ticket.TicketID,
+			"hold_expires_at": nil,
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to assign seat",
+		})
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Seat is not available",
+		})
+	}
+
+	if ticket.SeatID != "" {
+		if err := tx.Model(&models.EventSeat{}).
+			Where("seat_id = ? AND ticket_id = ?", ticket.SeatID, ticket.TicketID).
+			Updates(map[string]interface{}{
+				"status":    models.SeatAvailable,
+				"held_by":   "",
+				"ticket_id": "",
+			}).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to release previous seat",
+			})
+		}
+	}
+
+	label, err := seatLabel(tx, seat.SeatID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load seat",
+		})
+	}
+
+	if err := tx.Model(&models.Ticket{}).Where("ticket_id = ?", ticket.TicketID).Updates(map[string]interface{}{
+		"seat_id":    seat.SeatID,
+		"seat_label": label,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update ticket",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	previous := ticket.SeatLabel
+	ticket.SeatID = seat.SeatID
+	ticket.SeatLabel = label
+
+	go notifyTicketWallets(ticket.TicketID)
+
+	return c.JSON(fiber.Map{
+		"message":       "Attendee moved successfully",
+		"ticket":        ticket,
+		"previous_seat": previous,
+	})
+}
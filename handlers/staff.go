@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type EventStaffRequest struct {
+	Username          string   `json:"username"`
+	Name              string   `json:"name"`
+	Email             string   `json:"email"`
+	Password          string   `json:"password"`
+	TicketCategoryIDs []string `json:"ticket_category_ids"`
+	ValidFrom         string   `json:"valid_from"`
+	ValidUntil        string   `json:"valid_until"`
+}
+
+// loadManagedEvent - event yang boleh dikelola user (pemilik atau admin)
+func loadManagedEvent(c *fiber.Ctx, eventID string) (models.Event, error) {
+	user := c.Locals("user").(models.User)
+
+	var event models.Event
+	if err := config.DB.Preload("TicketCategories").First(&event, "event_id = ?", eventID).Error; err != nil {
+		return event, fiber.NewError(fiber.StatusNotFound, "Event not found")
+	}
+
+	if event.OwnerID != user.UserID && user.Role != "admin" {
+		return event, fiber.NewError(fiber.StatusForbidden, "Not authorized to manage this event")
+	}
+
+	return event, nil
+}
+
+// parseStaffScope memvalidasi kategori dan jam jaga staff terhadap event
+func parseStaffScope(event models.Event, req EventStaffRequest, assignment *models.EventStaff) string {
+	validCategories := make(map[string]bool)
+	for _, category := range event.TicketCategories {
+		validCategories[category.TicketCategoryID] = true
+	}
+	for _, id := range req.TicketCategoryIDs {
+		if !validCategories[id] {
+			return "Ticket category " + id + " does not belong to this event"
+		}
+	}
+	assignment.TicketCategoryIDs = req.TicketCategoryIDs
+
+	assignment.ValidFrom = nil
+	if req.ValidFrom != "" {
+		validFrom, err := time.Parse(time.RFC3339, req.ValidFrom)
+		if err != nil {
+			return "Invalid valid_from format. Use RFC3339 format"
+		}
+		assignment.ValidFrom = &validFrom
+	}
+
+	assignment.ValidUntil = nil
+	if req.ValidUntil != "" {
+		validUntil, err := time.Parse(time.RFC3339, req.ValidUntil)
+		if err != nil {
+			return "Invalid valid_until format. Use RFC3339 format"
+		}
+		assignment.ValidUntil = &validUntil
+	}
+
+	if assignment.ValidFrom != nil && assignment.ValidUntil != nil && assignment.ValidUntil.Before(*assignment.ValidFrom) {
+		return "valid_until must be after valid_from"
+	}
+
+	return ""
+}
+
+// AddEventStaff - Undang akun gate staff ke event. Akun staff dibuat jika belum ada.
+func AddEventStaff(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var req EventStaffRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	if req.Username == "" && req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Username or email is required",
+		})
+	}
+
+	assignment := models.EventStaff{
+		EventStaffID: utils.GenerateEventStaffID(),
+		EventID:      event.EventID,
+		InvitedBy:    user.UserID,
+	}
+	if msg := parseStaffScope(event, req, &assignment); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	// akun dicari lewat satu identitas saja; username diutamakan
+	lookup := config.DB.Where("username = ?", req.Username)
+	if req.Username == "" {
+		lookup = config.DB.Where("email = ?", req.Email)
+	}
+
+	var staff models.User
+	err = lookup.First(&staff).Error
+	if err == nil {
+		// akun yang sudah ada harus akun staff milik organizer event ini
+		if staff.Role != "staff" || staff.CreatedBy != event.OwnerID {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Username or email already belongs to another account",
+			})
+		}
+		if req.Password != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Password cannot be set for an existing staff account",
+			})
+		}
+	} else {
+		if req.Username == "" || req.Email == "" || req.Password == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Username, email and password are required to create a staff account",
+			})
+		}
+
+		// username atau email lain mungkin sudah dipakai akun lain
+		var taken int64
+		config.DB.Unscoped().Model(&models.User{}).Where("username = ? OR email = ?", req.Username, req.Email).Count(&taken)
+		if taken > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Username or email already belongs to another account",
+			})
+		}
+
+		hashedPassword, err := utils.HashPassword(req.Password)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to hash password",
+			})
+		}
+
+		staff = models.User{
+			UserID:         utils.GenerateUserID("staff"),
+			Username:       req.Username,
+			Name:           req.Name,
+			Email:          req.Email,
+			Password:       hashedPassword,
+			Role:           "staff",
+			RegisterStatus: "approved",
+			CreatedBy:      event.OwnerID,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+
+		if err := config.DB.Create(&staff).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create staff account",
+			})
+		}
+	}
+
+	var existing models.EventStaff
+	if err := config.DB.Where("event_id = ? AND user_id = ?", event.EventID, staff.UserID).First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Staff is already assigned to this event",
+		})
+	}
+
+	assignment.UserID = staff.UserID
+	if err := config.DB.Create(&assignment).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to assign staff: " + err.Error(),
+		})
+	}
+
+	assignment.User = staff
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Staff assigned successfully",
+		"staff":   assignment,
+	})
+}
+
+// GetEventStaff - Daftar gate staff sebuah event
+func GetEventStaff(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var staff []models.EventStaff
+	if err := config.DB.Preload("User").
+		Where("event_id = ?", event.EventID).
+		Order("created_at ASC").
+		Find(&staff).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch staff",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Staff retrieved successfully",
+		"staff":   staff,
+	})
+}
+
+// UpdateEventStaff - Ubah kategori dan jam jaga staff
+func UpdateEventStaff(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var assignment models.EventStaff
+	if err := config.DB.Where("event_staff_id = ? AND event_id = ?", c.Params("staff_id"), event.EventID).First(&assignment).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Staff assignment not found",
+		})
+	}
+
+	var req EventStaffRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	if msg := parseStaffScope(event, req, &assignment); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	if err := config.DB.Save(&assignment).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update staff assignment",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Staff assignment updated successfully",
+		"staff":   assignment,
+	})
+}
+
+// RemoveEventStaff - Cabut akses check-in staff dari event
+func RemoveEventStaff(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	result := config.DB.Where("event_staff_id = ? AND event_id = ?", c.Params("staff_id"), event.EventID).Delete(&models.EventStaff{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to remove staff",
+		})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Staff assignment not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Staff removed successfully",
+	})
+}
+
+// GetMyStaffAssignments - Event yang boleh discan oleh staff yang login
+func GetMyStaffAssignments(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	var assignments []models.EventStaff
+	if err := config.DB.Preload("Event").
+		Where("user_id = ?", user.UserID).
+		Find(&assignments).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch assignments",
+		})
+	}
+
+	// staff tidak boleh melihat data penjualan event
+	type assignmentResponse struct {
+		EventStaffID      string     `json:"event_staff_id"`
+		EventID           string     `json:"event_id"`
+		EventName         string     `json:"event_name"`
+		Venue             string     `json:"venue"`
+		DateStart         time.Time  `json:"date_start"`
+		DateEnd           time.Time  `json:"date_end"`
+		TicketCategoryIDs []string   `json:"ticket_category_ids"`
+		ValidFrom         *time.Time `json:"valid_from"`
+		ValidUntil        *time.Time `json:"valid_until"`
+	}
+
+	response := make([]assignmentResponse, 0, len(assignments))
+	for _, assignment := range assignments {
+		response = append(response, assignmentResponse{
+			EventStaffID:      assignment.EventStaffID,
+			EventID:           assignment.EventID,
+			EventName:         assignment.Event.Name,
+			Venue:             assignment.Event.Venue,
+			DateStart:         assignment.Event.DateStart,
+			DateEnd:           assignment.Event.DateEnd,
+			TicketCategoryIDs: assignment.TicketCategoryIDs,
+			ValidFrom:         assignment.ValidFrom,
+			ValidUntil:        assignment.ValidUntil,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":     "Assignments retrieved successfully",
+		"assignments": response,
+	})
+}
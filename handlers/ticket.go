@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"errors"
 	"time"
 
 	"log"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Tsaniii18/Ticketing-Backend/config"
 	"github.com/Tsaniii18/Ticketing-Backend/models"
@@ -14,7 +17,7 @@ import (
 )
 
 type ticketResponse struct {
-	Code           string                  `json:"code"`
+	Code           string                  `json:"code,omitempty"` // hanya dari GetTicketCode
 	TicketID       string                  `json:"ticket_id"`
 	TicketCategory *ticketCategoryResponse `json:"ticket_category"`
 	Event          *eventResponse          `json:"event"`
@@ -51,21 +54,19 @@ func GetTickets(c *fiber.Ctx) error {
 	// Query parameter untuk filter status (optional)
 	statusFilter := c.Query("status", "") // "" = semua, "active", "used", "expired", "cancelled"
 
-	var tickets []models.Ticket
-	query := config.DB.Where("owner_id = ?", user.UserID)
+	query := config.DB.Model(&models.Ticket{}).Where("owner_id = ?", user.UserID)
 
 	// Jika ada filter status spesifik
 	if statusFilter != "" && statusFilter != "all" {
 		query = query.Where("status = ?", statusFilter)
 	}
 
-	if err := query.Order("created_at DESC").Find(&tickets).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch tickets",
-		})
+	tickets, pagination, err := paginate(c, query, ticketListSpec, nil)
+	if err != nil {
+		return listError(c, err, "Failed to fetch tickets")
 	}
 
-	var ticketResponses []ticketResponse
+	ticketResponses := make([]ticketResponse, 0, len(tickets))
 
 	for _, ticket := range tickets {
 		var ticketCategory models.TicketCategory
@@ -110,14 +111,13 @@ func GetTickets(c *fiber.Ctx) error {
 			Image:     event.Image,
 		}
 
-		// Determine used_at (jika status used, gunakan UpdatedAt sebagai waktu check-in)
+		// Determine used_at (tiket lama belum punya used_at, fallback ke UpdatedAt)
 		var usedAt *time.Time
 		if ticket.Status == "used" {
-			usedAt = &ticket.UpdatedAt
+			usedAt = ticketUsedAt(ticket)
 		}
 
 		ticketResponse := ticketResponse{
-			Code:           ticket.Code,
 			TicketID:       ticket.TicketID,
 			TicketCategory: &ticketCategoryResponse,
 			Event:          &eventResponse,
@@ -130,37 +130,101 @@ func GetTickets(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(fiber.Map{
-		"data":    ticketResponses,
-		"message": "Tickets fetched successfully",
-		"total":   len(ticketResponses),
+		"data":       ticketResponses,
+		"message":    "Tickets fetched successfully",
+		"pagination": pagination,
 	})
 }
 
 func CheckInTicket(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
 	eventID := c.Params("event_id")
 	codeEvent := c.Params("id")
 
-	var ticket models.Ticket
+	// device & gate opsional, dari body atau header scanner
+	var req struct {
+		DeviceID string `json:"device_id"`
+		Gate     string `json:"gate"`
+	}
+	c.BodyParser(&req)
+	if req.DeviceID == "" {
+		req.DeviceID = c.Get("X-Device-ID")
+	}
+	if req.Gate == "" {
+		req.Gate = c.Get("X-Gate")
+	}
+
+	entry := models.CheckInLog{
+		EventID:   eventID,
+		ScannerID: user.UserID,
+		DeviceID:  req.DeviceID,
+		Gate:      req.Gate,
+		Code:      codeEvent,
+		Source:    "online",
+		ScannedAt: time.Now(),
+	}
+
+	status, body := performCheckIn(user, eventID, codeEvent, &entry)
+	recordCheckIn(entry)
+
+	return c.Status(status).JSON(body)
+}
+
+// performCheckIn menjalankan validasi dan check-in satu scan, lalu mengisi
+// outcome di entry untuk audit trail.
+func performCheckIn(user models.User, eventID string, codeEvent string, entry *models.CheckInLog) (int, fiber.Map) {
+	now := entry.ScannedAt
+
+	// Fetch event data early for validation
+	var event models.Event
+	if err := config.DB.First(&event, "event_id = ?", eventID).Error; err != nil {
+		entry.Outcome, entry.Message = models.CheckInInvalid, "event not found"
+		return fiber.StatusNotFound, fiber.Map{
+			"error": "Event not found",
+		}
+	}
+
+	scope, ok := loadScanScope(user, event)
+	if !ok {
+		entry.Outcome, entry.Message = models.CheckInUnauthorized, "not assigned to event"
+		return fiber.StatusForbidden, fiber.Map{
+			"error": "Not authorized to check in tickets for this event",
+		}
+	}
+
 	tx := config.DB.Begin()
 	if err := tx.Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return fiber.StatusInternalServerError, fiber.Map{
 			"error": "Failed to start transaction",
-		})
+		}
 	}
-	if err := tx.First(&ticket, "code = ? AND event_id = ?", codeEvent, eventID).Error; err != nil {
+	ticket, err := resolveTicketCode(tx, eventID, codeEvent)
+	entry.TicketID = ticket.TicketID
+	entry.TicketCategoryID = ticket.TicketCategoryID
+	if err == errTicketCodeExpired {
 		tx.Rollback()
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		entry.Outcome, entry.Message = models.CheckInInvalid, "code_expired"
+		return fiber.StatusNotAcceptable, fiber.Map{
+			"error":  "Ticket code has expired, ask the holder to refresh the QR code",
+			"status": "code_expired",
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		entry.Outcome, entry.Message = models.CheckInInvalid, "ticket not found"
+		return fiber.StatusNotFound, fiber.Map{
 			"error": "Ticket not found or Ticket code invalid",
-		})
+		}
 	}
 
-	// Fetch event data early for validation
-	var event models.Event
-	if err := tx.First(&event, "event_id = ?", ticket.EventID).Error; err != nil {
+	// Gate staff hanya boleh scan kategori dan jam jaga yang ditugaskan
+	if reason := scope.allows(ticket.TicketCategoryID, now); reason != "" {
 		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch event data",
-		})
+		entry.Outcome, entry.Message = models.CheckInUnauthorized, reason
+		return fiber.StatusForbidden, fiber.Map{
+			"error":  "You are not assigned to check in this ticket",
+			"status": reason,
+		}
 	}
 
 	// Fetch ticket category for response
@@ -169,14 +233,15 @@ func CheckInTicket(c *fiber.Ctx) error {
 
 	if ticket.Status == "used" {
 		tx.Rollback()
-		return c.Status(fiber.StatusNotAcceptable).JSON(fiber.Map{
+		entry.Outcome = models.CheckInAlreadyUsed
+		return fiber.StatusNotAcceptable, fiber.Map{
 			"error":   "Ticket already used",
 			"status":  "already_used",
-			"used_at": ticket.UpdatedAt,
+			"used_at": ticketUsedAt(ticket),
 			"ticket": fiber.Map{
 				"ticket_id":        ticket.TicketID,
 				"status":           ticket.Status,
-				"used_at":          ticket.UpdatedAt,
+				"used_at":          ticketUsedAt(ticket),
 				"ticket_category":  ticketCategory.Name,
 				"event_name":       event.Name,
 				"event_date_start": event.DateStart,
@@ -185,29 +250,32 @@ func CheckInTicket(c *fiber.Ctx) error {
 				"event_location":   event.Location,
 				"event_district":   event.District,
 			},
-		})
+		}
 	}
 
 	if ticket.Status == "cancelled" {
 		tx.Rollback()
-		return c.Status(fiber.StatusNotAcceptable).JSON(fiber.Map{
+		entry.Outcome, entry.Message = models.CheckInInvalid, "cancelled"
+		return fiber.StatusNotAcceptable, fiber.Map{
 			"error":  "Ticket has been cancelled",
 			"status": "cancelled",
-		})
+		}
 	}
 
 	if ticket.Status != "active" {
 		tx.Rollback()
-		return c.Status(fiber.StatusNotAcceptable).JSON(fiber.Map{
+		entry.Outcome, entry.Message = models.CheckInInvalid, "inactive"
+		return fiber.StatusNotAcceptable, fiber.Map{
 			"error":  "Ticket not active",
 			"status": "inactive",
-		})
+		}
 	}
 
 	// Check if event has not started yet
-	if event.DateStart.After(time.Now()) {
+	if checkInOpensAt(event).After(now) {
 		tx.Rollback()
-		return c.Status(fiber.StatusNotAcceptable).JSON(fiber.Map{
+		entry.Outcome = models.CheckInNotStarted
+		return fiber.StatusNotAcceptable, fiber.Map{
 			"error":  "Event has not started yet",
 			"status": "not_started",
 			"ticket": fiber.Map{
@@ -221,13 +289,14 @@ func CheckInTicket(c *fiber.Ctx) error {
 				"event_location":   event.Location,
 				"event_district":   event.District,
 			},
-		})
+		}
 	}
 
 	// Check if event has expired
-	if event.DateEnd.Before(time.Now()) {
+	if checkInClosesAt(event).Before(now) {
 		tx.Rollback()
-		return c.Status(fiber.StatusNotAcceptable).JSON(fiber.Map{
+		entry.Outcome = models.CheckInExpired
+		return fiber.StatusNotAcceptable, fiber.Map{
 			"error":  "Event has ended",
 			"status": "expired",
 			"ticket": fiber.Map{
@@ -241,89 +310,122 @@ func CheckInTicket(c *fiber.Ctx) error {
 				"event_location":   event.Location,
 				"event_district":   event.District,
 			},
-		})
+		}
 	}
 
-	ticket.Status = "used"
-	if err := tx.Save(&ticket).Error; err != nil {
+	// Tiket kategori hanya berlaku di jendela waktunya (misal tiket "Day 1")
+	if outsideCategoryWindow(ticketCategory, event, now) {
 		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to check in ticket",
-		})
+		from, until, _ := categoryWindow(ticketCategory, event)
+		message := categoryWindowMessage(ticketCategory, event, now)
+		entry.Outcome, entry.Message = models.CheckInOutsideWindow, message
+		return fiber.StatusNotAcceptable, fiber.Map{
+			"error":  message,
+			"status": models.CheckInOutsideWindow,
+			"ticket": fiber.Map{
+				"ticket_id":             ticket.TicketID,
+				"status":                models.CheckInOutsideWindow,
+				"ticket_category":       ticketCategory.Name,
+				"ticket_category_start": ticketCategory.DateTimeStart,
+				"ticket_category_end":   ticketCategory.DateTimeEnd,
+				"valid_from":            from,
+				"valid_until":           until,
+				"event_name":            event.Name,
+			},
+		}
 	}
 
-	if err := tx.Model(&models.TicketCategory{}).Where("ticket_category_id = ?", ticket.TicketCategoryID).UpdateColumn("attendant", gorm.Expr("attendant + ?", 1)).Error; err != nil {
+	entitlement, denial, err := consumeEntitlement(tx, &ticket, ticketCategory, event, now, user.UserID, entry.Gate, true)
+	if err != nil {
 		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update attendant count",
-		})
+		return fiber.StatusInternalServerError, fiber.Map{
+			"error": "Failed to check in ticket",
+		}
 	}
-
-	// Update event total attendant
-	if err := tx.Model(&models.Event{}).Where("event_id = ?", ticket.EventID).UpdateColumn("total_attendant", gorm.Expr("total_attendant + ?", 1)).Error; err != nil {
+	if denial != "" {
 		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update event attendant count",
-		})
+		outcome, message := entitlementDenial(denial)
+		entry.Outcome, entry.Message = outcome, denial
+		return fiber.StatusNotAcceptable, fiber.Map{
+			"error":       message,
+			"status":      denial,
+			"used_at":     ticketUsedAt(ticket),
+			"entitlement": entitlement,
+			"ticket": fiber.Map{
+				"ticket_id":       ticket.TicketID,
+				"status":          ticket.Status,
+				"inside_venue":    ticket.InsideVenue,
+				"ticket_category": ticketCategory.Name,
+				"entry_mode":      entitlement.Mode,
+				"event_name":      event.Name,
+			},
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return fiber.StatusInternalServerError, fiber.Map{
 			"error": "Failed to commit transaction",
-		})
+		}
 	}
 
-	return c.JSON(fiber.Map{
-		"message": "Ticket checked in successfully",
-		"status":  "success",
+	// pass wallet tiket yang sudah habis dipakai ditampilkan void
+	if ticket.Status == "used" {
+		go notifyTicketWallets(ticket.TicketID)
+	}
+
+	entry.Outcome, entry.Message = models.CheckInSuccess, entitlement.Consumed
+	return fiber.StatusOK, fiber.Map{
+		"message":     "Ticket checked in successfully",
+		"status":      "success",
+		"entitlement": entitlement,
+		"attendee":    checkInAttendee(config.DB, ticket),
 		"ticket": fiber.Map{
-			"ticket_id":              ticket.TicketID,
-			"code":                   ticket.Code,
-			"status":                 ticket.Status,
-			"checked_in_at":          ticket.UpdatedAt,
-			"ticket_category":        ticketCategory.Name,
-			"ticket_category_start":  ticketCategory.DateTimeStart,
-			"ticket_category_end":    ticketCategory.DateTimeEnd,
-			"event_name":             event.Name,
-			"event_date_start":       event.DateStart,
-			"event_date_end":         event.DateEnd,
-			"event_venue":            event.Venue,
-			"event_location":         event.Location,
-			"event_district":         event.District,
+			"ticket_id":             ticket.TicketID,
+			"status":                ticket.Status,
+			"checked_in_at":         now,
+			"first_checked_in_at":   ticket.UsedAt,
+			"ticket_category":       ticketCategory.Name,
+			"ticket_category_start": ticketCategory.DateTimeStart,
+			"ticket_category_end":   ticketCategory.DateTimeEnd,
+			"event_name":            event.Name,
+			"event_date_start":      event.DateStart,
+			"event_date_end":        event.DateEnd,
+			"event_venue":           event.Venue,
+			"event_location":        event.Location,
+			"event_district":        event.District,
 		},
-	})
+	}
 }
 
+// GetTicketCode - kode QR tiket untuk jendela yang sedang berjalan. Kode yang
+// sudah kedaluwarsa diperbarui lewat RenewTicketCode, bukan di GET.
 func GetTicketCode(c *fiber.Ctx) error {
-	TicketID := c.Params("id")
-	var Message string = "Ticket code is valid."
+	return sendTicketCode(c, false)
+}
 
-	var ticket models.Ticket
-	if err := config.DB.First(&ticket, "ticket_id = ?", TicketID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Ticket not found",
-		})
-	}
+// RenewTicketCode - terbitkan nonce baru; kode sebelumnya langsung tidak berlaku
+func RenewTicketCode(c *fiber.Ctx) error {
+	return sendTicketCode(c, true)
+}
 
-	tx := config.DB.Begin()
-	if err := tx.Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to start transaction",
-		})
+func sendTicketCode(c *fiber.Ctx, renew bool) error {
+	var Message string = "Ticket code is valid."
+
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
 	}
 
 	var ticketCategory models.TicketCategory
-	if err := tx.First(&ticketCategory, "ticket_category_id = ?", ticket.TicketCategoryID).Error; err != nil {
-		tx.Rollback()
+	if err := config.DB.First(&ticketCategory, "ticket_category_id = ?", ticket.TicketCategoryID).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch ticket category",
 		})
 	}
 
 	var event models.Event
-	if err := tx.First(&event, "event_id = ?", ticket.EventID).Error; err != nil {
-		tx.Rollback()
+	if err := config.DB.First(&event, "event_id = ?", ticket.EventID).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch event",
 		})
@@ -349,31 +451,30 @@ func GetTicketCode(c *fiber.Ctx) error {
 		Image:     event.Image,
 	}
 
-	// check if ticket is expired
-	if !ticket.ExpiresAt.IsZero() && ticket.ExpiresAt.Before(time.Now()) {
-
-		ticket.Code = utils.GenerateTicketCode()
-		ticket.ExpiresAt = time.Now().Add(30 * time.Minute)
-
-		if err := tx.Save(&ticket).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to update ticket code",
+	if renew {
+		if ticket.Status != "active" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Ticket code can only be renewed for active tickets",
 			})
-		} else {
-			Message = "Ticket code has expired. A new code has been generated."
 		}
-
-		// re fetch ticket to ensure we have the latest data
-		if err := tx.First(&ticket, "ticket_id = ?", TicketID).Error; err != nil {
+		if err := rotateTicketCode(config.DB, &ticket); err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to refetch ticket",
+				"error": "Failed to update ticket code",
 			})
 		}
+		Message = "A new ticket code has been generated."
 	}
 
-	if err := tx.Commit().Error; err != nil {
+	signedCode, err := signTicketCode(ticket)
+	if errors.Is(err, errTicketCodeExpired) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":      "Ticket code has expired. Request a new code",
+			"expires_at": ticket.ExpiresAt,
+		})
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to commit transaction",
+			"error": "Failed to sign ticket code",
 		})
 	}
 
@@ -385,7 +486,7 @@ func GetTicketCode(c *fiber.Ctx) error {
 
 	// prepare response data
 	ticketResponse := ticketResponse{
-		Code:           ticket.Code,
+		Code:           signedCode,
 		TicketID:       ticket.TicketID,
 		TicketCategory: &ticketCategoryResponse,
 		Event:          &eventResponse,
@@ -395,8 +496,130 @@ func GetTicketCode(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(fiber.Map{
-		"message": Message,
-		"ticket":  ticketResponse,
+		"message":    Message,
+		"ticket":     ticketResponse,
+		"expires_at": ticket.ExpiresAt,
+	})
+}
+
+var (
+	errTicketCodeInvalid = errors.New("ticket code invalid")
+	errTicketCodeExpired = errors.New("ticket code expired")
+)
+
+// rotateTicketCode menerbitkan nonce baru untuk satu jendela TicketCodeTTL.
+// Nonce lama dicatat agar scan offline sebelum rotasi tetap bisa diverifikasi.
+func rotateTicketCode(tx *gorm.DB, ticket *models.Ticket) error {
+	now := time.Now()
+	code := utils.GenerateTicketCode()
+	expiresAt := now.Add(utils.TicketCodeTTL())
+
+	if ticket.Code != "" {
+		if err := tx.Create(&models.TicketCodeHistory{
+			TicketCodeHistoryID: utils.GenerateTicketCodeHistoryID(),
+			TicketID:            ticket.TicketID,
+			Code:                ticket.Code,
+			SupersededAt:        now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(&models.Ticket{}).
+		Where("ticket_id = ?", ticket.TicketID).
+		Updates(map[string]interface{}{
+			"code":       code,
+			"expires_at": expiresAt,
+		}).Error; err != nil {
+		return err
+	}
+
+	ticket.Code = code
+	ticket.ExpiresAt = expiresAt
+	return nil
+}
+
+// signTicketCode mengembalikan kode QR bertanda tangan untuk jendela yang
+// sedang berjalan, atau errTicketCodeExpired jika nonce perlu diperbarui.
+func signTicketCode(ticket models.Ticket) (string, error) {
+	if ticket.Code == "" || ticket.ExpiresAt.Before(time.Now()) {
+		return "", errTicketCodeExpired
+	}
+	return utils.SignTicketCode(ticket.TicketID, ticket.EventID, ticket.TicketCategoryID, ticket.Code, ticket.ExpiresAt)
+}
+
+// ticketNonceValid - nonce token masih sama dengan kode rotasi aktif, QR
+// e-ticket PDF terakhir, atau QR pass wallet tiket
+func ticketNonceValid(tx *gorm.DB, ticket models.Ticket, nonce string) bool {
+	if nonce == "" {
+		return false
+	}
+	if nonce == ticket.Code || nonce == ticket.PrintNonce {
+		return true
+	}
+	var count int64
+	tx.Model(&models.WalletPass{}).
+		Where("ticket_id = ? AND (barcode_nonce = ? OR prev_barcode_nonce = ?)", ticket.TicketID, nonce, nonce).
+		Count(&count)
+	return count > 0
+}
+
+// resolveTicketCode mencari tiket dari kode yang discan. Hanya kode bertanda
+// tangan yang diterima (event, jendela waktu, nonce); nonce mentah "tix..."
+// bukan kode masuk.
+func resolveTicketCode(tx *gorm.DB, eventID string, code string) (models.Ticket, error) {
+	var ticket models.Ticket
+
+	claims, err := utils.ParseTicketCode(code)
+	if err != nil {
+		if utils.IsTicketTokenExpired(err) {
+			return ticket, errTicketCodeExpired
+		}
+		return ticket, errTicketCodeInvalid
+	}
+
+	if claims.EventID != eventID {
+		return ticket, errTicketCodeInvalid
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ticket, "ticket_id = ? AND event_id = ?", claims.TicketID, eventID).Error; err != nil {
+		return ticket, errTicketCodeInvalid
+	}
+
+	// nonce berubah berarti kode sudah dirotasi (screenshot lama) atau
+	// PDF-nya sudah diterbitkan ulang
+	if !ticketNonceValid(tx, ticket, claims.Nonce) {
+		return ticket, errTicketCodeExpired
+	}
+
+	return ticket, nil
+}
+
+// GetScannerKey - Public key Ed25519 event untuk verifikasi QR tiket secara
+// offline di scanner. Key ini hanya bisa memverifikasi, tidak bisa menandatangani.
+func GetScannerKey(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+	eventID := c.Params("id")
+
+	var event models.Event
+	if err := config.DB.First(&event, "event_id = ?", eventID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Event not found",
+		})
+	}
+
+	if _, ok := loadScanScope(user, event); !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Not authorized to access scanner key for this event",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"event_id":    event.EventID,
+		"algorithm":   "EdDSA",
+		"prefix":      utils.TicketTokenPrefix,
+		"public_key":  base64.StdEncoding.EncodeToString(utils.EventTicketPublicKey(event.EventID)),
+		"ttl_seconds": int(utils.TicketCodeTTL().Seconds()),
 	})
 }
 
@@ -463,4 +686,4 @@ func GetTicketStats(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"data": stats,
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Status event yang kategori tiketnya masih boleh diubah
+var ticketCategoryEditableStatuses = []string{"draft", "pending", "rejected", "approved", "active"}
+
+// TicketCategoryUpdateRequest - field kosong (nil) tidak diubah
+type TicketCategoryUpdateRequest struct {
+	Name          *string  `json:"name"`
+	Price         *float64 `json:"price"`
+	Quota         *uint    `json:"quota"`
+	Description   *string  `json:"description"`
+	DateTimeStart *string  `json:"date_time_start"`
+	DateTimeEnd   *string  `json:"date_time_end"`
+	EntryMode     *string  `json:"entry_mode"`
+	MaxEntries    *uint    `json:"max_entries"`
+}
+
+type ArchiveTicketCategoryRequest struct {
+	Archived *bool `json:"archived"`
+}
+
+// newTicketCategory membangun kategori baru dari request dan memvalidasinya
+func newTicketCategory(eventID string, req TicketCategoryRequest) (models.TicketCategory, string) {
+	category := models.TicketCategory{
+		TicketCategoryID: utils.GenerateTicketCategoryID(),
+		EventID:          eventID,
+		Name:             req.Name,
+		Price:            req.Price,
+		Quota:            req.Quota,
+		Description:      req.Description,
+		EntryMode:        entryModeOf(req.EntryMode),
+		MaxEntries:       req.MaxEntries,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+
+	if req.Name == "" {
+		return category, "Ticket category name is required"
+	}
+	if !validEntryMode(req.EntryMode) {
+		return category, "Invalid entry_mode in ticket category: " + req.Name
+	}
+
+	var err error
+	if category.DateTimeStart, err = time.Parse(time.RFC3339, req.DateTimeStart); err != nil {
+		return category, "Invalid date_time_start format in ticket category: " + req.Name
+	}
+	if category.DateTimeEnd, err = time.Parse(time.RFC3339, req.DateTimeEnd); err != nil {
+		return category, "Invalid date_time_end format in ticket category: " + req.Name
+	}
+	if !category.DateTimeEnd.After(category.DateTimeStart) {
+		return category, "date_time_end must be after date_time_start in ticket category: " + req.Name
+	}
+
+	return category, ""
+}
+
+// ticketCategoryNameTaken - nama kategori unik per event
+func ticketCategoryNameTaken(tx *gorm.DB, eventID, name, exceptID string) bool {
+	var count int64
+	tx.Model(&models.TicketCategory{}).
+		Where("event_id = ? AND name = ? AND ticket_category_id <> ?", eventID, name, exceptID).
+		Count(&count)
+	return count > 0
+}
+
+// minimumQuota - quota tidak boleh turun di bawah tiket yang sudah terjual
+// atau diimpor
+func minimumQuota(category models.TicketCategory) uint {
+	return category.Sold + category.Imported
+}
+
+// loadEditableCategoryEvent memuat event yang dikelola user dan memastikan
+// kategori tiketnya masih boleh diubah
+func loadEditableCategoryEvent(c *fiber.Ctx) (models.Event, error) {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return event, err
+	}
+
+	if !containsString(ticketCategoryEditableStatuses, event.Status) {
+		return event, fiber.NewError(fiber.StatusConflict, "Ticket categories can not be changed for "+event.Status+" events")
+	}
+
+	return event, nil
+}
+
+// submitCategoryRevision - kategori baru dan perubahan harga pada event live
+// masuk change-set yang direview admin. Event tetap tayang dan kategori lain
+// tetap dijual selama review.
+func submitCategoryRevision(tx *gorm.DB, event models.Event, actorID string, changes []models.FieldChange) (models.EventRevision, error) {
+	if pendingRevisionExists(tx, event.EventID) {
+		return models.EventRevision{}, fiber.NewError(fiber.StatusConflict, "Another change to this event is still under review")
+	}
+	return createEventRevision(tx, event.EventID, actorID, models.RevisionPending, changes)
+}
+
+// CreateTicketCategory - Tambah kategori tiket ke event
+func CreateTicketCategory(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	event, err := loadEditableCategoryEvent(c)
+	if err != nil {
+		return err
+	}
+
+	var req TicketCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	category, msg := newTicketCategory(event.EventID, req)
+	if msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	if ticketCategoryNameTaken(tx, event.EventID, category.Name, "") {
+		tx.Rollback()
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Duplicate ticket category name : " + category.Name,
+		})
+	}
+
+	if containsString(liveEventStatuses, event.Status) {
+		req.TicketCategoryID = ""
+		payload, err := json.Marshal(req)
+		if err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to encode ticket category",
+			})
+		}
+
+		revision, err := submitCategoryRevision(tx, event, user.UserID, []models.FieldChange{{Field: newCategoryChange, New: string(payload)}})
+		if err != nil {
+			tx.Rollback()
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				return fiberErr
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to record event revision",
+			})
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to commit transaction",
+			})
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message":         "Ticket category submitted for review, the published event stays on sale",
+			"revision":        revision,
+			"review_required": true,
+		})
+	}
+
+	if err := tx.Create(&category).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create ticket category",
+		})
+	}
+
+	if err := refreshEventMinPrice(tx, event.EventID); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update event price",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":         "Ticket category created successfully",
+		"ticket_category": category,
+		"review_required": false,
+	})
+}
+
+// UpdateTicketCategory - Ubah satu kategori tiket tanpa mengganti ID-nya.
+// Sold dan Attendant tidak pernah disentuh.
+func UpdateTicketCategory(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	event, err := loadEditableCategoryEvent(c)
+	if err != nil {
+		return err
+	}
+
+	var category models.TicketCategory
+	if err := config.DB.First(&category, "ticket_category_id = ? AND event_id = ?", c.Params("category_id"), event.EventID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ticket category not found",
+		})
+	}
+
+	var req TicketCategoryUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+
+	updates := map[string]interface{}{}
+	// harga kategori event live direview lewat change-set
+	var priceChange *models.FieldChange
+
+	if req.Name != nil && *req.Name != category.Name {
+		if *req.Name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Ticket category name is required",
+			})
+		}
+		if ticketCategoryNameTaken(config.DB, event.EventID, *req.Name, category.TicketCategoryID) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Duplicate ticket category name : " + *req.Name,
+			})
+		}
+		updates["name"] = *req.Name
+		category.Name = *req.Name
+	}
+	if req.Price != nil && *req.Price != category.Price {
+		if *req.Price < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Price must not be negative",
+			})
+		}
+		if containsString(liveEventStatuses, event.Status) {
+			priceChange = &models.FieldChange{
+				Field: categoryPriceChange(category.TicketCategoryID),
+				Old:   strconv.FormatFloat(category.Price, 'f', -1, 64),
+				New:   strconv.FormatFloat(*req.Price, 'f', -1, 64),
+			}
+		} else {
+			updates["price"] = *req.Price
+			category.Price = *req.Price
+		}
+	}
+	if req.Quota != nil && *req.Quota != category.Quota {
+		if category.Seated {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Quota of a seated category follows its seat map",
+			})
+		}
+		if *req.Quota < minimumQuota(category) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":         "Quota can not be lower than tickets already sold",
+				"minimum_quota": minimumQuota(category),
+			})
+		}
+		updates["quota"] = *req.Quota
+		category.Quota = *req.Quota
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+		category.Description = *req.Description
+	}
+	if req.DateTimeStart != nil {
+		dateTimeStart, err := time.Parse(time.RFC3339, *req.DateTimeStart)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid date_time_start format",
+			})
+		}
+		updates["date_time_start"] = dateTimeStart
+		category.DateTimeStart = dateTimeStart
+	}
+	if req.DateTimeEnd != nil {
+		dateTimeEnd, err := time.Parse(time.RFC3339, *req.DateTimeEnd)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid date_time_end format",
+			})
+		}
+		updates["date_time_end"] = dateTimeEnd
+		category.DateTimeEnd = dateTimeEnd
+	}
+	if !category.DateTimeEnd.After(category.DateTimeStart) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "date_time_end must be after date_time_start",
+		})
+	}
+	if req.EntryMode != nil {
+		if !validEntryMode(*req.EntryMode) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid entry_mode",
+			})
+		}
+		updates["entry_mode"] = entryModeOf(*req.EntryMode)
+		category.EntryMode = entryModeOf(*req.EntryMode)
+	}
+	if req.MaxEntries != nil {
+		updates["max_entries"] = *req.MaxEntries
+		category.MaxEntries = *req.MaxEntries
+	}
+
+	if len(updates) == 0 && priceChange == nil {
+		return c.JSON(fiber.Map{
+			"message":         "Nothing to update",
+			"ticket_category": category,
+			"review_required": false,
+		})
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	var revision *models.EventRevision
+	if priceChange != nil {
+		created, err := submitCategoryRevision(tx, event, user.UserID, []models.FieldChange{*priceChange})
+		if err != nil {
+			tx.Rollback()
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				return fiberErr
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to record event revision",
+			})
+		}
+		revision = &created
+	}
+
+	if len(updates) > 0 {
+		updates["updated_at"] = time.Now()
+
+		// quota dicek ulang di query agar tidak balapan dengan pembayaran
+		query := tx.Model(&models.TicketCategory{}).Where("ticket_category_id = ?", category.TicketCategoryID)
+		if quota, ok := updates["quota"]; ok {
+			query = query.Where("sold + imported <= ?", quota)
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update ticket category",
+			})
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Quota can not be lower than tickets already sold",
+			})
+		}
+
+		if _, ok := updates["price"]; ok {
+			if err := repriceCarts(tx, category.TicketCategoryID, category.Price); err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to update cart prices",
+				})
+			}
+		}
+	}
+
+	if err := refreshEventMinPrice(tx, event.EventID); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update event price",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	if err := config.DB.First(&category, "ticket_category_id = ?", category.TicketCategoryID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load updated ticket category",
+		})
+	}
+
+	// nama kategori tampil di pass wallet
+	if _, ok := updates["name"]; ok {
+		go notifyEventWallets(event.EventID)
+	}
+
+	if revision != nil {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message":         "Ticket category updated, the price change is submitted for review",
+			"ticket_category": category,
+			"revision":        revision,
+			"review_required": true,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":         "Ticket category updated successfully",
+		"ticket_category": category,
+		"review_required": false,
+	})
+}
+
+// ArchiveTicketCategory - Hentikan penjualan kategori tanpa menghapusnya.
+// Tiket yang sudah terjual tetap berlaku. {"archived": false} membuka lagi.
+func ArchiveTicketCategory(c *fiber.Ctx) error {
+	event, err := loadEditableCategoryEvent(c)
+	if err != nil {
+		return err
+	}
+
+	var category models.TicketCategory
+	if err := config.DB.First(&category, "ticket_category_id = ? AND event_id = ?", c.Params("category_id"), event.EventID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ticket category not found",
+		})
+	}
+
+	var req ArchiveTicketCategoryRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request",
+			})
+		}
+	}
+	archived := true
+	if req.Archived != nil {
+		archived = *req.Archived
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	if err := tx.Model(&category).Updates(map[string]interface{}{
+		"archived":   archived,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to archive ticket category",
+		})
+	}
+
+	// keranjang kategori yang diarsipkan tidak bisa dibayar lagi
+	if archived {
+		if err := tx.Where("ticket_category_id = ?", category.TicketCategoryID).Delete(&models.Cart{}).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to clear carts",
+			})
+		}
+	}
+
+	if err := refreshEventMinPrice(tx, event.EventID); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update event price",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+	category.Archived = archived
+
+	message := "Ticket category archived successfully"
+	if !archived {
+		message = "Ticket category restored successfully"
+	}
+
+	return c.JSON(fiber.Map{
+		"message":         message,
+		"ticket_category": category,
+	})
+}
@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// eTicket - data satu tiket yang dicetak ke PDF
+type eTicket struct {
+	Ticket   models.Ticket
+	Category models.TicketCategory
+	Event    models.Event
+	Holder   string
+	QRCode   string
+}
+
+// ticketHolderName - nama pemegang tiket. Tiket impor tanpa akun dipegang
+// organizer, jadi nama peserta diambil dari data impor.
+func ticketHolderName(ticket models.Ticket) string {
+	if ticket.HolderName != "" {
+		return ticket.HolderName
+	}
+	if ticket.Owner.Name != "" {
+		return ticket.Owner.Name
+	}
+	return ticket.Owner.Username
+}
+
+// loadOwnedTicket mengambil tiket milik user yang login (admin boleh semua tiket)
+func loadOwnedTicket(c *fiber.Ctx, ticketID string) (models.Ticket, error) {
+	user := c.Locals("user").(models.User)
+
+	var ticket models.Ticket
+	if err := config.DB.Preload("Owner").First(&ticket, "ticket_id = ?", ticketID).Error; err != nil {
+		return ticket, fiber.NewError(fiber.StatusNotFound, "Ticket not found")
+	}
+
+	if ticket.OwnerID == user.UserID || user.Role == "admin" {
+		return ticket, nil
+	}
+
+	// tiket impor yang belum diklaim dikelola organizer event-nya
+	if ticket.OwnerID == "" && ticket.Source == models.TicketSourceImport {
+		var count int64
+		config.DB.Model(&models.Event{}).Where("event_id = ? AND owner_id = ?", ticket.EventID, user.UserID).Count(&count)
+		if count > 0 {
+			return ticket, nil
+		}
+	}
+
+	return ticket, fiber.NewError(fiber.StatusForbidden, "Not authorized to access this ticket")
+}
+
+// GetTicketQR - Gambar QR (PNG/SVG) dari kode tiket yang sedang berlaku
+func GetTicketQR(c *fiber.Ctx) error {
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if ticket.Status != "active" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "QR code is only available for active tickets",
+		})
+	}
+
+	size, err := strconv.Atoi(c.Query("size", "512"))
+	if err != nil || size < 128 || size > 2048 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid size, must be between 128 and 2048",
+		})
+	}
+
+	// GET tidak merotasi kode; kode kedaluwarsa diperbarui via POST /:id/code
+	code, err := signTicketCode(ticket)
+	if errors.Is(err, errTicketCodeExpired) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":      "Ticket code has expired. Request a new code",
+			"expires_at": ticket.ExpiresAt,
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate ticket code",
+		})
+	}
+
+	// kode berotasi, jangan di-cache
+	c.Set("Cache-Control", "no-store")
+	c.Set("X-Ticket-Code-Expires-At", ticket.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"))
+
+	switch c.Query("format", "png") {
+	case "png":
+		png, err := utils.GenerateQRCodePNG(code, size)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to render QR code",
+			})
+		}
+		c.Set("Content-Type", "image/png")
+		return c.Send(png)
+	case "svg":
+		svg, err := utils.GenerateQRCodeSVG(code, size)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to render QR code",
+			})
+		}
+		c.Set("Content-Type", "image/svg+xml")
+		return c.SendString(svg)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid format, use png or svg",
+		})
+	}
+}
+
+// DownloadTicketPDF - E-ticket PDF untuk satu tiket dengan QR cetak yang
+// sedang berlaku
+func DownloadTicketPDF(c *fiber.Ctx) error {
+	return sendTicketPDF(c, false)
+}
+
+// ReissueTicketPDF - Terbitkan ulang e-ticket PDF; QR cetak sebelumnya dicabut
+func ReissueTicketPDF(c *fiber.Ctx) error {
+	return sendTicketPDF(c, true)
+}
+
+func sendTicketPDF(c *fiber.Ctx, reissue bool) error {
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if ticket.Status != "active" && ticket.Status != "used" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "E-ticket is only available for paid tickets",
+		})
+	}
+
+	eticket, err := buildETicket(ticket, reissue)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to prepare e-ticket: " + err.Error(),
+		})
+	}
+
+	pdf, err := renderETicketsPDF([]eTicket{eticket})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to render e-ticket",
+		})
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=e-ticket_%s.pdf", ticket.TicketID))
+	return c.Send(pdf)
+}
+
+// DownloadTransactionTicketsPDF - Semua tiket dalam satu transaksi sebagai satu PDF
+func DownloadTransactionTicketsPDF(c *fiber.Ctx) error {
+	return sendTransactionTicketsPDF(c, false)
+}
+
+// ReissueTransactionTicketsPDF - Terbitkan ulang PDF semua tiket transaksi;
+// QR cetak sebelumnya dari tiket-tiket tersebut dicabut
+func ReissueTransactionTicketsPDF(c *fiber.Ctx) error {
+	return sendTransactionTicketsPDF(c, true)
+}
+
+func sendTransactionTicketsPDF(c *fiber.Ctx, reissue bool) error {
+	user := c.Locals("user").(models.User)
+	transactionID := c.Params("id")
+
+	var transaction models.TransactionHistory
+	query := config.DB.Where("transaction_id = ?", transactionID)
+	if user.Role != "admin" {
+		query = query.Where("owner_id = ?", user.UserID)
+	}
+	if err := query.First(&transaction).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Transaction not found",
+		})
+	}
+
+	if transaction.TransactionStatus != "paid" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "E-tickets are only available for paid transactions",
+		})
+	}
+
+	tickets, err := transactionTickets(transaction)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch tickets: " + err.Error(),
+		})
+	}
+
+	if len(tickets) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "No tickets found for this transaction",
+		})
+	}
+
+	var etickets []eTicket
+	for _, ticket := range tickets {
+		eticket, err := buildETicket(ticket, reissue)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to prepare e-ticket: " + err.Error(),
+			})
+		}
+		etickets = append(etickets, eticket)
+	}
+
+	pdf, err := renderETicketsPDF(etickets)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to render e-tickets",
+		})
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=e-tickets_%s.pdf", transaction.TransactionID))
+	return c.Send(pdf)
+}
+
+// transactionTickets - tiket aktif/terpakai dari satu transaksi. Tiket lama
+// tanpa transaction_id tidak bisa dipastikan asal transaksinya, jadi hanya
+// tersedia lewat PDF per tiket.
+func transactionTickets(transaction models.TransactionHistory) ([]models.Ticket, error) {
+	var tickets []models.Ticket
+	err := config.DB.Preload("Owner").
+		Where("transaction_id = ? AND status IN ?", transaction.TransactionID, []string{"active", "used"}).
+		Order("created_at ASC").
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// ticketPrintNonce mengembalikan print nonce tiket. Nonce dibuat sekali saat
+// PDF pertama diunduh dan hanya diganti lewat reissue, sehingga unduhan ulang
+// tidak mencabut salinan yang sudah dicetak.
+func ticketPrintNonce(ticket models.Ticket, reissue bool) (string, error) {
+	if ticket.PrintNonce != "" && !reissue {
+		return ticket.PrintNonce, nil
+	}
+
+	nonce := utils.GeneratePrintNonce()
+	query := config.DB.Model(&models.Ticket{}).Where("ticket_id = ?", ticket.TicketID)
+	if !reissue {
+		// unduhan pertama yang bersamaan memakai nonce yang sama
+		query = query.Where("print_nonce = '' OR print_nonce IS NULL")
+	}
+	result := query.Update("print_nonce", nonce)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		var current models.Ticket
+		if err := config.DB.Select("print_nonce").First(&current, "ticket_id = ?", ticket.TicketID).Error; err != nil {
+			return "", err
+		}
+		return current.PrintNonce, nil
+	}
+	return nonce, nil
+}
+
+func buildETicket(ticket models.Ticket, reissue bool) (eTicket, error) {
+	var category models.TicketCategory
+	if err := config.DB.First(&category, "ticket_category_id = ?", ticket.TicketCategoryID).Error; err != nil {
+		return eTicket{}, err
+	}
+
+	var event models.Event
+	if err := config.DB.First(&event, "event_id = ?", ticket.EventID).Error; err != nil {
+		return eTicket{}, err
+	}
+
+	// QR cetak berlaku sampai check-in ditutup karena kertas tidak bisa
+	// dirotasi. Reissue mengganti print nonce sehingga salinan lama (foto,
+	// screenshot, PDF yang diteruskan) tidak berlaku lagi.
+	nonce, err := ticketPrintNonce(ticket, reissue)
+	if err != nil {
+		return eTicket{}, err
+	}
+	ticket.PrintNonce = nonce
+
+	code, err := utils.SignTicketCode(ticket.TicketID, ticket.EventID, ticket.TicketCategoryID, nonce, checkInClosesAt(event))
+	if err != nil {
+		return eTicket{}, err
+	}
+
+	holder := ticketHolderName(ticket)
+
+	return eTicket{
+		Ticket:   ticket,
+		Category: category,
+		Event:    event,
+		Holder:   holder,
+		QRCode:   code,
+	}, nil
+}
+
+func renderETicketsPDF(etickets []eTicket) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("E-Ticket", true)
+	pdf.SetMargins(20, 20, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for i, et := range etickets {
+		pdf.AddPage()
+
+		// Header
+		pdf.SetFillColor(33, 37, 41)
+		pdf.Rect(0, 0, 210, 28, "F")
+		pdf.SetTextColor(255, 255, 255)
+		pdf.SetFont("Helvetica", "B", 18)
+		pdf.SetXY(20, 9)
+		pdf.CellFormat(170, 10, "E-TICKET", "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.SetXY(20, 9)
+		pdf.CellFormat(170, 10, fmt.Sprintf("%d / %d", i+1, len(etickets)), "", 0, "R", false, 0, "")
+
+		// Event
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetXY(20, 38)
+		pdf.SetFont("Helvetica", "B", 16)
+		pdf.MultiCell(170, 8, tr(et.Event.Name), "", "L", false)
+		pdf.Ln(2)
+
+		rows := [][2]string{
+			{"Date", fmt.Sprintf("%s - %s", et.Event.DateStart.Format("02 Jan 2006 15:04"), et.Event.DateEnd.Format("02 Jan 2006 15:04"))},
+			{"Venue", et.Event.Venue},
+			{"Location", et.Event.Location},
+			{"District", et.Event.District},
+			{"Category", et.Category.Name},
+			{"Holder", et.Holder},
+			{"Tag", et.Ticket.Tag},
+			{"Ticket ID", et.Ticket.TicketID},
+		}
+		if et.Ticket.SeatLabel != "" {
+			rows = append(rows, [2]string{"Seat", et.Ticket.SeatLabel})
+		}
+		for _, row := range rows {
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.CellFormat(35, 8, row[0], "", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 11)
+			pdf.MultiCell(135, 8, tr(row[1]), "", "L", false)
+		}
+
+		// QR code
+		png, err := utils.GenerateQRCodePNG(et.QRCode, 600)
+		if err != nil {
+			return nil, err
+		}
+		imageName := "qr-" + et.Ticket.TicketID
+		options := fpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(imageName, options, bytes.NewReader(png))
+		pdf.ImageOptions(imageName, 55, pdf.GetY()+8, 100, 100, false, options, 0, "")
+
+		pdf.SetXY(20, 262)
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.MultiCell(170, 5, "Show the live QR code in the app when possible; this printed code is the fallback. Downloading this ticket again invalidates earlier copies. Do not share this ticket with anyone.", "", "C", false)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
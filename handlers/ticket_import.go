@@ -0,0 +1,513 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxImportRows = 5000
+
+// Nama kolom yang dikenali di file impor (header tidak case-sensitive)
+var importColumns = map[string]string{
+	"external_ref": "external_ref",
+	"reference":    "external_ref",
+	"ref":          "external_ref",
+	"order_id":     "external_ref",
+	"name":         "name",
+	"holder_name":  "name",
+	"nama":         "name",
+	"email":        "email",
+	"holder_email": "email",
+}
+
+// importRow - satu baris file impor beserta hasil validasinya
+type importRow struct {
+	Row         int      `json:"row"`
+	ExternalRef string   `json:"external_ref"`
+	Name        string   `json:"name"`
+	Email       string   `json:"email"`
+	Result      string   `json:"result"` // create, skip, error
+	Holder      string   `json:"holder,omitempty"`
+	ClaimCode   string   `json:"claim_code,omitempty"`
+	Errors      []string `json:"errors,omitempty"`
+
+	ownerID string
+}
+
+// readImportFile membaca CSV atau sheet pertama XLSX menjadi baris-baris string
+func readImportFile(name string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+	case ".xlsx":
+		book, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer book.Close()
+
+		sheets := book.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("workbook has no sheets")
+		}
+		return book.GetRows(sheets[0])
+	default:
+		return nil, fmt.Errorf("unsupported file type %s, use .csv or .xlsx", filepath.Ext(name))
+	}
+}
+
+// parseImportRows memetakan header ke kolom yang dikenali lalu membaca tiap baris
+func parseImportRows(records [][]string) ([]importRow, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	index := make(map[string]int)
+	for i, header := range records[0] {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		key = strings.ReplaceAll(key, " ", "_")
+		if column, ok := importColumns[key]; ok {
+			index[column] = i
+		}
+	}
+	if _, ok := index["external_ref"]; !ok {
+		return nil, fmt.Errorf("missing external_ref column")
+	}
+	if _, ok := index["name"]; !ok {
+		return nil, fmt.Errorf("missing name column")
+	}
+
+	cell := func(record []string, column string) string {
+		i, ok := index[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := make([]importRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		rows = append(rows, importRow{
+			Row:         i + 2, // baris 1 adalah header
+			ExternalRef: cell(record, "external_ref"),
+			Name:        cell(record, "name"),
+			Email:       strings.ToLower(cell(record, "email")),
+		})
+	}
+	if len(rows) > maxImportRows {
+		return nil, fmt.Errorf("too many rows, maximum is %d", maxImportRows)
+	}
+
+	return rows, nil
+}
+
+// validateImportRows mengisi Result tiap baris: error validasi, skip jika
+// external_ref sudah pernah diimpor (idempoten), atau create
+func validateImportRows(db *gorm.DB, event models.Event, rows []importRow) error {
+	seen := make(map[string]int)
+	refs := make([]string, 0, len(rows))
+	emails := make([]string, 0, len(rows))
+
+	for i := range rows {
+		row := &rows[i]
+		if row.ExternalRef == "" {
+			row.Errors = append(row.Errors, "external_ref is required")
+		} else if len(row.ExternalRef) > 100 {
+			row.Errors = append(row.Errors, "external_ref must be at most 100 characters")
+		} else if first, dup := seen[row.ExternalRef]; dup {
+			row.Errors = append(row.Errors, fmt.Sprintf("duplicate external_ref, first seen on row %d", first))
+		} else {
+			seen[row.ExternalRef] = row.Row
+			refs = append(refs, row.ExternalRef)
+		}
+
+		if row.Name == "" {
+			row.Errors = append(row.Errors, "name is required")
+		} else if len(row.Name) > 100 {
+			row.Errors = append(row.Errors, "name must be at most 100 characters")
+		}
+
+		if row.Email != "" {
+			if _, err := mail.ParseAddress(row.Email); err != nil || len(row.Email) > 100 {
+				row.Errors = append(row.Errors, "email is invalid")
+			} else {
+				emails = append(emails, row.Email)
+			}
+		}
+	}
+
+	existing := make(map[string]bool)
+	if len(refs) > 0 {
+		var found []string
+		if err := db.Model(&models.Ticket{}).
+			Where("event_id = ? AND external_ref IN ?", event.EventID, refs).
+			Pluck("external_ref", &found).Error; err != nil {
+			return err
+		}
+		for _, ref := range found {
+			existing[ref] = true
+		}
+	}
+
+	accounts := make(map[string]string)
+	if len(emails) > 0 {
+		var users []models.User
+		if err := db.Where("email IN ? AND role = ?", emails, "user").Find(&users).Error; err != nil {
+			return err
+		}
+		for _, user := range users {
+			accounts[strings.ToLower(user.Email)] = user.UserID
+		}
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		switch {
+		case len(row.Errors) > 0:
+			row.Result = "error"
+		case existing[row.ExternalRef]:
+			row.Result = "skip"
+		default:
+			row.Result = "create"
+			if userID, ok := accounts[row.Email]; ok {
+				row.ownerID = userID
+				row.Holder = "account"
+			} else {
+				// tanpa akun: tiket tidak dimiliki siapa pun sampai pemegangnya
+				// mengklaim dengan claim_code yang dibagikan organizer
+				row.Holder = "unclaimed"
+			}
+		}
+	}
+
+	return nil
+}
+
+// ImportTickets - Impor tiket yang dijual di luar platform dari CSV/XLSX.
+// Form: file, ticket_category_id, dry_run. Kolom: external_ref, name, email.
+// Baris dengan external_ref yang sudah ada dilewati sehingga upload ulang aman.
+// Impor bersifat semua-atau-tidak: satu baris error membatalkan seluruh file.
+func ImportTickets(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var category models.TicketCategory
+	categoryID := c.FormValue("ticket_category_id")
+	found := false
+	for _, tc := range event.TicketCategories {
+		if tc.TicketCategoryID == categoryID {
+			category, found = tc, true
+			break
+		}
+	}
+	if !found {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ticket_category_id must be a ticket category of this event",
+		})
+	}
+
+	dryRun := c.FormValue("dry_run") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "File is required",
+		})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to open file",
+		})
+	}
+	defer file.Close()
+
+	records, err := readImportFile(fileHeader.Filename, file)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to read file: " + err.Error(),
+		})
+	}
+
+	rows, err := parseImportRows(records)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := validateImportRows(config.DB, event, rows); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to validate rows",
+		})
+	}
+
+	summary := fiber.Map{"total_rows": len(rows)}
+	var creates, skips, failures int
+	for _, row := range rows {
+		switch row.Result {
+		case "create":
+			creates++
+		case "skip":
+			skips++
+		case "error":
+			failures++
+		}
+	}
+	summary["create"], summary["skip"], summary["error"] = creates, skips, failures
+
+	// tiket impor ikut memakai kuota kategori
+	available := int(category.Quota) - int(category.Sold) - int(category.Imported)
+	var quotaError string
+	if creates > available {
+		quotaError = fmt.Sprintf("Not enough quota for ticket category %s. Available: %d, Requested: %d", category.Name, available, creates)
+	}
+
+	if dryRun || failures > 0 || quotaError != "" {
+		status := fiber.StatusOK
+		message := "Dry run completed"
+		if !dryRun {
+			status = fiber.StatusUnprocessableEntity
+			message = "Import rejected, fix the errors and upload again"
+		}
+		response := fiber.Map{
+			"message": message,
+			"dry_run": dryRun,
+			"summary": summary,
+			"rows":    rows,
+		}
+		if quotaError != "" {
+			response["error"] = quotaError
+		}
+		return c.Status(status).JSON(response)
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start transaction",
+		})
+	}
+
+	batch := models.TicketImport{
+		TicketImportID:   utils.GenerateTicketImportID(),
+		EventID:          event.EventID,
+		TicketCategoryID: category.TicketCategoryID,
+		ImportedBy:       user.UserID,
+		FileName:         fileHeader.Filename,
+		TotalRows:        len(rows),
+		Created:          creates,
+		Skipped:          skips,
+	}
+	if err := tx.Create(&batch).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create import",
+		})
+	}
+
+	// kuota dicek ulang di query agar tidak balapan dengan pembayaran atau
+	// impor lain yang berjalan bersamaan
+	if creates > 0 {
+		result := tx.Model(&models.TicketCategory{}).
+			Where("ticket_category_id = ? AND sold + imported + ? <= quota", category.TicketCategoryID, creates).
+			UpdateColumn("imported", gorm.Expr("imported + ?", creates))
+		if result.Error != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update ticket category",
+			})
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Not enough quota for ticket category " + category.Name,
+			})
+		}
+	}
+
+	now := time.Now()
+	for i, row := range rows {
+		if row.Result != "create" {
+			continue
+		}
+
+		ref := row.ExternalRef
+		ticket := models.Ticket{
+			TicketID:         utils.GenerateTicketID(),
+			EventID:          event.EventID,
+			TicketCategoryID: category.TicketCategoryID,
+			OwnerID:          row.ownerID,
+			Status:           "active",
+			Code:             utils.GenerateTicketCode(),
+			CreatedAt:        now,
+			UpdatedAt:        now,
+			ExpiresAt:        now.Add(1 * time.Minute),
+			Tag:              "My Ticket",
+			Source:           models.TicketSourceImport,
+			ExternalRef:      &ref,
+			TicketImportID:   batch.TicketImportID,
+			HolderName:       row.Name,
+			HolderEmail:      row.Email,
+		}
+		if row.ownerID == "" {
+			claimCode := utils.GenerateClaimCode()
+			ticket.ClaimCode = &claimCode
+			rows[i].ClaimCode = claimCode
+		}
+		if err := tx.Create(&ticket).Error; err != nil {
+			tx.Rollback()
+			// unique index (event_id, external_ref) menahan impor paralel file yang sama
+			log.Printf("Failed to create imported ticket for row %d: %v", row.Row, err)
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": fmt.Sprintf("Failed to create ticket for row %d, it may have been imported already", row.Row),
+			})
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to commit transaction",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Tickets imported successfully",
+		"dry_run": false,
+		"import":  batch,
+		"summary": summary,
+		"rows":    rows,
+	})
+}
+
+// GetTicketImports - Riwayat impor tiket event
+func GetTicketImports(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var imports []models.TicketImport
+	if err := config.DB.Where("event_id = ?", event.EventID).
+		Order("created_at DESC").
+		Find(&imports).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch imports",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Imports retrieved successfully",
+		"imports": imports,
+	})
+}
+
+// GetImportedTickets - Tiket impor event (?status=, ?unclaimed=true). Tiket
+// tanpa akun tidak dimiliki organizer, jadi daftarnya diambil lewat source.
+func GetImportedTickets(c *fiber.Ctx) error {
+	event, err := loadManagedEvent(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	query := config.DB.Model(&models.Ticket{}).
+		Where("event_id = ? AND source = ?", event.EventID, models.TicketSourceImport)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if c.Query("unclaimed") == "true" {
+		query = query.Where("owner_id IS NULL OR owner_id = ''")
+	}
+
+	tickets, pagination, err := paginate(c, query, ticketListSpec, nil)
+	if err != nil {
+		return listError(c, err, "Failed to fetch imported tickets")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Imported tickets retrieved successfully",
+		"tickets":    tickets,
+		"pagination": pagination,
+	})
+}
+
+// ClaimImportedTicket - Pemegang tiket impor tanpa akun mengklaim tiketnya ke
+// akun sendiri dengan claim_code dari organizer. Kode hanya bisa dipakai sekali.
+func ClaimImportedTicket(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+
+	if user.Role != "user" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only buyer accounts can claim tickets",
+		})
+	}
+
+	var req struct {
+		ClaimCode string `json:"claim_code"`
+	}
+	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.ClaimCode) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "claim_code is required",
+		})
+	}
+
+	var ticket models.Ticket
+	if err := config.DB.First(&ticket, "claim_code = ? AND source = ?", strings.TrimSpace(req.ClaimCode), models.TicketSourceImport).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Claim code not found",
+		})
+	}
+
+	result := config.DB.Model(&models.Ticket{}).
+		Where("ticket_id = ? AND claim_code = ? AND (owner_id IS NULL OR owner_id = '')", ticket.TicketID, *ticket.ClaimCode).
+		Updates(map[string]interface{}{
+			"owner_id":   user.UserID,
+			"claim_code": nil,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to claim ticket",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Ticket has already been claimed",
+		})
+	}
+
+	if err := config.DB.Preload("Owner").First(&ticket, "ticket_id = ?", ticket.TicketID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load claimed ticket",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Ticket claimed successfully",
+		"ticket":  ticket,
+	})
+}
@@ -15,7 +15,6 @@ func GetTransactionHistory(c *fiber.Ctx) error {
 	// Struct untuk response dengan detail lengkap
 	type TicketDetailResponse struct {
 		TicketID         string    `json:"ticket_id"`
-		Code             string    `json:"code"`
 		Status           string    `json:"status"`
 		TicketCategoryID string    `json:"ticket_category_id"`
 		CategoryName     string    `json:"category_name"`
@@ -46,19 +45,19 @@ func GetTransactionHistory(c *fiber.Ctx) error {
 		Events            []EventInTransactionResponse `json:"events"`
 	}
 
-	// Get all transactions untuk user
-	var transactions []models.TransactionHistory
-	if err := config.DB.
-		Where("owner_id = ?", user.UserID).
-		Order("transaction_time DESC").
-		Find(&transactions).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch transaction history: " + err.Error(),
-		})
+	// Get transactions untuk user (per halaman)
+	query := config.DB.Model(&models.TransactionHistory{}).Where("owner_id = ?", user.UserID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("transaction_status = ?", status)
+	}
+
+	transactions, pagination, err := paginate(c, query, transactionListSpec, nil)
+	if err != nil {
+		return listError(c, err, "Failed to fetch transaction history")
 	}
 
 	// Build response dengan details
-	var response []TransactionHistoryResponse
+	response := make([]TransactionHistoryResponse, 0, len(transactions))
 
 	for _, transaction := range transactions {
 		// Get transaction details
@@ -118,7 +117,6 @@ func GetTransactionHistory(c *fiber.Ctx) error {
 					eventMap[event.EventID].TicketDetails,
 					TicketDetailResponse{
 						TicketID:         ticket.TicketID,
-						Code:             ticket.Code,
 						Status:           ticket.Status,
 						TicketCategoryID: ticketCategory.TicketCategoryID,
 						CategoryName:     ticketCategory.Name,
@@ -152,6 +150,7 @@ func GetTransactionHistory(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message":      "Transaction history retrieved successfully",
 		"transactions": response,
+		"pagination":   pagination,
 	})
 }
 
@@ -163,7 +162,6 @@ func GetTransactionDetail(c *fiber.Ctx) error {
 	// Struct untuk response detail
 	type TicketDetailResponse struct {
 		TicketID         string    `json:"ticket_id"`
-		Code             string    `json:"code"`
 		Status           string    `json:"status"`
 		TicketCategoryID string    `json:"ticket_category_id"`
 		CategoryName     string    `json:"category_name"`
@@ -256,7 +254,6 @@ func GetTransactionDetail(c *fiber.Ctx) error {
 				eventMap[event.EventID].TicketDetails,
 				TicketDetailResponse{
 					TicketID:         ticket.TicketID,
-					Code:             ticket.Code,
 					Status:           ticket.Status,
 					TicketCategoryID: ticketCategory.TicketCategoryID,
 					CategoryName:     ticketCategory.Name,
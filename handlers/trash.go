@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Retensi default trash sebelum dihapus permanen
+const defaultTrashRetentionDays = 30
+
+// trashResource - resource yang bisa dihapus sementara dan dipulihkan admin
+type trashResource struct {
+	model interface{}
+	page  func(c *fiber.Ctx, query *gorm.DB) (interface{}, fiber.Map, error)
+	key   string
+}
+
+// trashPage memasang paginate untuk satu tipe baris trash
+func trashPage[T any](spec listSpec[T]) func(*fiber.Ctx, *gorm.DB) (interface{}, fiber.Map, error) {
+	return func(c *fiber.Ctx, query *gorm.DB) (interface{}, fiber.Map, error) {
+		return paginate(c, query, spec, nil)
+	}
+}
+
+var trashResources = map[string]trashResource{
+	"events": {
+		model: &models.Event{},
+		page: trashPage(trashListSpec("event_id",
+			func(e models.Event) string { return e.EventID },
+			func(e models.Event) gorm.DeletedAt { return e.DeletedAt })),
+		key: "event_id",
+	},
+	"users": {
+		model: &models.User{},
+		page: trashPage(trashListSpec("user_id",
+			func(u models.User) string { return u.UserID },
+			func(u models.User) gorm.DeletedAt { return u.DeletedAt })),
+		key: "user_id",
+	},
+	"feedback": {
+		model: &models.Feedback{},
+		page: trashPage(trashListSpec("feedback_id",
+			func(f models.Feedback) string { return f.FeedbackID },
+			func(f models.Feedback) gorm.DeletedAt { return f.DeletedAt })),
+		key: "feedback_id",
+	},
+	"categories": {
+		model: &models.EventCategory{},
+		page: trashPage(trashListSpec("event_category_id",
+			func(ec models.EventCategory) string { return ec.EventCategoryID },
+			func(ec models.EventCategory) gorm.DeletedAt { return ec.DeletedAt })),
+		key: "event_category_id",
+	},
+	"child-categories": {
+		model: &models.ChildEventCategory{},
+		page: trashPage(trashListSpec("child_event_category_id",
+			func(cc models.ChildEventCategory) string { return cc.ChildEventCategoryID },
+			func(cc models.ChildEventCategory) gorm.DeletedAt { return cc.DeletedAt })),
+		key: "child_event_category_id",
+	},
+}
+
+// softDelete menandai baris sebagai terhapus beserta pelakunya. Baris masih
+// bisa dipulihkan dari trash sampai dipurge.
+func softDelete(model interface{}, actorID string, query string, args ...interface{}) (int64, error) {
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	if err := tx.Model(model).Where(query, args...).Update("deleted_by", actorID).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	result := tx.Where(query, args...).Delete(model)
+	if result.Error != nil {
+		tx.Rollback()
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, tx.Commit().Error
+}
+
+func trashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = defaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// GetTrash - Daftar baris yang dihapus sementara untuk satu resource
+func GetTrash(c *fiber.Ctx) error {
+	resource, ok := trashResources[c.Params("type")]
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Unknown trash type",
+		})
+	}
+
+	query := config.DB.Unscoped().Model(resource.model).Where("deleted_at IS NOT NULL")
+	items, pagination, err := resource.page(c, query)
+	if err != nil {
+		return listError(c, err, "Failed to fetch trash")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":        "Trash retrieved successfully",
+		"retention_days": int(trashRetention().Hours() / 24),
+		"data":           items,
+		"pagination":     pagination,
+	})
+}
+
+// RestoreTrash - Pulihkan satu baris dari trash
+func RestoreTrash(c *fiber.Ctx) error {
+	resource, ok := trashResources[c.Params("type")]
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Unknown trash type",
+		})
+	}
+	id := c.Params("id")
+
+	// sub kategori hanya bisa dipulihkan jika induknya masih ada
+	if c.Params("type") == "child-categories" {
+		var child models.ChildEventCategory
+		if err := config.DB.Unscoped().First(&child, "child_event_category_id = ?", id).Error; err == nil {
+			var parent int64
+			config.DB.Model(&models.EventCategory{}).Where("event_category_id = ?", child.ParentCategoryID).Count(&parent)
+			if parent == 0 {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": "Restore the parent category first",
+				})
+			}
+		}
+	}
+
+	result := config.DB.Unscoped().Model(resource.model).
+		Where(resource.key+" = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"deleted_by": "",
+		})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to restore item",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Item not found in trash",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Item restored successfully",
+	})
+}
+
+// purgeRows menghapus permanen baris trash satu per satu; baris yang masih
+// direferensikan data lain (mis. user dengan transaksi) dilewati
+func purgeRows(name string, ids []string, purge func(tx *gorm.DB, id string) error) {
+	for _, id := range ids {
+		tx := config.DB.Begin()
+		if tx.Error != nil {
+			log.Println("Failed to start purge transaction:", tx.Error)
+			return
+		}
+		if err := purge(tx, id); err != nil {
+			tx.Rollback()
+			log.Printf("Failed to purge %s %s: %v", name, id, err)
+			continue
+		}
+		if err := tx.Commit().Error; err != nil {
+			log.Printf("Failed to purge %s %s: %v", name, id, err)
+		}
+	}
+}
+
+func expiredTrash(model interface{}, key string, cutoff time.Time) []string {
+	var ids []string
+	if err := config.DB.Unscoped().Model(model).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck(key, &ids).Error; err != nil {
+		log.Println("Failed to load expired trash:", err)
+	}
+	return ids
+}
+
+// PurgeTrash menghapus permanen semua baris yang sudah melewati retensi
+func PurgeTrash() {
+	cutoff := time.Now().Add(-trashRetention())
+
+	purgeRows("feedback", expiredTrash(&models.Feedback{}, "feedback_id", cutoff), func(tx *gorm.DB, id string) error {
+		return tx.Unscoped().Delete(&models.Feedback{}, "feedback_id = ?", id).Error
+	})
+
+	purgeRows("child category", expiredTrash(&models.ChildEventCategory{}, "child_event_category_id", cutoff), func(tx *gorm.DB, id string) error {
+		return tx.Unscoped().Delete(&models.ChildEventCategory{}, "child_event_category_id = ?", id).Error
+	})
+
+	purgeRows("category", expiredTrash(&models.EventCategory{}, "event_category_id", cutoff), func(tx *gorm.DB, id string) error {
+		return tx.Unscoped().Delete(&models.EventCategory{}, "event_category_id = ?", id).Error
+	})
+
+	// event di trash tidak punya tiket maupun penjualan (DeleteEvent
+	// menolaknya), jadi cukup data konfigurasi event yang ikut dihapus
+	purgeRows("event", expiredTrash(&models.Event{}, "event_id", cutoff), func(tx *gorm.DB, id string) error {
+		categories := tx.Model(&models.TicketCategory{}).Select("ticket_category_id").Where("event_id = ?", id)
+		if err := tx.Where("ticket_category_id IN (?)", categories).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		sessions := tx.Model(&models.AgendaSession{}).Select("session_id").Where("event_id = ?", id)
+		if err := tx.Exec("DELETE FROM agenda_session_speakers WHERE session_id IN (?)", sessions).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&models.SessionBookmark{},
+			&models.AgendaSession{},
+			&models.Speaker{},
+			&models.EventSeat{},
+			&models.SeatSection{},
+			&models.RegistrationQuestion{},
+			&models.EventStaff{},
+			&models.EntrySession{},
+			&models.TicketImport{},
+			&models.EventRevision{},
+			&models.TicketCategory{},
+			&models.EventLike{},
+		} {
+			if err := tx.Where("event_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("entity_type = ? AND entity_id = ?", models.EntityEvent, id).Delete(&models.StatusHistory{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Event{}, "event_id = ?", id).Error
+	})
+
+	purgeRows("user", expiredTrash(&models.User{}, "user_id", cutoff), func(tx *gorm.DB, id string) error {
+		if err := tx.Where("owner_id = ?", id).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Event{}).Unscoped().
+			Where("event_id IN (?) AND total_likes > 0", tx.Model(&models.EventLike{}).Select("event_id").Where("user_id = ?", id)).
+			UpdateColumn("total_likes", gorm.Expr("total_likes - 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.EventLike{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("owner_id = ?", id).Delete(&models.Feedback{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.Notification{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, "user_id = ?", id).Error
+	})
+}
+
+// StartTrashPurge menjalankan purge saat start lalu sekali sehari
+func StartTrashPurge() {
+	go func() {
+		for {
+			PurgeTrash()
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+}
@@ -96,20 +96,22 @@ func UpdateProfile(c *fiber.Ctx) error {
 func GetUsers(c *fiber.Ctx) error {
 	role := c.Query("role")
 
-	var users []models.User
-	query := config.DB
+	query := config.DB.Model(&models.User{})
 
 	if role != "" {
 		query = query.Where("role = ?", role)
 	}
 
-	if err := query.Find(&users).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch users",
-		})
+	users, pagination, err := paginate(c, query, userListSpec, nil)
+	if err != nil {
+		return listError(c, err, "Failed to fetch users")
 	}
 
-	return c.JSON(users)
+	return c.JSON(fiber.Map{
+		"message":    "Users retrieved successfully",
+		"data":       users,
+		"pagination": pagination,
+	})
 }
 
 func GetUserByID(c *fiber.Ctx) error {
@@ -187,4 +189,33 @@ func HashPassword(password string) (string, error) {
 func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
-}
\ No newline at end of file
+}
+
+// DeleteUser - Hapus akun (soft delete). Akun tidak bisa login lagi sampai
+// dipulihkan admin dari trash.
+func DeleteUser(c *fiber.Ctx) error {
+	admin := c.Locals("user").(models.User)
+	userID := c.Params("id")
+
+	if userID == admin.UserID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You can not delete your own account",
+		})
+	}
+
+	deleted, err := softDelete(&models.User{}, admin.UserID, "user_id = ?", userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete user",
+		})
+	}
+	if deleted == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User deleted successfully",
+	})
+}
@@ -0,0 +1,704 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tsaniii18/Ticketing-Backend/config"
+	"github.com/Tsaniii18/Ticketing-Backend/models"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	walletApple  = "apple"
+	walletGoogle = "google"
+)
+
+// walletTicket - data tiket yang diisi ke pass wallet
+type walletTicket struct {
+	Ticket   models.Ticket
+	Category models.TicketCategory
+	Event    models.Event
+	Holder   string
+	Code     string
+}
+
+var (
+	apnsClient     *http.Client
+	apnsClientOnce sync.Once
+)
+
+// Pass dirotasi sedikit sebelum kodenya habis agar wallet sempat mengambil
+// versi baru
+const walletRotateAhead = 5 * time.Minute
+
+// loadWalletTicket menyiapkan data pass. QR pass memakai nonce milik pass
+// sendiri (terpisah dari kode di aplikasi) yang dirotasi tiap TTL kode tiket;
+// setiap rotasi didorong ke wallet lewat StartWalletRotation.
+func loadWalletTicket(ticket models.Ticket, pass models.WalletPass) (walletTicket, error) {
+	var category models.TicketCategory
+	if err := config.DB.First(&category, "ticket_category_id = ?", ticket.TicketCategoryID).Error; err != nil {
+		return walletTicket{}, err
+	}
+
+	var event models.Event
+	if err := config.DB.First(&event, "event_id = ?", ticket.EventID).Error; err != nil {
+		return walletTicket{}, err
+	}
+
+	expiresAt := checkInClosesAt(event)
+	if pass.BarcodeExpiresAt != nil && pass.BarcodeExpiresAt.Before(expiresAt) {
+		expiresAt = *pass.BarcodeExpiresAt
+	}
+	code, err := utils.SignTicketCode(ticket.TicketID, ticket.EventID, ticket.TicketCategoryID, pass.BarcodeNonce, expiresAt)
+	if err != nil {
+		return walletTicket{}, err
+	}
+
+	holder := ticketHolderName(ticket)
+
+	return walletTicket{
+		Ticket:   ticket,
+		Category: category,
+		Event:    event,
+		Holder:   holder,
+		Code:     code,
+	}, nil
+}
+
+// walletPassFor mengambil atau membuat catatan pass tiket untuk satu platform
+func walletPassFor(ticket models.Ticket, platform string) (models.WalletPass, error) {
+	var pass models.WalletPass
+	if err := config.DB.Where("ticket_id = ? AND platform = ?", ticket.TicketID, platform).First(&pass).Error; err == nil {
+		if pass.BarcodeNonce == "" || pass.BarcodeExpiresAt == nil || pass.BarcodeExpiresAt.Before(time.Now()) {
+			if err := rotateWalletPassCode(&pass); err != nil {
+				return pass, err
+			}
+		}
+		return pass, nil
+	}
+
+	pass = models.WalletPass{
+		WalletPassID: utils.GenerateWalletPassID(),
+		TicketID:     ticket.TicketID,
+		EventID:      ticket.EventID,
+		Platform:     platform,
+		SerialNumber: ticket.TicketID,
+		BarcodeNonce: utils.GeneratePassNonce(),
+	}
+	expiresAt := time.Now().Add(utils.TicketCodeTTL())
+	pass.BarcodeExpiresAt = &expiresAt
+	if platform == walletApple {
+		pass.AuthenticationToken = utils.GenerateWalletAuthToken()
+	}
+
+	if err := config.DB.Create(&pass).Error; err != nil {
+		return pass, err
+	}
+	return pass, nil
+}
+
+func buildApplePass(wt walletTicket, pass models.WalletPass) ([]byte, error) {
+	apple := config.AppleWallet
+
+	passJSON := map[string]interface{}{
+		"formatVersion":       1,
+		"passTypeIdentifier":  apple.PassTypeID,
+		"teamIdentifier":      apple.TeamID,
+		"serialNumber":        pass.SerialNumber,
+		"authenticationToken": pass.AuthenticationToken,
+		"organizationName":    apple.Organization,
+		"description":         "Ticket for " + wt.Event.Name,
+		"relevantDate":        wt.Event.DateStart.Format(time.RFC3339),
+		"backgroundColor":     "rgb(33, 37, 41)",
+		"foregroundColor":     "rgb(255, 255, 255)",
+		"labelColor":          "rgb(200, 200, 200)",
+		"voided":              wt.Ticket.Status != "active",
+		"barcodes": []map[string]string{{
+			"format":          "PKBarcodeFormatQR",
+			"message":         wt.Code,
+			"messageEncoding": "iso-8859-1",
+		}},
+		"eventTicket": map[string]interface{}{
+			"primaryFields": []map[string]string{
+				{"key": "event", "label": "EVENT", "value": wt.Event.Name},
+			},
+			"secondaryFields": []map[string]string{
+				{"key": "venue", "label": "VENUE", "value": wt.Event.Venue},
+				{"key": "category", "label": "CATEGORY", "value": wt.Category.Name},
+			},
+			"auxiliaryFields": []map[string]string{
+				{"key": "date", "label": "DATE", "value": wt.Event.DateStart.Format(time.RFC3339), "dateStyle": "PKDateStyleMedium", "timeStyle": "PKDateStyleShort"},
+				{"key": "holder", "label": "HOLDER", "value": wt.Holder},
+			},
+			"backFields": []map[string]string{
+				{"key": "location", "label": "Location", "value": wt.Event.Location},
+				{"key": "district", "label": "District", "value": wt.Event.District},
+				{"key": "ends", "label": "Ends", "value": wt.Event.DateEnd.Format("02 Jan 2006 15:04")},
+				{"key": "ticket", "label": "Ticket ID", "value": wt.Ticket.TicketID},
+			},
+		},
+	}
+	if wt.Ticket.SeatLabel != "" {
+		eventTicket := passJSON["eventTicket"].(map[string]interface{})
+		eventTicket["auxiliaryFields"] = append(eventTicket["auxiliaryFields"].([]map[string]string),
+			map[string]string{"key": "seat", "label": "SEAT", "value": wt.Ticket.SeatLabel})
+	}
+	if apple.WebServiceURL != "" {
+		passJSON["webServiceURL"] = apple.WebServiceURL
+	}
+
+	content, err := json.Marshal(passJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{"pass.json": content}
+
+	icon := utils.SolidPNG(58, color.RGBA{R: 33, G: 37, B: 41, A: 255})
+	files["icon.png"] = icon
+	files["icon@2x.png"] = icon
+	if wt.Event.Image != "" {
+		if thumbnail, err := utils.FetchImagePNG(wt.Event.Image, 180); err == nil {
+			files["thumbnail@2x.png"] = thumbnail
+		} else {
+			log.Printf("Failed to fetch event image for wallet pass %s: %v", pass.SerialNumber, err)
+		}
+	}
+
+	return utils.BuildPKPass(files, apple.Certificate, apple.PrivateKey, apple.WWDR)
+}
+
+func googleClassID(eventID string) string {
+	return config.GoogleWallet.IssuerID + "." + eventID
+}
+
+func googleObjectID(ticketID string) string {
+	return config.GoogleWallet.IssuerID + "." + ticketID
+}
+
+func localizedString(value string) map[string]interface{} {
+	return map[string]interface{}{
+		"defaultValue": map[string]string{"language": "en", "value": value},
+	}
+}
+
+func googleEventClass(event models.Event) map[string]interface{} {
+	class := map[string]interface{}{
+		"id":           googleClassID(event.EventID),
+		"issuerName":   "Ticketing",
+		"reviewStatus": "UNDER_REVIEW",
+		"eventName":    localizedString(event.Name),
+		"venue": map[string]interface{}{
+			"name":    localizedString(event.Venue),
+			"address": localizedString(event.Location + ", " + event.District),
+		},
+		"dateTime": map[string]string{
+			"start": event.DateStart.Format(time.RFC3339),
+			"end":   event.DateEnd.Format(time.RFC3339),
+		},
+	}
+	if event.Image != "" {
+		class["heroImage"] = map[string]interface{}{
+			"sourceUri": map[string]string{"uri": event.Image},
+		}
+	}
+	return class
+}
+
+func googleTicketObject(wt walletTicket) map[string]interface{} {
+	state := "ACTIVE"
+	if wt.Ticket.Status != "active" {
+		state = "INACTIVE"
+	}
+
+	object := map[string]interface{}{
+		"id":               googleObjectID(wt.Ticket.TicketID),
+		"classId":          googleClassID(wt.Event.EventID),
+		"state":            state,
+		"ticketHolderName": wt.Holder,
+		"ticketNumber":     wt.Ticket.TicketID,
+		"ticketType":       localizedString(wt.Category.Name),
+		"barcode": map[string]string{
+			"type":  "QR_CODE",
+			"value": wt.Code,
+		},
+	}
+	if wt.Ticket.SeatLabel != "" {
+		object["seatInfo"] = map[string]interface{}{
+			"seat": localizedString(wt.Ticket.SeatLabel),
+		}
+	}
+	return object
+}
+
+// GetAppleWalletPass - Unduh tiket sebagai .pkpass
+func GetAppleWalletPass(c *fiber.Ctx) error {
+	if config.AppleWallet == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Apple Wallet is not configured",
+		})
+	}
+
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if ticket.Status != "active" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Wallet pass is only available for active tickets",
+		})
+	}
+
+	pass, err := walletPassFor(ticket, walletApple)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to register wallet pass",
+		})
+	}
+
+	wt, err := loadWalletTicket(ticket, pass)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to prepare wallet pass: " + err.Error(),
+		})
+	}
+
+	pkpass, err := buildApplePass(wt, pass)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to sign wallet pass",
+		})
+	}
+
+	c.Set("Content-Type", "application/vnd.apple.pkpass")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pkpass", ticket.TicketID))
+	return c.Send(pkpass)
+}
+
+// GetGoogleWalletPass - Link "Save to Google Wallet" untuk tiket
+func GetGoogleWalletPass(c *fiber.Ctx) error {
+	google := config.GoogleWallet
+	if google == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Google Wallet is not configured",
+		})
+	}
+
+	ticket, err := loadOwnedTicket(c, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if ticket.Status != "active" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Wallet pass is only available for active tickets",
+		})
+	}
+
+	pass, err := walletPassFor(ticket, walletGoogle)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to register wallet pass",
+		})
+	}
+
+	wt, err := loadWalletTicket(ticket, pass)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to prepare wallet pass: " + err.Error(),
+		})
+	}
+
+	token, err := utils.SignGoogleWalletJWT(google.ClientEmail, google.PrivateKey, google.Origins, map[string]interface{}{
+		"eventTicketClasses": []interface{}{googleEventClass(wt.Event)},
+		"eventTicketObjects": []interface{}{googleTicketObject(wt)},
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to sign wallet pass",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Google Wallet pass generated successfully",
+		"jwt":      token,
+		"save_url": utils.GoogleWalletSaveURL + token,
+	})
+}
+
+// rotateWalletPassCode mengganti nonce QR pass. Nonce lama tetap diterima
+// sampai token yang sudah ada di wallet habis, sehingga scan tidak gagal selama
+// wallet mengambil pass baru.
+func rotateWalletPassCode(pass *models.WalletPass) error {
+	expiresAt := time.Now().Add(utils.TicketCodeTTL())
+	updates := map[string]interface{}{
+		"barcode_nonce":      utils.GeneratePassNonce(),
+		"prev_barcode_nonce": pass.BarcodeNonce,
+		"barcode_expires_at": expiresAt,
+		"updated_at":         time.Now(),
+	}
+	if err := config.DB.Model(&models.WalletPass{}).Where("wallet_pass_id = ?", pass.WalletPassID).Updates(updates).Error; err != nil {
+		return err
+	}
+	pass.PrevBarcodeNonce = pass.BarcodeNonce
+	pass.BarcodeNonce = updates["barcode_nonce"].(string)
+	pass.BarcodeExpiresAt = &expiresAt
+	return nil
+}
+
+// RotateWalletCodes merotasi kode pass tiket aktif yang hampir habis lalu
+// mendorong update ke Apple Wallet dan Google Wallet
+func RotateWalletCodes() {
+	if config.AppleWallet == nil && config.GoogleWallet == nil {
+		return
+	}
+
+	var passes []models.WalletPass
+	if err := config.DB.
+		Where("barcode_expires_at IS NULL OR barcode_expires_at < ?", time.Now().Add(walletRotateAhead)).
+		Where("ticket_id IN (?)", config.DB.Model(&models.Ticket{}).Select("ticket_id").Where("status = ?", "active")).
+		Find(&passes).Error; err != nil {
+		log.Println("Failed to load wallet passes to rotate:", err)
+		return
+	}
+
+	ticketIDs := make([]string, 0, len(passes))
+	for i := range passes {
+		if err := rotateWalletPassCode(&passes[i]); err != nil {
+			log.Printf("Failed to rotate wallet pass %s: %v", passes[i].WalletPassID, err)
+			continue
+		}
+		ticketIDs = append(ticketIDs, passes[i].TicketID)
+	}
+
+	notifyTicketWallets(ticketIDs...)
+}
+
+// StartWalletRotation menjalankan rotasi kode pass setiap menit
+func StartWalletRotation() {
+	go func() {
+		for {
+			RotateWalletCodes()
+			time.Sleep(time.Minute)
+		}
+	}()
+}
+
+// notifyTicketWallets menandai pass tiket berubah lalu mengirim push ke Apple
+// Wallet dan memperbarui object di Google Wallet. Dijalankan di goroutine.
+func notifyTicketWallets(ticketIDs ...string) {
+	if len(ticketIDs) == 0 || (config.AppleWallet == nil && config.GoogleWallet == nil) {
+		return
+	}
+
+	var passes []models.WalletPass
+	if err := config.DB.Where("ticket_id IN ?", ticketIDs).Find(&passes).Error; err != nil {
+		log.Println("Failed to load wallet passes:", err)
+		return
+	}
+	if len(passes) == 0 {
+		return
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&models.WalletPass{}).Where("ticket_id IN ?", ticketIDs).Update("updated_at", now).Error; err != nil {
+		log.Println("Failed to touch wallet passes:", err)
+	}
+
+	var appleSerials []string
+	var googleTickets []string
+	for _, pass := range passes {
+		switch pass.Platform {
+		case walletApple:
+			appleSerials = append(appleSerials, pass.SerialNumber)
+		case walletGoogle:
+			googleTickets = append(googleTickets, pass.TicketID)
+		}
+	}
+
+	pushApplePasses(appleSerials)
+	patchGoogleObjects(googleTickets)
+}
+
+// notifyEventWallets - detail event berubah, semua pass event diperbarui
+func notifyEventWallets(eventID string) {
+	if config.AppleWallet == nil && config.GoogleWallet == nil {
+		return
+	}
+
+	var ticketIDs []string
+	if err := config.DB.Model(&models.WalletPass{}).Where("event_id = ?", eventID).Distinct().Pluck("ticket_id", &ticketIDs).Error; err != nil {
+		log.Println("Failed to load wallet passes:", err)
+		return
+	}
+	if len(ticketIDs) == 0 {
+		return
+	}
+
+	if google := config.GoogleWallet; google != nil {
+		var event models.Event
+		if err := config.DB.First(&event, "event_id = ?", eventID).Error; err == nil {
+			token, err := utils.GoogleWalletAccessToken(google.ClientEmail, google.PrivateKey)
+			if err == nil {
+				err = utils.PatchGoogleWalletResource(token, "eventTicketClass", googleClassID(eventID), googleEventClass(event))
+			}
+			if err != nil {
+				log.Printf("Failed to update Google Wallet class for event %s: %v", eventID, err)
+			}
+		}
+	}
+
+	notifyTicketWallets(ticketIDs...)
+}
+
+func pushApplePasses(serials []string) {
+	apple := config.AppleWallet
+	if apple == nil || len(serials) == 0 {
+		return
+	}
+
+	apnsClientOnce.Do(func() {
+		client, err := utils.NewAPNsClient(apple.CertificatePEM, apple.PrivateKeyPEM)
+		if err != nil {
+			log.Println("Failed to create APNs client:", err)
+			return
+		}
+		apnsClient = client
+	})
+	if apnsClient == nil {
+		return
+	}
+
+	var devices []models.WalletDevice
+	if err := config.DB.Where("pass_type_id = ? AND serial_number IN ?", apple.PassTypeID, serials).Find(&devices).Error; err != nil {
+		log.Println("Failed to load wallet devices:", err)
+		return
+	}
+
+	// satu push per push token cukup, Wallet mengambil semua pass yang berubah
+	pushed := make(map[string]bool)
+	for _, device := range devices {
+		if pushed[device.PushToken] {
+			continue
+		}
+		pushed[device.PushToken] = true
+		if err := utils.PushPassUpdate(apnsClient, apple.APNsHost, apple.PassTypeID, device.PushToken); err != nil {
+			log.Printf("Failed to push wallet update to device %s: %v", device.DeviceLibraryID, err)
+		}
+	}
+}
+
+func patchGoogleObjects(ticketIDs []string) {
+	google := config.GoogleWallet
+	if google == nil || len(ticketIDs) == 0 {
+		return
+	}
+
+	token, err := utils.GoogleWalletAccessToken(google.ClientEmail, google.PrivateKey)
+	if err != nil {
+		log.Println("Failed to get Google Wallet access token:", err)
+		return
+	}
+
+	for _, ticketID := range ticketIDs {
+		var ticket models.Ticket
+		if err := config.DB.Preload("Owner").First(&ticket, "ticket_id = ?", ticketID).Error; err != nil {
+			continue
+		}
+
+		pass, err := walletPassFor(ticket, walletGoogle)
+		if err != nil {
+			continue
+		}
+
+		wt, err := loadWalletTicket(ticket, pass)
+		if err != nil {
+			log.Printf("Failed to prepare Google Wallet object for ticket %s: %v", ticketID, err)
+			continue
+		}
+
+		if err := utils.PatchGoogleWalletResource(token, "eventTicketObject", googleObjectID(ticketID), googleTicketObject(wt)); err != nil {
+			log.Printf("Failed to update Google Wallet object for ticket %s: %v", ticketID, err)
+		}
+	}
+}
+
+// appleWalletAuth memvalidasi header "Authorization: ApplePass <token>" untuk serial
+func appleWalletAuth(c *fiber.Ctx, passTypeID string, serial string) (models.WalletPass, bool) {
+	var pass models.WalletPass
+	if config.AppleWallet == nil || passTypeID != config.AppleWallet.PassTypeID {
+		return pass, false
+	}
+
+	token := strings.TrimPrefix(c.Get("Authorization"), "ApplePass ")
+	if err := config.DB.Where("serial_number = ? AND platform = ?", serial, walletApple).First(&pass).Error; err != nil {
+		return pass, false
+	}
+	return pass, token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(pass.AuthenticationToken)) == 1
+}
+
+// RegisterWalletDevice - Web service Apple: perangkat mendaftar untuk update pass
+func RegisterWalletDevice(c *fiber.Ctx) error {
+	passTypeID := c.Params("pass_type_id")
+	serial := c.Params("serial")
+	if _, ok := appleWalletAuth(c, passTypeID, serial); !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var req struct {
+		PushToken string `json:"pushToken"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.PushToken == "" {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var device models.WalletDevice
+	err := config.DB.Where("device_library_id = ? AND pass_type_id = ? AND serial_number = ?", c.Params("device_id"), passTypeID, serial).First(&device).Error
+	if err == nil {
+		if device.PushToken != req.PushToken {
+			config.DB.Model(&device).Update("push_token", req.PushToken)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	}
+
+	device = models.WalletDevice{
+		WalletDeviceID:  utils.GenerateWalletDeviceID(),
+		DeviceLibraryID: c.Params("device_id"),
+		PassTypeID:      passTypeID,
+		SerialNumber:    serial,
+		PushToken:       req.PushToken,
+	}
+	if err := config.DB.Create(&device).Error; err != nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// UnregisterWalletDevice - Web service Apple: pass dihapus dari perangkat
+func UnregisterWalletDevice(c *fiber.Ctx) error {
+	passTypeID := c.Params("pass_type_id")
+	serial := c.Params("serial")
+	if _, ok := appleWalletAuth(c, passTypeID, serial); !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := config.DB.Where("device_library_id = ? AND pass_type_id = ? AND serial_number = ?", c.Params("device_id"), passTypeID, serial).
+		Delete(&models.WalletDevice{}).Error; err != nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// GetUpdatedWalletSerials - Web service Apple: serial pass yang berubah sejak tag
+func GetUpdatedWalletSerials(c *fiber.Ctx) error {
+	passTypeID := c.Params("pass_type_id")
+	if config.AppleWallet == nil || passTypeID != config.AppleWallet.PassTypeID {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	var serials []string
+	if err := config.DB.Model(&models.WalletDevice{}).
+		Where("device_library_id = ? AND pass_type_id = ?", c.Params("device_id"), passTypeID).
+		Pluck("serial_number", &serials).Error; err != nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if len(serials) == 0 {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	query := config.DB.Where("platform = ? AND serial_number IN ?", walletApple, serials)
+	if since := c.Query("passesUpdatedSince"); since != "" {
+		if unix, err := strconv.ParseInt(since, 10, 64); err == nil {
+			query = query.Where("updated_at > ?", time.Unix(unix, 0))
+		}
+	}
+
+	var passes []models.WalletPass
+	if err := query.Find(&passes).Error; err != nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if len(passes) == 0 {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+
+	updated := make([]string, 0, len(passes))
+	var lastUpdated time.Time
+	for _, pass := range passes {
+		updated = append(updated, pass.SerialNumber)
+		if pass.UpdatedAt.After(lastUpdated) {
+			lastUpdated = pass.UpdatedAt
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"serialNumbers": updated,
+		"lastUpdated":   strconv.FormatInt(lastUpdated.Unix(), 10),
+	})
+}
+
+// GetLatestWalletPass - Web service Apple: versi terbaru pass
+func GetLatestWalletPass(c *fiber.Ctx) error {
+	pass, ok := appleWalletAuth(c, c.Params("pass_type_id"), c.Params("serial"))
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var ticket models.Ticket
+	err := config.DB.Preload("Owner").First(&ticket, "ticket_id = ?", pass.TicketID).Error
+	if err != nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	// kode pass tiket aktif yang sudah habis dirotasi saat diambil
+	if ticket.Status == "active" && (pass.BarcodeExpiresAt == nil || pass.BarcodeExpiresAt.Before(time.Now())) {
+		if err := rotateWalletPassCode(&pass); err != nil {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		pass.UpdatedAt = time.Now()
+	}
+
+	if ims := c.Get("If-Modified-Since"); ims != "" {
+		if since, err := http.ParseTime(ims); err == nil && !pass.UpdatedAt.Truncate(time.Second).After(since) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	}
+
+	// tiket yang sudah tidak aktif ditampilkan void oleh buildApplePass
+	wt, err := loadWalletTicket(ticket, pass)
+	if err != nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	pkpass, err := buildApplePass(wt, pass)
+	if err != nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	c.Set("Content-Type", "application/vnd.apple.pkpass")
+	c.Set("Last-Modified", pass.UpdatedAt.UTC().Format(http.TimeFormat))
+	return c.Send(pkpass)
+}
+
+// LogWalletErrors - Web service Apple: log error dari perangkat
+func LogWalletErrors(c *fiber.Ctx) error {
+	var req struct {
+		Logs []string `json:"logs"`
+	}
+	if err := c.BodyParser(&req); err == nil {
+		for _, message := range req.Logs {
+			log.Println("Apple Wallet:", message)
+		}
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"os"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/Tsaniii18/Ticketing-Backend/handlers"
 	"github.com/Tsaniii18/Ticketing-Backend/models"
 	"github.com/Tsaniii18/Ticketing-Backend/routes"
+	"github.com/Tsaniii18/Ticketing-Backend/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -15,18 +17,37 @@ import (
 )
 
 func main() {
+	if err := utils.CheckTicketSigningSecret(); err != nil {
+		log.Fatal("Invalid ticket signing config:", err)
+	}
+
 	// Connect to database
 	config.ConnectDatabase()
 
 	// Initialize Cloudinary
 	config.InitCloudinary()
 
+	// Redis opsional untuk live dashboard multi-instance
+	config.InitRedis()
+	handlers.StartLiveRelay()
+
+	// Apple/Google Wallet opsional, aktif jika sertifikat dikonfigurasi
+	config.InitWallet()
+
+	// SMTP opsional untuk notifikasi email
+	config.InitSMTP()
+
+	// Geocoder opsional untuk koordinat venue (GEOCODER=stub|nominatim)
+	config.InitGeocoder()
+
 	err := migrateDatabase(config.DB)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: jsonErrorHandler,
+	})
 
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
@@ -48,6 +69,13 @@ func main() {
 		log.Fatal("Failed to start event_auto_status goroutine:", err)
 	}
 
+	handlers.StartWalletRotation()
+
+	handlers.ResumePendingRefunds()
+
+	// Purge trash (soft delete) yang melewati TRASH_RETENTION_DAYS
+	handlers.StartTrashPurge()
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = ":3000" // default untuk local & Docker
@@ -57,6 +85,23 @@ func main() {
 	log.Fatal(app.Listen(port))
 }
 
+// jsonErrorHandler - error yang dikembalikan handler (mis. fiber.NewError dari
+// helper) dikirim dengan format {"error": ...} seperti response lain. Pesan
+// error lain (GORM, driver) hanya dicatat di log, tidak dikirim ke client.
+func jsonErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(fiber.Map{
+			"error": fiberErr.Message,
+		})
+	}
+
+	log.Printf("Unhandled error on %s %s: %v", c.Method(), c.Path(), err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Internal server error",
+	})
+}
+
 func migrateDatabase(db *gorm.DB) error {
 	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
 
@@ -65,6 +110,9 @@ func migrateDatabase(db *gorm.DB) error {
 		return err
 	}
 
+	// min_price event lama dihitung sekali saat kolomnya baru dibuat
+	backfillMinPrice := db.Migrator().HasTable(&models.Event{}) && !db.Migrator().HasColumn(&models.Event{}, "MinPrice")
+
 	err = db.AutoMigrate(&models.Event{})
 	if err != nil {
 		return err
@@ -115,6 +163,117 @@ func migrateDatabase(db *gorm.DB) error {
 		return err
 	}
 
+	err = db.AutoMigrate(&models.OfflineScan{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.TicketCodeHistory{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.EventStaff{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.CheckInLog{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.EntrySession{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.TicketEntry{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.WalletPass{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.WalletDevice{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.RegistrationQuestion{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.TicketAnswer{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.StatusHistory{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.TicketImport{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.EventSeries{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.Speaker{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.AgendaSession{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.SessionBookmark{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.SeatSection{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.EventSeat{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.EventRevision{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.Refund{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&models.Notification{})
+	if err != nil {
+		return err
+	}
+
+	if backfillMinPrice {
+		if err := handlers.BackfillEventMinPrice(db); err != nil {
+			return err
+		}
+	}
+
 	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
 
 	log.Println("Database migrated successfully")
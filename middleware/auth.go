@@ -88,3 +88,23 @@ func OrganizerMiddleware(c *fiber.Ctx) error {
 	}
 	return c.Next()
 }
+
+// NonStaffMiddleware - akun gate staff hanya untuk check-in, tidak boleh melihat data penjualan
+func NonStaffMiddleware(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)
+	if user.Role == "staff" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Staff accounts can only access check-in features",
+		})
+	}
+	return c.Next()
+}
+
+// QueryTokenMiddleware - EventSource di browser tidak bisa mengirim header
+// Authorization, jadi token boleh dikirim lewat query ?token=
+func QueryTokenMiddleware(c *fiber.Ctx) error {
+	if c.Get("Authorization") == "" && c.Query("token") != "" {
+		c.Request().Header.Set("Authorization", "Bearer "+c.Query("token"))
+	}
+	return c.Next()
+}
@@ -2,26 +2,32 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
-	UserID                  string    `gorm:"primaryKey;type:char(60)" json:"user_id"`
-	Username                string    `gorm:"uniqueIndex;size:50" json:"username"`
-	Name                    string    `gorm:"size:100" json:"name"`
-	Email                   string    `gorm:"uniqueIndex;size:100" json:"email"`
-	Password                string    `gorm:"size:255" json:"-"`
-	Role                    string    `gorm:"size:20;default:user" json:"role"`
-	ProfilePict             string    `gorm:"size:255" json:"profile_pict"`
-	Organization            string    `gorm:"size:100" json:"organization"`
-	OrganizationType        string    `gorm:"size:50" json:"organization_type"`
-	OrganizationDescription string    `gorm:"type:text" json:"organization_description"`
-	KTP                     string    `gorm:"size:255" json:"ktp"`
-	RegisterStatus          string    `gorm:"size:20;default:pending" json:"register_status"`
-	RegisterComment         string    `gorm:"type:text" json:"register_comment"`
-	AccessToken             string    `gorm:"size:500" json:"-"`
-	RefreshToken            string    `gorm:"size:500" json:"-"`
-	CreatedAt               time.Time `json:"created_at"`
-	UpdatedAt               time.Time `json:"updated_at"`
+	UserID                  string         `gorm:"primaryKey;type:char(60)" json:"user_id"`
+	Username                string         `gorm:"uniqueIndex;size:50" json:"username"`
+	Name                    string         `gorm:"size:100" json:"name"`
+	Email                   string         `gorm:"uniqueIndex;size:100" json:"email"`
+	Password                string         `gorm:"size:255" json:"-"`
+	Role                    string         `gorm:"size:20;default:user" json:"role"`
+	ProfilePict             string         `gorm:"size:255" json:"profile_pict"`
+	Organization            string         `gorm:"size:100" json:"organization"`
+	OrganizationType        string         `gorm:"size:50" json:"organization_type"`
+	OrganizationDescription string         `gorm:"type:text" json:"organization_description"`
+	KTP                     string         `gorm:"size:255" json:"ktp"`
+	RegisterStatus          string         `gorm:"size:20;default:pending" json:"register_status"`
+	RegisterComment         string         `gorm:"type:text" json:"register_comment"`
+	AccessToken             string         `gorm:"size:500" json:"-"`
+	RefreshToken            string         `gorm:"size:500" json:"-"`
+	CalendarToken           *string        `gorm:"size:64;uniqueIndex" json:"-"`
+	CreatedAt               time.Time      `json:"created_at"`
+	UpdatedAt               time.Time      `json:"updated_at"`
+	CreatedBy               string         `gorm:"type:char(60);index" json:"created_by,omitempty"` // organizer pembuat akun staff
+	DeletedAt               gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedBy               string         `gorm:"type:char(60)" json:"deleted_by,omitempty"`
 
 	// Relationships
 	Events               []Event              `gorm:"foreignKey:OwnerID" json:"events,omitempty"`
@@ -33,28 +39,39 @@ type User struct {
 }
 
 type Event struct {
-	EventID          string    `gorm:"primaryKey;type:char(60)" json:"event_id"`
-	Name             string    `gorm:"size:100" json:"name"`
-	OwnerID          string    `gorm:"type:char(60);not null" json:"owner_id"`
-	Status           string    `gorm:"size:20;default:pending" json:"status"`
-	ApprovalComment  string    `gorm:"type:text" json:"approval_comment"`
-	DateStart        time.Time `json:"date_start"`
-	DateEnd          time.Time `json:"date_end"`
-	Location         string    `gorm:"size:255" json:"location"`
-	Venue            string    `gorm:"size:100" json:"venue"`
-	District         string    `gorm:"size:100" json:"district"`
-	Description      string    `gorm:"type:text" json:"description"`
-	Rules            string    `gorm:"type:text" json:"rules"`
-	Image            string    `gorm:"size:255" json:"image"`
-	Flyer            string    `gorm:"size:255" json:"flyer"`
-	Category         string    `gorm:"size:50" json:"category"`
-	ChildCategory    string    `gorm:"size:50" json:"child_category"`
-	TotalAttendant   uint      `gorm:"default:0" json:"total_attendant"`
-	TotalLikes       uint      `gorm:"default:0" json:"total_likes"`
-	TotalSales       float64   `gorm:"type:decimal(10,2);default:0" json:"total_sales"`
-	TotalTicketsSold uint      `gorm:"default:0" json:"total_tickets_sold"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	EventID            string         `gorm:"primaryKey;type:char(60)" json:"event_id"`
+	Name               string         `gorm:"size:100;index:idx_event_search,class:FULLTEXT" json:"name"`
+	OwnerID            string         `gorm:"type:char(60);not null" json:"owner_id"`
+	Status             string         `gorm:"size:20;default:pending" json:"status"`
+	ApprovalComment    string         `gorm:"type:text" json:"approval_comment"`
+	DateStart          time.Time      `gorm:"index" json:"date_start"`
+	DateEnd            time.Time      `json:"date_end"`
+	Location           string         `gorm:"size:255" json:"location"`
+	Venue              string         `gorm:"size:100;index:idx_event_search,class:FULLTEXT" json:"venue"`
+	District           string         `gorm:"size:100;index:idx_event_search,class:FULLTEXT" json:"district"`
+	Latitude           *float64       `gorm:"index:idx_event_coordinates" json:"latitude"`
+	Longitude          *float64       `gorm:"index:idx_event_coordinates" json:"longitude"`
+	Description        string         `gorm:"type:text;index:idx_event_search,class:FULLTEXT" json:"description"`
+	Rules              string         `gorm:"type:text" json:"rules"`
+	Image              string         `gorm:"size:255" json:"image"`
+	Flyer              string         `gorm:"size:255" json:"flyer"`
+	Category           string         `gorm:"size:50" json:"category"`
+	ChildCategory      string         `gorm:"size:50" json:"child_category"`
+	TotalAttendant     uint           `gorm:"default:0" json:"total_attendant"`
+	TotalLikes         uint           `gorm:"default:0;index" json:"total_likes"`
+	TotalSales         float64        `gorm:"type:decimal(10,2);default:0" json:"total_sales"`
+	TotalTicketsSold   uint           `gorm:"default:0" json:"total_tickets_sold"`
+	MinPrice           float64        `gorm:"type:decimal(10,2);default:0;index" json:"min_price"`
+	SeriesID           string         `gorm:"type:char(60);index" json:"series_id,omitempty"`
+	OccurrenceStart    *time.Time     `json:"occurrence_start,omitempty"`
+	PublishAt          *time.Time     `gorm:"index" json:"publish_at"`
+	RefundableUntil    *time.Time     `json:"refundable_until"`                       // batas refund setelah event ditunda lalu dijadwalkan ulang
+	CheckInGraceBefore uint           `gorm:"default:0" json:"check_in_grace_before"` // menit
+	CheckInGraceAfter  uint           `gorm:"default:0" json:"check_in_grace_after"`  // menit
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedBy          string         `gorm:"type:char(60)" json:"deleted_by,omitempty"`
 
 	// Relationships
 	Owner            User             `gorm:"foreignKey:OwnerID;references:UserID" json:"owner"`
@@ -76,6 +93,11 @@ type TicketCategory struct {
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 	Attendant        uint      `gorm:"default:0" json:"attendant"`
+	EntryMode        string    `gorm:"size:20;default:single" json:"entry_mode"`
+	MaxEntries       uint      `json:"max_entries"`
+	Imported         uint      `gorm:"default:0" json:"imported"`
+	Seated           bool      `gorm:"default:false" json:"seated"`
+	Archived         bool      `gorm:"default:false" json:"archived"`
 
 	// Relationships
 	Tickets            []Ticket            `gorm:"foreignKey:TicketCategoryID" json:"tickets,omitempty"`
@@ -84,16 +106,28 @@ type TicketCategory struct {
 }
 
 type Ticket struct {
-	TicketID         string    `gorm:"primaryKey;type:char(60)" json:"ticket_id"`
-	EventID          string    `gorm:"type:char(60);not null" json:"event_id"`
-	TicketCategoryID string    `gorm:"type:char(60);not null" json:"ticket_category_id"`
-	OwnerID          string    `gorm:"type:char(60);not null" json:"owner_id"`
-	Status           string    `gorm:"size:20;default:active" json:"status"`
-	Code             string    `gorm:"size:100;uniqueIndex" json:"code"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
-	ExpiresAt        time.Time `json:"expires_at"`
-	Tag              string    `gorm:"size:100" json:"tag" default:"My Ticket"`
+	TicketID         string     `gorm:"primaryKey;type:char(60)" json:"ticket_id"`
+	EventID          string     `gorm:"type:char(60);not null;uniqueIndex:idx_ticket_external_ref" json:"event_id"`
+	TicketCategoryID string     `gorm:"type:char(60);not null" json:"ticket_category_id"`
+	OwnerID          string     `gorm:"type:char(60);default:null" json:"owner_id"` // kosong untuk tiket impor yang belum diklaim
+	TransactionID    string     `gorm:"type:char(60);index" json:"transaction_id"`
+	Status           string     `gorm:"size:20;default:active" json:"status"`
+	Code             string     `gorm:"size:100;uniqueIndex" json:"-"` // nonce rotasi, tidak pernah dikirim mentah
+	PrintNonce       string     `gorm:"size:100" json:"-"`             // nonce QR e-ticket PDF terakhir
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	UsedAt           *time.Time `json:"used_at"`
+	InsideVenue      bool       `gorm:"default:false" json:"inside_venue"`
+	Tag              string     `gorm:"size:100" json:"tag" default:"My Ticket"`
+	Source           string     `gorm:"size:20;default:platform" json:"source"`
+	ExternalRef      *string    `gorm:"size:100;uniqueIndex:idx_ticket_external_ref" json:"external_ref,omitempty"`
+	TicketImportID   string     `gorm:"type:char(60);index" json:"ticket_import_id,omitempty"`
+	SeatID           string     `gorm:"type:char(60);index" json:"seat_id,omitempty"`
+	SeatLabel        string     `gorm:"size:150" json:"seat_label,omitempty"`
+	HolderName       string     `gorm:"size:100" json:"holder_name,omitempty"`
+	HolderEmail      string     `gorm:"size:100" json:"holder_email,omitempty"`
+	ClaimCode        *string    `gorm:"size:100;uniqueIndex" json:"claim_code,omitempty"` // hanya untuk tiket impor yang belum diklaim
 
 	// Relationships
 	Owner User `gorm:"foreignKey:OwnerID" json:"owner"`
@@ -105,6 +139,7 @@ type Cart struct {
 	OwnerID          string    `gorm:"type:char(60);not null" json:"owner_id"`
 	Quantity         uint      `gorm:"default:1" json:"quantity"`
 	PriceTotal       float64   `gorm:"type:decimal(10,2)" json:"price_total"`
+	SeatIDs          []string  `gorm:"serializer:json;type:text" json:"seat_ids,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 
@@ -147,31 +182,431 @@ type EventLike struct {
 }
 
 type Feedback struct {
-	FeedbackID       string    `gorm:"primaryKey;type:char(60);not null" json:"feedback_id"`
-	OwnerID          string    `gorm:"column:owner_id;type:char(60);not null" json:"owner_id"`
-	FeedbackCategory string    `gorm:"type:char(40);not null" json:"feedback_category"`
-	Status           string    `gorm:"size:20;default:active" json:"status"`
-	Comment          string    `gorm:"type:text" json:"comment"`
-	Image            string    `gorm:"size:255" json:"image"`
-	Reply            string    `gorm:"type:text" json:"reply"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	FeedbackID       string         `gorm:"primaryKey;type:char(60);not null" json:"feedback_id"`
+	OwnerID          string         `gorm:"column:owner_id;type:char(60);not null" json:"owner_id"`
+	FeedbackCategory string         `gorm:"type:char(40);not null" json:"feedback_category"`
+	Status           string         `gorm:"size:20;default:active" json:"status"`
+	Comment          string         `gorm:"type:text" json:"comment"`
+	Image            string         `gorm:"size:255" json:"image"`
+	Reply            string         `gorm:"type:text" json:"reply"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedBy        string         `gorm:"type:char(60)" json:"deleted_by,omitempty"`
 
 	User User `gorm:"foreignKey:OwnerID;reference:UserID" json:"user,omitempty"`
 }
 
 type EventCategory struct {
-	EventCategoryID   string `gorm:"primaryKey;type:char(60);not null" json:"event_category_id"`
-	EventCategoryName string `gorm:"size:50;default:active" json:"event_category_name"`
+	EventCategoryID   string         `gorm:"primaryKey;type:char(60);not null" json:"event_category_id"`
+	EventCategoryName string         `gorm:"size:50;default:active" json:"event_category_name"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedBy         string         `gorm:"type:char(60)" json:"deleted_by,omitempty"`
 
 	ChildEventCategory []ChildEventCategory `gorm:"foreignKey:ParentCategoryID;reference:EventCategoryID" json:"child_event_category,omitempty"`
 }
 
 type ChildEventCategory struct {
-	ChildEventCategoryID   string `gorm:"primaryKey;type:char(60);not null" json:"child_event_category_id"`
-	ParentCategoryID       string `gorm:";type:char(60);not null" json:"event_category_id"`
-	ParentCategoryName     string `gorm:";type:char(60);not null" json:"event_category_name"`
-	ChildEventCategoryName string `gorm:"size:50;default:active" json:"child_event_category_name"`
+	ChildEventCategoryID   string         `gorm:"primaryKey;type:char(60);not null" json:"child_event_category_id"`
+	ParentCategoryID       string         `gorm:";type:char(60);not null" json:"event_category_id"`
+	ParentCategoryName     string         `gorm:";type:char(60);not null" json:"event_category_name"`
+	ChildEventCategoryName string         `gorm:"size:50;default:active" json:"child_event_category_name"`
+	DeletedAt              gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedBy              string         `gorm:"type:char(60)" json:"deleted_by,omitempty"`
 
 	// Parent EventCategory `gorm:"foreignKey:ParentCategoryID;reference:EventCategoryID" json:"parent"`
 }
+
+type OfflineScan struct {
+	OfflineScanID string    `gorm:"primaryKey;type:char(60)" json:"offline_scan_id"`
+	EventID       string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	TicketID      string    `gorm:"type:char(60);index" json:"ticket_id"`
+	DeviceID      string    `gorm:"size:100;not null;uniqueIndex:idx_offline_scan_device" json:"device_id"`
+	ScanID        string    `gorm:"size:100;not null;uniqueIndex:idx_offline_scan_device" json:"scan_id"`
+	ScannerID     string    `gorm:"type:char(60)" json:"scanner_id"`
+	Code          string    `gorm:"size:500" json:"code"`
+	ScannedAt     time.Time `json:"scanned_at"`
+	Result        string    `gorm:"size:20" json:"result"`
+	Reason        string    `gorm:"size:255" json:"reason"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TicketCodeHistory - nonce rotasi tiket yang sudah diganti. Scan offline
+// dengan nonce ini hanya sah jika dipindai sebelum SupersededAt.
+type TicketCodeHistory struct {
+	TicketCodeHistoryID string    `gorm:"primaryKey;type:char(60)" json:"ticket_code_history_id"`
+	TicketID            string    `gorm:"type:char(60);not null;index:idx_ticket_code_history" json:"ticket_id"`
+	Code                string    `gorm:"size:100;not null;index:idx_ticket_code_history" json:"-"`
+	SupersededAt        time.Time `json:"superseded_at"`
+}
+
+type EventStaff struct {
+	EventStaffID      string     `gorm:"primaryKey;type:char(60)" json:"event_staff_id"`
+	EventID           string     `gorm:"type:char(60);not null;uniqueIndex:idx_event_staff_user" json:"event_id"`
+	UserID            string     `gorm:"type:char(60);not null;uniqueIndex:idx_event_staff_user" json:"user_id"`
+	InvitedBy         string     `gorm:"type:char(60)" json:"invited_by"`
+	TicketCategoryIDs []string   `gorm:"type:text;serializer:json" json:"ticket_category_ids"`
+	ValidFrom         *time.Time `json:"valid_from"`
+	ValidUntil        *time.Time `json:"valid_until"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	User  User  `gorm:"foreignKey:UserID;references:UserID" json:"user"`
+	Event Event `gorm:"foreignKey:EventID;references:EventID" json:"event"`
+}
+
+// Outcome hasil scan check-in
+const (
+	CheckInSuccess       = "success"
+	CheckInAlreadyUsed   = "already_used"
+	CheckInNotStarted    = "not_started"
+	CheckInExpired       = "expired"
+	CheckInInvalid       = "invalid"
+	CheckInUnauthorized  = "unauthorized"
+	CheckInAlreadyInside = "already_inside"
+	CheckInOutsideWindow = "outside_validity_window"
+	CheckOutSuccess      = "checked_out"
+)
+
+// Entry mode kategori tiket
+const (
+	EntryModeSingle   = "single"   // sekali masuk
+	EntryModeMultiple = "multiple" // keluar-masuk sampai MaxEntries (0 = tanpa batas)
+	EntryModeDaily    = "daily"    // satu kali masuk per hari event
+	EntryModeSession  = "session"  // satu kali masuk per sesi (EntrySession)
+)
+
+type EntrySession struct {
+	EntrySessionID    string    `gorm:"primaryKey;type:char(60)" json:"entry_session_id"`
+	EventID           string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	Name              string    `gorm:"size:100" json:"name"`
+	StartsAt          time.Time `json:"starts_at"`
+	EndsAt            time.Time `json:"ends_at"`
+	TicketCategoryIDs []string  `gorm:"serializer:json;type:text" json:"ticket_category_ids"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// TicketEntry - satu hak masuk yang sudah dipakai (entry ke-n, hari, atau sesi)
+type TicketEntry struct {
+	TicketEntryID    string     `gorm:"primaryKey;type:char(60)" json:"ticket_entry_id"`
+	TicketID         string     `gorm:"type:char(60);not null;uniqueIndex:idx_ticket_entry_entitlement" json:"ticket_id"`
+	EventID          string     `gorm:"type:char(60);not null;index:idx_ticket_entry_event_date" json:"event_id"`
+	TicketCategoryID string     `gorm:"type:char(60)" json:"ticket_category_id"`
+	Entitlement      string     `gorm:"size:100;not null;uniqueIndex:idx_ticket_entry_entitlement" json:"entitlement"`
+	EntryDate        string     `gorm:"size:10;index:idx_ticket_entry_event_date" json:"entry_date"`
+	CheckedInAt      time.Time  `json:"checked_in_at"`
+	CheckedOutAt     *time.Time `json:"checked_out_at"`
+	ScannerID        string     `gorm:"type:char(60)" json:"scanner_id"`
+	Gate             string     `gorm:"size:100" json:"gate"`
+	OfflineScanID    string     `gorm:"type:char(60)" json:"offline_scan_id,omitempty"` // diisi jika entry berasal dari sinkronisasi offline
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type CheckInLog struct {
+	CheckInLogID     string    `gorm:"primaryKey;type:char(60)" json:"check_in_log_id"`
+	EventID          string    `gorm:"type:char(60);not null;index:idx_check_in_log_event_time" json:"event_id"`
+	TicketID         string    `gorm:"type:char(60);index" json:"ticket_id"`
+	TicketCategoryID string    `gorm:"type:char(60)" json:"ticket_category_id"`
+	ScannerID        string    `gorm:"type:char(60);index" json:"scanner_id"`
+	DeviceID         string    `gorm:"size:100" json:"device_id"`
+	Gate             string    `gorm:"size:100;index" json:"gate"`
+	Code             string    `gorm:"size:500" json:"code"`
+	Outcome          string    `gorm:"size:30;index" json:"outcome"`
+	Message          string    `gorm:"size:255" json:"message"`
+	Source           string    `gorm:"size:20;default:online" json:"source"`
+	ScannedAt        time.Time `gorm:"index:idx_check_in_log_event_time" json:"scanned_at"`
+	CreatedAt        time.Time `json:"created_at"`
+
+	Scanner User `gorm:"foreignKey:ScannerID;references:UserID" json:"scanner"`
+}
+
+// WalletPass - pass Apple/Google Wallet yang pernah diterbitkan untuk tiket.
+// UpdatedAt dipakai sebagai tag "lastUpdated" web service Apple.
+type WalletPass struct {
+	WalletPassID        string     `gorm:"primaryKey;type:char(60)" json:"wallet_pass_id"`
+	TicketID            string     `gorm:"type:char(60);not null;uniqueIndex:idx_wallet_pass_ticket" json:"ticket_id"`
+	EventID             string     `gorm:"type:char(60);not null;index" json:"event_id"`
+	Platform            string     `gorm:"size:10;not null;uniqueIndex:idx_wallet_pass_ticket" json:"platform"`
+	SerialNumber        string     `gorm:"size:100;index" json:"serial_number"`
+	AuthenticationToken string     `gorm:"size:100" json:"-"`
+	BarcodeNonce        string     `gorm:"size:100" json:"-"` // nonce QR pass, dirotasi seperti kode tiket
+	PrevBarcodeNonce    string     `gorm:"size:100" json:"-"` // nonce sebelumnya, berlaku sampai token lamanya habis
+	BarcodeExpiresAt    *time.Time `gorm:"index" json:"-"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// WalletDevice - registrasi perangkat Apple Wallet untuk push update pass
+type WalletDevice struct {
+	WalletDeviceID  string    `gorm:"primaryKey;type:char(60)" json:"wallet_device_id"`
+	DeviceLibraryID string    `gorm:"size:100;not null;uniqueIndex:idx_wallet_device_pass" json:"device_library_id"`
+	PassTypeID      string    `gorm:"size:100;not null;uniqueIndex:idx_wallet_device_pass" json:"pass_type_id"`
+	SerialNumber    string    `gorm:"size:100;not null;uniqueIndex:idx_wallet_device_pass" json:"serial_number"`
+	PushToken       string    `gorm:"size:255" json:"push_token"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Tipe field pertanyaan registrasi
+const (
+	FieldText        = "text"
+	FieldTextarea    = "textarea"
+	FieldNumber      = "number"
+	FieldEmail       = "email"
+	FieldPhone       = "phone"
+	FieldDate        = "date"
+	FieldSelect      = "select"
+	FieldMultiSelect = "multiselect"
+	FieldCheckbox    = "checkbox"
+)
+
+// RegistrationQuestion - pertanyaan data peserta per event, atau per kategori
+// tiket jika TicketCategoryID diisi
+type RegistrationQuestion struct {
+	QuestionID       string    `gorm:"primaryKey;type:char(60)" json:"question_id"`
+	EventID          string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	TicketCategoryID string    `gorm:"type:char(60)" json:"ticket_category_id"`
+	Label            string    `gorm:"size:255;not null" json:"label"`
+	HelpText         string    `gorm:"size:255" json:"help_text"`
+	FieldType        string    `gorm:"size:20;not null" json:"field_type"`
+	Options          []string  `gorm:"serializer:json;type:text" json:"options"`
+	Required         bool      `gorm:"default:false" json:"required"`
+	MinLength        *int      `json:"min_length"`
+	MaxLength        *int      `json:"max_length"`
+	MinValue         *float64  `json:"min_value"`
+	MaxValue         *float64  `json:"max_value"`
+	Pattern          string    `gorm:"size:255" json:"pattern"`
+	ShowOnCheckIn    bool      `gorm:"default:false" json:"show_on_check_in"`
+	Position         int       `gorm:"default:0" json:"position"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TicketAnswer - jawaban pemegang tiket untuk satu pertanyaan registrasi.
+// Jawaban multiselect disimpan sebagai JSON array.
+type TicketAnswer struct {
+	TicketAnswerID string    `gorm:"primaryKey;type:char(60)" json:"ticket_answer_id"`
+	TicketID       string    `gorm:"type:char(60);not null;uniqueIndex:idx_ticket_answer_question" json:"ticket_id"`
+	QuestionID     string    `gorm:"type:char(60);not null;uniqueIndex:idx_ticket_answer_question" json:"question_id"`
+	EventID        string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	Value          string    `gorm:"type:text" json:"value"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Entitas yang status-nya dikelola state machine
+const (
+	EntityTicket      = "ticket"
+	EntityEvent       = "event"
+	EntityTransaction = "transaction"
+	EntitySeries      = "series"
+)
+
+// StatusHistory - jejak setiap perubahan status tiket, event dan transaksi.
+// ActorID berisi user_id, atau "system"/"midtrans" untuk perubahan otomatis.
+type StatusHistory struct {
+	StatusHistoryID string    `gorm:"primaryKey;type:char(60)" json:"status_history_id"`
+	EntityType      string    `gorm:"size:20;not null;index:idx_status_history_entity" json:"entity_type"`
+	EntityID        string    `gorm:"type:char(60);not null;index:idx_status_history_entity" json:"entity_id"`
+	FromStatus      string    `gorm:"size:20" json:"from_status"`
+	ToStatus        string    `gorm:"size:20;not null" json:"to_status"`
+	ActorID         string    `gorm:"type:char(60)" json:"actor_id"`
+	Reason          string    `gorm:"size:255" json:"reason"`
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`
+}
+
+// Asal tiket
+const (
+	TicketSourcePlatform = "platform"
+	TicketSourceImport   = "import"
+)
+
+// TicketImport - satu batch impor tiket yang dijual di luar platform.
+// Tiket tanpa akun yang cocok tidak punya owner (OwnerID kosong) sampai
+// pemegangnya mengklaim dengan ClaimCode; data pemegang ada di
+// HolderName/HolderEmail.
+type TicketImport struct {
+	TicketImportID   string    `gorm:"primaryKey;type:char(60)" json:"ticket_import_id"`
+	EventID          string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	TicketCategoryID string    `gorm:"type:char(60);not null" json:"ticket_category_id"`
+	ImportedBy       string    `gorm:"type:char(60);not null" json:"imported_by"`
+	FileName         string    `gorm:"size:255" json:"file_name"`
+	TotalRows        int       `json:"total_rows"`
+	Created          int       `json:"created"`
+	Skipped          int       `json:"skipped"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// EventSeries - event berulang. Tiap occurrence adalah Event biasa dengan
+// SeriesID; status approval dipegang series dan diterapkan ke semua occurrence.
+type EventSeries struct {
+	SeriesID        string    `gorm:"primaryKey;type:char(60)" json:"series_id"`
+	OwnerID         string    `gorm:"type:char(60);not null;index" json:"owner_id"`
+	Name            string    `gorm:"size:100" json:"name"`
+	RRule           string    `gorm:"size:255;not null" json:"rrule"`
+	FirstStart      time.Time `json:"first_start"`
+	Status          string    `gorm:"size:20;default:pending" json:"status"`
+	ApprovalComment string    `gorm:"type:text" json:"approval_comment"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Speaker - pembicara di agenda event, bisa mengisi beberapa sesi
+type Speaker struct {
+	SpeakerID string    `gorm:"primaryKey;type:char(60)" json:"speaker_id"`
+	EventID   string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	Headline  string    `gorm:"size:255" json:"headline"`
+	Bio       string    `gorm:"type:text" json:"bio"`
+	Photo     string    `gorm:"size:255" json:"photo"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AgendaSession - satu sesi di jadwal event. TicketCategoryIDs kosong berarti
+// sesi terbuka untuk semua pemegang tiket event.
+type AgendaSession struct {
+	SessionID         string    `gorm:"primaryKey;type:char(60)" json:"session_id"`
+	EventID           string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	Title             string    `gorm:"size:255;not null" json:"title"`
+	Description       string    `gorm:"type:text" json:"description"`
+	Stage             string    `gorm:"size:100" json:"stage"`
+	StartTime         time.Time `gorm:"index" json:"start_time"`
+	EndTime           time.Time `json:"end_time"`
+	Tags              []string  `gorm:"serializer:json;type:text" json:"tags"`
+	TicketCategoryIDs []string  `gorm:"serializer:json;type:text" json:"ticket_category_ids"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	Speakers []Speaker `gorm:"many2many:agenda_session_speakers;foreignKey:SessionID;joinForeignKey:session_id;references:SpeakerID;joinReferences:speaker_id" json:"speakers"`
+}
+
+// SessionBookmark - sesi yang disimpan pemegang tiket ke jadwal pribadinya
+type SessionBookmark struct {
+	BookmarkID string    `gorm:"primaryKey;type:char(60)" json:"bookmark_id"`
+	SessionID  string    `gorm:"type:char(60);not null;uniqueIndex:idx_session_bookmark_user" json:"session_id"`
+	UserID     string    `gorm:"type:char(60);not null;uniqueIndex:idx_session_bookmark_user" json:"user_id"`
+	EventID    string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Status kursi
+const (
+	SeatAvailable = "available"
+	SeatHeld      = "held"
+	SeatSold      = "sold"
+	SeatBlocked   = "blocked"
+)
+
+// SeatSection - bagian denah venue (mis. "Tribun A") yang dipetakan ke satu
+// kategori tiket. Kategori dengan section menjadi kategori bernomor kursi.
+type SeatSection struct {
+	SectionID        string    `gorm:"primaryKey;type:char(60)" json:"section_id"`
+	EventID          string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	TicketCategoryID string    `gorm:"type:char(60);not null" json:"ticket_category_id"`
+	Name             string    `gorm:"size:100;not null" json:"name"`
+	Position         int       `gorm:"default:0" json:"position"`
+	CreatedAt        time.Time `json:"created_at"`
+
+	Seats []EventSeat `gorm:"foreignKey:SectionID" json:"seats,omitempty"`
+}
+
+// EventSeat - satu kursi. Hold dari pembeli berlaku sampai HoldExpiresAt;
+// kursi yang sudah terhubung ke tiket pending tetap held sampai pembayaran
+// selesai atau gagal.
+type EventSeat struct {
+	SeatID           string     `gorm:"primaryKey;type:char(60)" json:"seat_id"`
+	EventID          string     `gorm:"type:char(60);not null;uniqueIndex:idx_event_seat" json:"event_id"`
+	SectionID        string     `gorm:"type:char(60);not null;uniqueIndex:idx_event_seat" json:"section_id"`
+	TicketCategoryID string     `gorm:"type:char(60);not null;index" json:"ticket_category_id"`
+	Row              string     `gorm:"size:10;not null;uniqueIndex:idx_event_seat" json:"row"`
+	Number           int        `gorm:"not null;uniqueIndex:idx_event_seat" json:"number"`
+	Label            string     `gorm:"size:30" json:"label"`
+	Status           string     `gorm:"size:20;default:available;index" json:"status"`
+	HeldBy           string     `gorm:"type:char(60)" json:"held_by,omitempty"`
+	HoldExpiresAt    *time.Time `json:"hold_expires_at,omitempty"`
+	TicketID         string     `gorm:"type:char(60);index" json:"ticket_id,omitempty"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// Status change-set event
+const (
+	RevisionPending      = "pending"
+	RevisionApproved     = "approved"
+	RevisionRejected     = "rejected"
+	RevisionAutoApproved = "auto_approved"
+	RevisionApplied      = "applied"
+	RevisionWithdrawn    = "withdrawn"
+)
+
+// FieldChange - satu field yang diubah dalam change-set, nilai disimpan
+// sebagai string (tanggal dalam RFC3339)
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// EventRevision - change-set bernomor versi untuk setiap edit event. Edit
+// event yang sudah tayang menunggu review admin (kecuali semua field-nya
+// low-risk) sementara versi live tetap dipublikasikan.
+type EventRevision struct {
+	RevisionID    string        `gorm:"primaryKey;type:char(60)" json:"revision_id"`
+	EventID       string        `gorm:"type:char(60);not null;uniqueIndex:idx_event_revision_version" json:"event_id"`
+	Version       uint          `gorm:"not null;uniqueIndex:idx_event_revision_version" json:"version"`
+	AuthorID      string        `gorm:"type:char(60);not null" json:"author_id"`
+	Status        string        `gorm:"size:20;not null;index" json:"status"`
+	Changes       []FieldChange `gorm:"type:text;serializer:json" json:"changes"`
+	ReviewerID    string        `gorm:"type:char(60)" json:"reviewer_id,omitempty"`
+	ReviewComment string        `gorm:"type:text" json:"review_comment,omitempty"`
+	ReviewedAt    *time.Time    `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// Status refund ke payment gateway
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+	RefundFailed    = "failed"
+)
+
+// Refund - pengembalian dana sebagian atau penuh satu transaksi paid untuk
+// tiket dari satu event. RefundID dikirim sebagai refund_key Midtrans agar
+// retry tidak membuat refund ganda.
+type Refund struct {
+	RefundID       string    `gorm:"primaryKey;type:char(60)" json:"refund_id"`
+	TransactionID  string    `gorm:"type:char(60);not null;index" json:"transaction_id"`
+	EventID        string    `gorm:"type:char(60);not null;index" json:"event_id"`
+	OwnerID        string    `gorm:"type:char(60);not null;index" json:"owner_id"`
+	TicketIDs      []string  `gorm:"type:text;serializer:json" json:"ticket_ids"`
+	Amount         float64   `gorm:"type:decimal(10,2)" json:"amount"`
+	Reason         string    `gorm:"size:255" json:"reason"`
+	Status         string    `gorm:"size:20;default:pending;index" json:"status"`
+	GatewayMessage string    `gorm:"size:255" json:"gateway_message,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Jenis notifikasi ke pemegang tiket
+const (
+	NotificationEventCancelled   = "event_cancelled"
+	NotificationEventPostponed   = "event_postponed"
+	NotificationEventRescheduled = "event_rescheduled"
+	NotificationRefund           = "refund"
+)
+
+// Notification - pemberitahuan in-app; dikirim juga lewat email jika SMTP
+// dikonfigurasi
+type Notification struct {
+	NotificationID string     `gorm:"primaryKey;type:char(60)" json:"notification_id"`
+	UserID         string     `gorm:"type:char(60);not null;index" json:"user_id"`
+	EventID        string     `gorm:"type:char(60);index" json:"event_id,omitempty"`
+	Type           string     `gorm:"size:30;not null" json:"type"`
+	Title          string     `gorm:"size:150;not null" json:"title"`
+	Message        string     `gorm:"type:text" json:"message"`
+	ReadAt         *time.Time `json:"read_at"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+}
@@ -21,23 +21,86 @@ func SetupRoutes(app *fiber.App) {
 	user := app.Group("/api/users", middleware.AuthMiddleware)
 	user.Get("/profile", handlers.GetProfile)
 	user.Put("/profile", handlers.UpdateProfile)
+	user.Get("/calendar-feed", handlers.GetCalendarFeedURL)
+	user.Post("/calendar-feed/rotate", handlers.RotateCalendarFeedURL)
+	user.Delete("/calendar-feed", handlers.RevokeCalendarFeedURL)
 	user.Get("/:id", middleware.AdminMiddleware, handlers.GetUserByID)
 	user.Get("/", middleware.AdminMiddleware, handlers.GetUsers)
 	user.Post("/:id/verify", middleware.AdminMiddleware, handlers.VerifyUser)
+	user.Delete("/:id", middleware.AdminMiddleware, handlers.DeleteUser)
 
 	// Event routes
 	app.Get("/api/events", handlers.GetApprovedEvents)
 	app.Get("/api/event/:id", handlers.GetEvent)
+	app.Get("/api/event/:id/agenda", handlers.GetEventAgenda)
+	app.Get("/api/event/:id/seats", handlers.GetSeatMap)
+	app.Get("/api/event/:id/calendar.ics", handlers.GetEventCalendar)
 	app.Get("/api/events/popular", handlers.GetEventsPopular)
+	app.Get("/api/events/nearby", handlers.GetNearbyEvents)
 	app.Get("/api/events/category", handlers.GetEventCategories)
+	app.Get("/api/status-transitions", handlers.GetStatusTransitions)
 	event := app.Group("/api/events", middleware.AuthMiddleware)
-	event.Get("/all", handlers.GetEvents)
+	event.Get("/all", middleware.NonStaffMiddleware, handlers.GetEvents)
 	event.Get("/my-events", handlers.GetMyEvents)
 	event.Post("/", middleware.OrganizerApprovalMiddleware, handlers.CreateEvent)
+	event.Post("/series", middleware.OrganizerApprovalMiddleware, handlers.CreateEventSeries)
+	event.Get("/series/:series_id", handlers.GetEventSeries)
 	event.Put("/:id", handlers.UpdateEvent)
+	event.Put("/:id/occurrence", middleware.NonStaffMiddleware, handlers.UpdateOccurrence)
+	event.Post("/:id/occurrence/cancel", middleware.NonStaffMiddleware, handlers.CancelOccurrence)
+	event.Post("/:id/cancel", middleware.NonStaffMiddleware, handlers.CancelEvent)
+	event.Post("/:id/postpone", middleware.NonStaffMiddleware, handlers.PostponeEvent)
+	event.Post("/:id/reschedule", middleware.NonStaffMiddleware, handlers.RescheduleEvent)
+	event.Get("/:id/refunds", middleware.NonStaffMiddleware, handlers.GetEventRefunds)
+	event.Post("/:id/refunds/:refund_id/retry", middleware.AdminMiddleware, handlers.RetryRefund)
+	event.Post("/:id/submit", middleware.NonStaffMiddleware, handlers.SubmitEvent)
+	event.Post("/:id/duplicate", middleware.OrganizerApprovalMiddleware, handlers.DuplicateEvent)
+	event.Patch("/:id/publish-at", middleware.NonStaffMiddleware, handlers.ScheduleEventPublish)
+	event.Get("/revisions/pending", middleware.AdminMiddleware, handlers.GetPendingRevisions)
+	event.Get("/:id/revisions", middleware.NonStaffMiddleware, handlers.GetEventRevisions)
+	event.Get("/:id/revisions/:revision_id", middleware.NonStaffMiddleware, handlers.GetEventRevision)
+	event.Patch("/:id/revisions/:revision_id/review", middleware.AdminMiddleware, handlers.ReviewEventRevision)
+	event.Delete("/:id/revisions/:revision_id", middleware.NonStaffMiddleware, handlers.WithdrawEventRevision)
+	event.Post("/:id/ticket-categories", middleware.NonStaffMiddleware, handlers.CreateTicketCategory)
+	event.Put("/:id/ticket-categories/:category_id", middleware.NonStaffMiddleware, handlers.UpdateTicketCategory)
+	event.Patch("/:id/ticket-categories/:category_id/archive", middleware.NonStaffMiddleware, handlers.ArchiveTicketCategory)
 	event.Delete("/:id", handlers.DeleteEvent)
-	event.Get("/:id/report", handlers.GetEventReport)
-	event.Get("/:id/report/download", handlers.DownloadEventReport)
+	event.Get("/:id/report", middleware.NonStaffMiddleware, handlers.GetEventReport)
+	event.Get("/:id/report/download", middleware.NonStaffMiddleware, handlers.DownloadEventReport)
+	event.Get("/:id/scanner-key", handlers.GetScannerKey)
+	event.Patch("/:id/checkin-settings", middleware.NonStaffMiddleware, handlers.UpdateCheckInSettings)
+	event.Get("/:id/staff", middleware.NonStaffMiddleware, handlers.GetEventStaff)
+	event.Post("/:id/staff", middleware.NonStaffMiddleware, handlers.AddEventStaff)
+	event.Put("/:id/staff/:staff_id", middleware.NonStaffMiddleware, handlers.UpdateEventStaff)
+	event.Delete("/:id/staff/:staff_id", middleware.NonStaffMiddleware, handlers.RemoveEventStaff)
+	event.Get("/:id/entry-sessions", middleware.NonStaffMiddleware, handlers.GetEntrySessions)
+	event.Post("/:id/entry-sessions", middleware.NonStaffMiddleware, handlers.CreateEntrySession)
+	event.Delete("/:id/entry-sessions/:session_id", middleware.NonStaffMiddleware, handlers.DeleteEntrySession)
+	event.Get("/:id/questions", middleware.NonStaffMiddleware, handlers.GetRegistrationQuestions)
+	event.Post("/:id/questions", middleware.NonStaffMiddleware, handlers.CreateRegistrationQuestion)
+	event.Put("/:id/questions/:question_id", middleware.NonStaffMiddleware, handlers.UpdateRegistrationQuestion)
+	event.Delete("/:id/questions/:question_id", middleware.NonStaffMiddleware, handlers.DeleteRegistrationQuestion)
+	event.Get("/:id/answers/export", middleware.NonStaffMiddleware, handlers.ExportTicketAnswers)
+	event.Post("/:id/imports", middleware.NonStaffMiddleware, handlers.ImportTickets)
+	event.Get("/:id/imports", middleware.NonStaffMiddleware, handlers.GetTicketImports)
+	event.Get("/:id/imports/tickets", middleware.NonStaffMiddleware, handlers.GetImportedTickets)
+	event.Get("/:id/history", middleware.NonStaffMiddleware, handlers.GetEventHistory)
+	event.Get("/:id/speakers", middleware.NonStaffMiddleware, handlers.GetSpeakers)
+	event.Post("/:id/speakers", middleware.NonStaffMiddleware, handlers.CreateSpeaker)
+	event.Put("/:id/speakers/:speaker_id", middleware.NonStaffMiddleware, handlers.UpdateSpeaker)
+	event.Delete("/:id/speakers/:speaker_id", middleware.NonStaffMiddleware, handlers.DeleteSpeaker)
+	event.Post("/:id/agenda", middleware.NonStaffMiddleware, handlers.CreateAgendaSession)
+	event.Put("/:id/agenda/:session_id", middleware.NonStaffMiddleware, handlers.UpdateAgendaSession)
+	event.Delete("/:id/agenda/:session_id", middleware.NonStaffMiddleware, handlers.DeleteAgendaSession)
+	event.Get("/:id/agenda/bookmarks", handlers.GetMyBookmarks)
+	event.Post("/:id/agenda/:session_id/bookmark", handlers.BookmarkSession)
+	event.Delete("/:id/agenda/:session_id/bookmark", handlers.RemoveBookmark)
+	event.Get("/:id/seats", middleware.NonStaffMiddleware, handlers.GetManagedSeatMap)
+	event.Put("/:id/seat-map", middleware.NonStaffMiddleware, handlers.SaveSeatMap)
+	event.Patch("/:id/seats/block", middleware.NonStaffMiddleware, handlers.BlockSeats)
+	event.Post("/:id/seats/move", middleware.NonStaffMiddleware, handlers.MoveAttendee)
+	event.Post("/:id/seats/hold", middleware.NonStaffMiddleware, handlers.HoldSeats)
+	event.Delete("/:id/seats/hold", middleware.NonStaffMiddleware, handlers.ReleaseSeats)
 	event.Patch("/:id/verify", middleware.AdminMiddleware, handlers.VerifyEvent)
 	event.Post("/:id/like", handlers.AddLike)
 	event.Get("/like", handlers.MyLikedEvent)
@@ -51,27 +114,66 @@ func SetupRoutes(app *fiber.App) {
 	ticket := app.Group("/api/tickets", middleware.AuthMiddleware)
 	ticket.Get("/", handlers.GetTickets)
 	ticket.Get("/stats", handlers.GetTicketStats)
+	ticket.Post("/claim", handlers.ClaimImportedTicket)
 	ticket.Get("/:id", handlers.GetEvent)
 	ticket.Patch("/:event_id/:id/checkin", handlers.CheckInTicket)
+	ticket.Patch("/:event_id/:id/checkout", handlers.CheckOutTicket)
 	ticket.Get("/:id/code", handlers.GetTicketCode)
+	ticket.Post("/:id/code", handlers.RenewTicketCode)
+	ticket.Get("/:id/qr", handlers.GetTicketQR)
+	ticket.Get("/:id/pdf", handlers.DownloadTicketPDF)
+	ticket.Post("/:id/pdf/reissue", handlers.ReissueTicketPDF)
+	ticket.Get("/:id/calendar.ics", handlers.GetTicketCalendar)
+	ticket.Get("/:id/wallet/apple", handlers.GetAppleWalletPass)
+	ticket.Get("/:id/wallet/google", handlers.GetGoogleWalletPass)
+	ticket.Get("/:id/answers", handlers.GetTicketAnswers)
+	ticket.Put("/:id/answers", handlers.SaveTicketAnswers)
+	ticket.Get("/:id/history", handlers.GetTicketHistory)
+	ticket.Post("/:id/refund", handlers.RefundTicket)
 	ticket.Patch("/:id/tag", handlers.UpdateTagTicket)
 
+	// Langganan kalender (.ics), autentikasi lewat token di URL
+	app.Get("/api/calendar/:token/feed.ics", handlers.GetCalendarFeed)
+
+	// Apple Wallet web service (APPLE_PASS_WEB_SERVICE_URL = <host>/api/wallet/apple),
+	// diautentikasi dengan authenticationToken pass
+	wallet := app.Group("/api/wallet/apple/v1")
+	wallet.Post("/devices/:device_id/registrations/:pass_type_id/:serial", handlers.RegisterWalletDevice)
+	wallet.Delete("/devices/:device_id/registrations/:pass_type_id/:serial", handlers.UnregisterWalletDevice)
+	wallet.Get("/devices/:device_id/registrations/:pass_type_id", handlers.GetUpdatedWalletSerials)
+	wallet.Get("/passes/:pass_type_id/:serial", handlers.GetLatestWalletPass)
+	wallet.Post("/log", handlers.LogWalletErrors)
+
+	// Check-in routes
+	// didaftarkan sebelum group agar token dari query dipasang sebelum AuthMiddleware
+	app.Get("/api/checkin/:event_id/live", middleware.QueryTokenMiddleware, middleware.AuthMiddleware, middleware.NonStaffMiddleware, handlers.StreamCheckInLive)
+	checkin := app.Group("/api/checkin", middleware.AuthMiddleware)
+	checkin.Get("/:event_id/manifest", handlers.GetCheckInManifest)
+	checkin.Post("/:event_id/sync", handlers.SyncOfflineScans)
+	checkin.Get("/assignments", handlers.GetMyStaffAssignments)
+	checkin.Get("/:event_id/logs", middleware.NonStaffMiddleware, handlers.GetCheckInLogs)
+	checkin.Get("/:event_id/stats", middleware.NonStaffMiddleware, handlers.GetCheckInStats)
+	checkin.Get("/:event_id/attendance", middleware.NonStaffMiddleware, handlers.GetEventAttendance)
+
 	// Cart routes
-	cart := app.Group("/api/cart", middleware.AuthMiddleware)
+	cart := app.Group("/api/cart", middleware.AuthMiddleware, middleware.NonStaffMiddleware)
 	cart.Post("/", handlers.AddToCart)
 	cart.Get("/", handlers.GetCart)
 	cart.Patch("/", handlers.UpdateCart)
 	cart.Delete("/", handlers.DeleteCart)
 
 	// Payment routes
-	payment := app.Group("/api/payment", middleware.AuthMiddleware)
+	payment := app.Group("/api/payment", middleware.AuthMiddleware, middleware.NonStaffMiddleware)
 	payment.Post("/midtrans", handlers.PaymentMidtrans)
 	app.Post("/midtrans/callback", handlers.PaymentNotificationHandler)
 
 	// Transaction routes
-	transaction := app.Group("/api/transactions", middleware.AuthMiddleware)
+	transaction := app.Group("/api/transactions", middleware.AuthMiddleware, middleware.NonStaffMiddleware)
 	transaction.Get("/", handlers.GetTransactionHistory)
 	transaction.Get("/:id", handlers.GetTransactionDetail)
+	transaction.Get("/:id/history", handlers.GetTransactionStatusHistory)
+	transaction.Get("/:id/tickets/pdf", handlers.DownloadTransactionTicketsPDF)
+	transaction.Post("/:id/tickets/pdf/reissue", handlers.ReissueTransactionTicketsPDF)
 
 	// Feedback routes
 	feedback := app.Group("/api/feedback", middleware.AuthMiddleware)
@@ -80,4 +182,15 @@ func SetupRoutes(app *fiber.App) {
 	feedback.Get("/mine", handlers.GetMyFeedbacks)
 	feedback.Put("/detail/:id/status", handlers.UpdateStatusFeedback)
 	feedback.Get("/detail/:id", handlers.GetFeedback)
+	feedback.Delete("/detail/:id", handlers.DeleteFeedback)
+
+	// Trash routes (soft delete), dipurge setelah TRASH_RETENTION_DAYS
+	trash := app.Group("/api/trash", middleware.AuthMiddleware, middleware.AdminMiddleware)
+	trash.Get("/:type", handlers.GetTrash)
+	trash.Post("/:type/:id/restore", handlers.RestoreTrash)
+
+	// Notification routes
+	notification := app.Group("/api/notifications", middleware.AuthMiddleware)
+	notification.Get("/", handlers.GetMyNotifications)
+	notification.Patch("/:id/read", handlers.MarkNotificationRead)
 }
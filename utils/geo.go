@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const earthRadiusKm = 6371.0
+
+var ErrAddressNotFound = errors.New("address not found")
+
+// ValidCoordinates - latitude -90..90, longitude -180..180
+func ValidCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// HaversineKm - jarak lingkaran besar dua titik dalam kilometer
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// HaversineSQL - ekspresi SQL (MySQL) jarak km dari titik ke kolom lat/lng,
+// sama dengan HaversineKm. Argumen placeholder-nya: lat, lat, lng.
+func HaversineSQL(latColumn, lngColumn string) string {
+	return fmt.Sprintf("(%f * 2 * ASIN(LEAST(1, SQRT(POW(SIN(RADIANS(%s - ?) / 2), 2) + "+
+		"COS(RADIANS(?)) * COS(RADIANS(%s)) * POW(SIN(RADIANS(%s - ?) / 2), 2)))))",
+		earthRadiusKm, latColumn, latColumn, lngColumn)
+}
+
+// BoundingBox - kotak lat/lng yang memuat lingkaran radius, untuk prefilter
+// query sebelum jarak dihitung tepat
+func BoundingBox(lat, lng, radiusKm float64) (minLat, maxLat, minLng, maxLng float64) {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	minLat, maxLat = math.Max(lat-dLat, -90), math.Min(lat+dLat, 90)
+
+	cos := math.Cos(lat * math.Pi / 180)
+	if cos < 1e-6 || maxLat == 90 || minLat == -90 {
+		return minLat, maxLat, -180, 180
+	}
+	dLng := dLat / cos
+	return minLat, maxLat, math.Max(lng-dLng, -180), math.Min(lng+dLng, 180)
+}
+
+// Geocoder mengubah alamat teks menjadi koordinat
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (lat, lng float64, err error)
+}
+
+// StubGeocoder - geocoder offline untuk development dan test. Alamat dicocokkan
+// dengan daftar kota besar; koordinat yang dikembalikan adalah pusat kota.
+type StubGeocoder struct{}
+
+var stubCities = map[string][2]float64{
+	"jakarta":    {-6.2088, 106.8456},
+	"bandung":    {-6.9175, 107.6191},
+	"surabaya":   {-7.2575, 112.7521},
+	"yogyakarta": {-7.7956, 110.3695},
+	"semarang":   {-6.9667, 110.4167},
+	"surakarta":  {-7.5755, 110.8243},
+	"malang":     {-7.9666, 112.6326},
+	"bogor":      {-6.5971, 106.8060},
+	"depok":      {-6.4025, 106.7942},
+	"tangerang":  {-6.1783, 106.6319},
+	"bekasi":     {-6.2383, 106.9756},
+	"medan":      {3.5952, 98.6722},
+	"palembang":  {-2.9761, 104.7754},
+	"denpasar":   {-8.6705, 115.2126},
+	"makassar":   {-5.1477, 119.4327},
+}
+
+func (StubGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	address = strings.ToLower(address)
+	for city, coords := range stubCities {
+		if strings.Contains(address, city) {
+			return coords[0], coords[1], nil
+		}
+	}
+	return 0, 0, ErrAddressNotFound
+}
+
+// NominatimGeocoder - geocoder OpenStreetMap Nominatim (atau server yang
+// kompatibel). UserAgent wajib diisi sesuai kebijakan penggunaan Nominatim.
+type NominatimGeocoder struct {
+	BaseURL   string
+	UserAgent string
+	Client    *http.Client
+}
+
+func (g NominatimGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	params := url.Values{}
+	params.Set("q", address)
+	params.Set("format", "json")
+	params.Set("limit", "1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(g.BaseURL, "/")+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("User-Agent", g.UserAgent)
+
+	client := g.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("geocoder returned status %d", resp.StatusCode)
+	}
+
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return 0, 0, err
+	}
+	if len(results) == 0 {
+		return 0, 0, ErrAddressNotFound
+	}
+
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	lng, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return lat, lng, nil
+}
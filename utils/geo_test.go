@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+// jarak satu derajat lintang
+var kmPerDegree = earthRadiusKm * math.Pi / 180
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{name: "same point", lat1: -6.2088, lng1: 106.8456, lat2: -6.2088, lng2: 106.8456, want: 0},
+		{name: "one degree of latitude", lat1: 0, lng1: 0, lat2: 1, lng2: 0, want: kmPerDegree},
+		{name: "jakarta to bandung", lat1: -6.2088, lng1: 106.8456, lat2: -6.9175, lng2: 107.6191, want: 116.24},
+		{name: "antipodal", lat1: 0, lng1: 0, lat2: 0, lng2: 180, want: math.Pi * earthRadiusKm},
+		{name: "across the antimeridian", lat1: 0, lng1: 179.5, lat2: 0, lng2: -179.5, want: kmPerDegree},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HaversineKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("HaversineKm() = %.4f, want %.4f", got, tt.want)
+			}
+			if back := HaversineKm(tt.lat2, tt.lng2, tt.lat1, tt.lng1); math.Abs(back-got) > 1e-9 {
+				t.Errorf("HaversineKm() is not symmetric: %.6f vs %.6f", got, back)
+			}
+		})
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	tests := []struct {
+		name                           string
+		lat, lng, radiusKm             float64
+		minLat, maxLat, minLng, maxLng float64
+	}{
+		{name: "equator", lat: 0, lng: 0, radiusKm: kmPerDegree, minLat: -1, maxLat: 1, minLng: -1, maxLng: 1},
+		{name: "longitude widens with latitude", lat: 60, lng: 10, radiusKm: kmPerDegree, minLat: 59, maxLat: 61, minLng: 8, maxLng: 12},
+		{name: "clamped at the antimeridian", lat: 0, lng: 179.5, radiusKm: kmPerDegree, minLat: -1, maxLat: 1, minLng: 178.5, maxLng: 180},
+		{name: "circle covers the pole", lat: 89.5, lng: 10, radiusKm: kmPerDegree, minLat: 88.5, maxLat: 90, minLng: -180, maxLng: 180},
+	}
+
+	const eps = 1e-9
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minLat, maxLat, minLng, maxLng := BoundingBox(tt.lat, tt.lng, tt.radiusKm)
+			got := []float64{minLat, maxLat, minLng, maxLng}
+			want := []float64{tt.minLat, tt.maxLat, tt.minLng, tt.maxLng}
+			for i := range got {
+				if math.Abs(got[i]-want[i]) > eps {
+					t.Fatalf("BoundingBox() = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+// ICalEvent - satu VEVENT (RFC 5545)
+type ICalEvent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Start        time.Time
+	End          time.Time
+	Status       string // CONFIRMED, TENTATIVE atau CANCELLED
+	LastModified time.Time
+	Latitude     *float64
+	Longitude    *float64
+}
+
+// BuildICalendar menyusun VCALENDAR berisi events. name tampil sebagai nama
+// kalender di klien yang mendukung X-WR-CALNAME.
+func BuildICalendar(name string, events []ICalEvent) []byte {
+	var b strings.Builder
+	line := func(content string) {
+		b.WriteString(foldICalLine(content))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Ticketing-Backend//Event Calendar//ID")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if name != "" {
+		line("X-WR-CALNAME:" + escapeICalText(name))
+	}
+
+	now := time.Now().UTC().Format(icalTimeFormat)
+	for _, event := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + event.UID)
+		line("DTSTAMP:" + now)
+		line("DTSTART:" + event.Start.UTC().Format(icalTimeFormat))
+		line("DTEND:" + event.End.UTC().Format(icalTimeFormat))
+		line("SUMMARY:" + escapeICalText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION:" + escapeICalText(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION:" + escapeICalText(event.Location))
+		}
+		if event.Latitude != nil && event.Longitude != nil {
+			line(fmt.Sprintf("GEO:%f;%f", *event.Latitude, *event.Longitude))
+		}
+		if event.Status != "" {
+			line("STATUS:" + event.Status)
+		}
+		if !event.LastModified.IsZero() {
+			line("LAST-MODIFIED:" + event.LastModified.UTC().Format(icalTimeFormat))
+		}
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+func escapeICalText(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(value)
+}
+
+// foldICalLine memecah baris lebih dari 75 octet tanpa memotong karakter UTF-8
+func foldICalLine(content string) string {
+	if len(content) <= 75 {
+		return content
+	}
+
+	var b strings.Builder
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// baris lanjutan diawali spasi, jadi sisa muatannya 74 octet
+		limit = 74
+	}
+	b.WriteString(content)
+	return b.String()
+}
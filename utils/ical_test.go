@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeICalText(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "Konser Musik", want: "Konser Musik"},
+		{name: "separators", value: "Jl. Sudirman, No. 1; Lt. 2", want: `Jl. Sudirman\, No. 1\; Lt. 2`},
+		{name: "backslash first", value: `C:\tiket`, want: `C:\\tiket`},
+		{name: "newlines", value: "baris 1\r\nbaris 2\nbaris 3\rbaris 4", want: `baris 1\nbaris 2\nbaris 3\nbaris 4`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeICalText(tt.value); got != tt.want {
+				t.Errorf("escapeICalText(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldICalLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string // kosong berarti hanya properti lipatan yang dicek
+	}{
+		{name: "short", content: "SUMMARY:Konser", want: "SUMMARY:Konser"},
+		{name: "exactly 75 octets", content: strings.Repeat("a", 75), want: strings.Repeat("a", 75)},
+		{name: "76 octets", content: strings.Repeat("a", 76), want: strings.Repeat("a", 75) + "\r\n a"},
+		{name: "continuation holds 74 octets", content: strings.Repeat("a", 150), want: strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a"},
+		{name: "two byte runes", content: "SUMMARY:" + strings.Repeat("é", 60)},
+		{name: "three byte runes", content: "DESCRIPTION:" + strings.Repeat("—", 50)},
+		{name: "four byte runes", content: "LOCATION:" + strings.Repeat("🎫", 40)},
+		{name: "mixed", content: "SUMMARY:a" + strings.Repeat("ñ🎵x", 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := foldICalLine(tt.content)
+			if tt.want != "" && got != tt.want {
+				t.Errorf("foldICalLine() = %q, want %q", got, tt.want)
+			}
+
+			for i, line := range strings.Split(got, "\r\n") {
+				if len(line) > 75 {
+					t.Errorf("line %d has %d octets", i, len(line))
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space", i)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, line)
+				}
+			}
+
+			if unfolded := strings.ReplaceAll(got, "\r\n ", ""); unfolded != tt.content {
+				t.Errorf("unfolded line = %q, want %q", unfolded, tt.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Batas jumlah occurrence yang dibangkitkan dari satu RRULE
+const MaxRRuleOccurrences = 104
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RRuleDay - nilai BYDAY, N != 0 berarti hari ke-N dalam bulan (-1 = terakhir)
+type RRuleDay struct {
+	N   int
+	Day time.Weekday
+}
+
+// RRule - subset RFC 5545: FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, COUNT, UNTIL,
+// BYDAY (WEEKLY: MO,WE; MONTHLY: 1FR,-1SA) dan BYMONTHDAY (MONTHLY).
+// COUNT atau UNTIL wajib agar jumlah occurrence terbatas.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []RRuleDay
+	ByMonthDay []int
+
+	// UNTIL tanpa "Z" (tanggal atau waktu lokal) mengikuti zona waktu start
+	untilFloating bool
+}
+
+// ParseRRule mem-parse string seperti "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=10".
+// Awalan "RRULE:" boleh ada.
+func ParseRRule(value string) (RRule, error) {
+	rule := RRule{Interval: 1}
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return rule, fmt.Errorf("rrule is empty")
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return rule, fmt.Errorf("invalid rrule part %q", part)
+		}
+		key, val = strings.ToUpper(strings.TrimSpace(key)), strings.ToUpper(strings.TrimSpace(val))
+
+		switch key {
+		case "FREQ":
+			if val != "DAILY" && val != "WEEKLY" && val != "MONTHLY" {
+				return rule, fmt.Errorf("unsupported FREQ %s, use DAILY, WEEKLY or MONTHLY", val)
+			}
+			rule.Freq = val
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("invalid INTERVAL %s", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("invalid COUNT %s", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, floating, err := parseRRuleTime(val)
+			if err != nil {
+				return rule, fmt.Errorf("invalid UNTIL %s", val)
+			}
+			rule.Until, rule.untilFloating = until, floating
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				day, err := parseRRuleDay(item)
+				if err != nil {
+					return rule, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(item)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return rule, fmt.Errorf("invalid BYMONTHDAY %s", item)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			// minggu selalu dihitung mulai Senin
+		default:
+			return rule, fmt.Errorf("unsupported rrule part %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, fmt.Errorf("FREQ is required")
+	}
+	if rule.Count == 0 && rule.Until.IsZero() {
+		return rule, fmt.Errorf("COUNT or UNTIL is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return rule, fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+	if rule.Freq == "DAILY" && (len(rule.ByDay) > 0 || len(rule.ByMonthDay) > 0) {
+		return rule, fmt.Errorf("BYDAY and BYMONTHDAY are not supported with FREQ=DAILY")
+	}
+	if rule.Freq == "WEEKLY" {
+		if len(rule.ByMonthDay) > 0 {
+			return rule, fmt.Errorf("BYMONTHDAY is not supported with FREQ=WEEKLY")
+		}
+		for _, day := range rule.ByDay {
+			if day.N != 0 {
+				return rule, fmt.Errorf("BYDAY with position is only supported with FREQ=MONTHLY")
+			}
+		}
+	}
+
+	return rule, nil
+}
+
+// parseRRuleTime mengembalikan waktu UNTIL dan apakah waktunya floating
+// (tanpa zona, ditafsirkan di zona waktu start)
+func parseRRuleTime(value string) (time.Time, bool, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// UNTIL berupa tanggal mencakup seluruh hari itu
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, layout != "20060102T150405Z", nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid time %s", value)
+}
+
+func parseRRuleDay(value string) (RRuleDay, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return RRuleDay{}, fmt.Errorf("invalid BYDAY %s", value)
+	}
+
+	day, ok := rruleWeekdays[value[len(value)-2:]]
+	if !ok {
+		return RRuleDay{}, fmt.Errorf("invalid BYDAY %s", value)
+	}
+
+	n := 0
+	if prefix := value[:len(value)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RRuleDay{}, fmt.Errorf("invalid BYDAY %s", value)
+		}
+	}
+
+	return RRuleDay{N: n, Day: day}, nil
+}
+
+// Occurrences membangkitkan waktu mulai tiap occurrence, diawali start.
+// Jam dan zona waktu mengikuti start; hasil dibatasi MaxRRuleOccurrences.
+func (r RRule) Occurrences(start time.Time) []time.Time {
+	limit := MaxRRuleOccurrences
+	if r.Count > 0 && r.Count < limit {
+		limit = r.Count
+	}
+
+	until := r.Until
+	if r.untilFloating {
+		until = time.Date(until.Year(), until.Month(), until.Day(), until.Hour(), until.Minute(), until.Second(), 0, start.Location())
+	}
+
+	var result []time.Time
+	add := func(candidates []time.Time) bool {
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+		for _, t := range candidates {
+			if t.Before(start) {
+				continue
+			}
+			if !until.IsZero() && t.After(until) {
+				return false
+			}
+			result = append(result, t)
+			if len(result) >= limit {
+				return false
+			}
+		}
+		return true
+	}
+
+	hour, min, sec := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, start.Nanosecond(), start.Location())
+	}
+
+	// batas iterasi agar rule tanpa hasil (mis. BYMONTHDAY=31 di FREQ bulanan
+	// yang hanya mengenai bulan pendek) tidak berputar selamanya
+	const maxPeriods = 1000
+
+	switch r.Freq {
+	case "DAILY":
+		for i := 0; i < maxPeriods; i++ {
+			day := start.AddDate(0, 0, i*r.Interval)
+			if !add([]time.Time{at(day.Year(), day.Month(), day.Day())}) {
+				break
+			}
+		}
+
+	case "WEEKLY":
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []RRuleDay{{Day: start.Weekday()}}
+		}
+		// Senin dari minggu start
+		offset := (int(start.Weekday()) + 6) % 7
+		monday := start.AddDate(0, 0, -offset)
+		for i := 0; i < maxPeriods; i++ {
+			week := monday.AddDate(0, 0, 7*i*r.Interval)
+			candidates := make([]time.Time, 0, len(days))
+			for _, d := range days {
+				day := week.AddDate(0, 0, (int(d.Day)+6)%7)
+				candidates = append(candidates, at(day.Year(), day.Month(), day.Day()))
+			}
+			if !add(candidates) {
+				break
+			}
+		}
+
+	case "MONTHLY":
+		first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+		for i := 0; i < maxPeriods; i++ {
+			month := first.AddDate(0, i*r.Interval, 0)
+			if !add(r.monthCandidates(month, start, at)) {
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// monthCandidates - tanggal-tanggal dalam satu bulan yang cocok dengan rule
+func (r RRule) monthCandidates(month time.Time, start time.Time, at func(int, time.Month, int) time.Time) []time.Time {
+	year, mon := month.Year(), month.Month()
+	daysIn := time.Date(year, mon+1, 0, 0, 0, 0, 0, month.Location()).Day()
+
+	var days []int
+	for _, n := range r.ByMonthDay {
+		day := n
+		if n < 0 {
+			day = daysIn + n + 1
+		}
+		if day >= 1 && day <= daysIn {
+			days = append(days, day)
+		}
+	}
+
+	for _, d := range r.ByDay {
+		var matches []int
+		for day := 1; day <= daysIn; day++ {
+			if time.Date(year, mon, day, 0, 0, 0, 0, month.Location()).Weekday() == d.Day {
+				matches = append(matches, day)
+			}
+		}
+		switch {
+		case d.N == 0:
+			days = append(days, matches...)
+		case d.N > 0 && d.N <= len(matches):
+			days = append(days, matches[d.N-1])
+		case d.N < 0 && -d.N <= len(matches):
+			days = append(days, matches[len(matches)+d.N])
+		}
+	}
+
+	// tanpa BYDAY/BYMONTHDAY: tanggal yang sama dengan start, bulan tanpa
+	// tanggal itu (mis. 31) dilewati sesuai RFC 5545
+	if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && start.Day() <= daysIn {
+		days = append(days, start.Day())
+	}
+
+	seen := make(map[int]bool)
+	candidates := make([]time.Time, 0, len(days))
+	for _, day := range days {
+		if seen[day] {
+			continue
+		}
+		seen[day] = true
+		candidates = append(candidates, at(year, mon, day))
+	}
+	return candidates
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+		check   func(RRule) bool
+	}{
+		{
+			name:  "last saturday with prefix",
+			value: "RRULE:FREQ=MONTHLY;BYDAY=-1SA;COUNT=3",
+			check: func(r RRule) bool {
+				return r.Freq == "MONTHLY" && r.Count == 3 && len(r.ByDay) == 1 &&
+					r.ByDay[0] == RRuleDay{N: -1, Day: time.Saturday}
+			},
+		},
+		{
+			name:  "month day and interval",
+			value: "freq=monthly;interval=2;bymonthday=31,-1;count=4",
+			check: func(r RRule) bool {
+				return r.Interval == 2 && len(r.ByMonthDay) == 2 && r.ByMonthDay[0] == 31 && r.ByMonthDay[1] == -1
+			},
+		},
+		{name: "empty", value: "", wantErr: true},
+		{name: "yearly", value: "FREQ=YEARLY;COUNT=2", wantErr: true},
+		{name: "unbounded", value: "FREQ=DAILY", wantErr: true},
+		{name: "count and until", value: "FREQ=DAILY;COUNT=2;UNTIL=20240101", wantErr: true},
+		{name: "weekly position", value: "FREQ=WEEKLY;BYDAY=-1SA;COUNT=2", wantErr: true},
+		{name: "daily byday", value: "FREQ=DAILY;BYDAY=MO;COUNT=2", wantErr: true},
+		{name: "month day out of range", value: "FREQ=MONTHLY;BYMONTHDAY=32;COUNT=2", wantErr: true},
+		{name: "position out of range", value: "FREQ=MONTHLY;BYDAY=6SA;COUNT=2", wantErr: true},
+		{name: "invalid until", value: "FREQ=DAILY;UNTIL=2024-01-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRRule(%q) = %+v, want error", tt.value, rule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error: %v", tt.value, err)
+			}
+			if !tt.check(rule) {
+				t.Errorf("ParseRRule(%q) = %+v", tt.value, rule)
+			}
+		})
+	}
+}
+
+func TestRRuleOccurrences(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	est := time.FixedZone("EST", -5*60*60)
+	date := func(loc *time.Location, year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []time.Time
+	}{
+		{
+			name:  "last saturday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=-1SA;COUNT=3",
+			start: date(wib, 2024, time.January, 6, 19),
+			want: []time.Time{
+				date(wib, 2024, time.January, 27, 19),
+				date(wib, 2024, time.February, 24, 19),
+				date(wib, 2024, time.March, 30, 19),
+			},
+		},
+		{
+			name:  "month day 31 skips short months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=4",
+			start: date(wib, 2024, time.January, 31, 10),
+			want: []time.Time{
+				date(wib, 2024, time.January, 31, 10),
+				date(wib, 2024, time.March, 31, 10),
+				date(wib, 2024, time.May, 31, 10),
+				date(wib, 2024, time.July, 31, 10),
+			},
+		},
+		{
+			name:  "monthly on start day skips short months",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			start: date(wib, 2024, time.January, 31, 10),
+			want: []time.Time{
+				date(wib, 2024, time.January, 31, 10),
+				date(wib, 2024, time.March, 31, 10),
+				date(wib, 2024, time.May, 31, 10),
+			},
+		},
+		{
+			name:  "weekly on two days",
+			rule:  "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4",
+			start: date(wib, 2024, time.January, 1, 9),
+			want: []time.Time{
+				date(wib, 2024, time.January, 2, 9),
+				date(wib, 2024, time.January, 4, 9),
+				date(wib, 2024, time.January, 9, 9),
+				date(wib, 2024, time.January, 11, 9),
+			},
+		},
+		{
+			name:  "until date covers the whole local day",
+			rule:  "FREQ=DAILY;UNTIL=20240103",
+			start: date(est, 2024, time.January, 1, 20),
+			want: []time.Time{
+				date(est, 2024, time.January, 1, 20),
+				date(est, 2024, time.January, 2, 20),
+				date(est, 2024, time.January, 3, 20),
+			},
+		},
+		{
+			name:  "floating until uses the start time zone",
+			rule:  "FREQ=DAILY;UNTIL=20240102T060000",
+			start: date(wib, 2024, time.January, 1, 7),
+			want: []time.Time{
+				date(wib, 2024, time.January, 1, 7),
+			},
+		},
+		{
+			name:  "utc until is absolute",
+			rule:  "FREQ=DAILY;UNTIL=20240102T000000Z",
+			start: date(wib, 2024, time.January, 1, 7),
+			want: []time.Time{
+				date(wib, 2024, time.January, 1, 7),
+				date(wib, 2024, time.January, 2, 7),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error: %v", tt.rule, err)
+			}
+			got := rule.Occurrences(tt.start)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Occurrences() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRRuleOccurrencesLimit(t *testing.T) {
+	rule, err := ParseRRule("FREQ=DAILY;UNTIL=20300101")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rule.Occurrences(time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC))
+	if len(got) != MaxRRuleOccurrences {
+		t.Errorf("len(Occurrences()) = %d, want %d", len(got), MaxRRuleOccurrences)
+	}
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// TicketTokenPrefix menandai kode tiket yang berupa token bertanda tangan
+// (bukan nonce acak "tix..."). TIX2 ditandatangani Ed25519; TIX1 (HMAC) tidak
+// diterima lagi.
+const TicketTokenPrefix = "TIX2."
+
+var ErrInvalidTicketToken = errors.New("invalid ticket token")
+
+// TicketClaims adalah isi QR tiket yang bisa diverifikasi scanner secara offline.
+type TicketClaims struct {
+	TicketID         string `json:"tid"`
+	EventID          string `json:"eid"`
+	TicketCategoryID string `json:"cid"`
+	Nonce            string `json:"n"`
+	jwt.RegisteredClaims
+}
+
+// TicketCodeTTL - lama satu jendela kode tiket sebelum dirotasi
+func TicketCodeTTL() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("TICKET_CODE_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 30
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+var ErrTicketSigningSecret = errors.New("TICKET_SIGNING_SECRET must be set and differ from JWT_SEED")
+
+// CheckTicketSigningSecret dipanggil saat startup; key tiket tidak boleh
+// kosong atau sama dengan secret JWT login.
+func CheckTicketSigningSecret() error {
+	secret := os.Getenv("TICKET_SIGNING_SECRET")
+	if secret == "" || secret == os.Getenv("JWT_SEED") {
+		return ErrTicketSigningSecret
+	}
+	return nil
+}
+
+func ticketMasterSecret() []byte {
+	return []byte(os.Getenv("TICKET_SIGNING_SECRET"))
+}
+
+// eventTicketSigningKey menurunkan key pair Ed25519 per event dari master
+// secret. Private key hanya ada di server; scanner cukup memegang public key
+// sehingga tidak bisa memalsukan tiket.
+func eventTicketSigningKey(eventID string) ed25519.PrivateKey {
+	mac := hmac.New(sha256.New, ticketMasterSecret())
+	mac.Write([]byte("ticket-key:" + eventID))
+	return ed25519.NewKeyFromSeed(mac.Sum(nil))
+}
+
+// EventTicketPublicKey - public key untuk verifikasi QR dan manifest event
+func EventTicketPublicKey(eventID string) ed25519.PublicKey {
+	return eventTicketSigningKey(eventID).Public().(ed25519.PublicKey)
+}
+
+// SignTicketCode membuat kode QR bertanda tangan untuk satu tiket yang berlaku
+// sampai expiresAt.
+func SignTicketCode(ticketID, eventID, ticketCategoryID, nonce string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := TicketClaims{
+		TicketID:         ticketID,
+		EventID:          eventID,
+		TicketCategoryID: ticketCategoryID,
+		Nonce:            nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now.Add(-1 * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	signed, err := token.SignedString(eventTicketSigningKey(eventID))
+	if err != nil {
+		return "", err
+	}
+	return TicketTokenPrefix + signed, nil
+}
+
+// IsTicketToken membedakan token bertanda tangan dari kode tiket lama.
+func IsTicketToken(code string) bool {
+	return strings.HasPrefix(code, TicketTokenPrefix)
+}
+
+// ParseTicketCode memverifikasi tanda tangan dan jendela waktu token tiket.
+func ParseTicketCode(code string) (*TicketClaims, error) {
+	return parseTicketCode(code, jwt.NewParser())
+}
+
+// ParseTicketCodeAt memverifikasi token terhadap waktu scan, bukan waktu
+// sekarang. Dipakai saat sinkronisasi scan offline yang diunggah belakangan.
+func ParseTicketCodeAt(code string, at time.Time) (*TicketClaims, error) {
+	claims, err := parseTicketCode(code, jwt.NewParser(jwt.WithoutClaimsValidation()))
+	if err != nil {
+		return claims, err
+	}
+	if claims.ExpiresAt != nil && at.After(claims.ExpiresAt.Time) {
+		return claims, jwt.ErrTokenExpired
+	}
+	if claims.NotBefore != nil && at.Before(claims.NotBefore.Time) {
+		return claims, jwt.ErrTokenNotValidYet
+	}
+	return claims, nil
+}
+
+func parseTicketCode(code string, parser *jwt.Parser) (*TicketClaims, error) {
+	if !IsTicketToken(code) {
+		return nil, ErrInvalidTicketToken
+	}
+
+	claims := &TicketClaims{}
+	token, err := parser.ParseWithClaims(strings.TrimPrefix(code, TicketTokenPrefix), claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodEdDSA {
+			return nil, ErrInvalidTicketToken
+		}
+		parsed, _ := token.Claims.(*TicketClaims)
+		if parsed == nil || parsed.EventID == "" {
+			return nil, ErrInvalidTicketToken
+		}
+		return EventTicketPublicKey(parsed.EventID), nil
+	})
+	if err != nil {
+		return claims, err
+	}
+	if !token.Valid {
+		return claims, ErrInvalidTicketToken
+	}
+	return claims, nil
+}
+
+// IsTicketTokenExpired - token valid tetapi jendela waktunya sudah lewat
+func IsTicketTokenExpired(err error) bool {
+	return errors.Is(err, jwt.ErrTokenExpired)
+}
+
+// SignEventManifest menandatangani manifest tiket offline dengan key event
+// yang sama dengan QR, sehingga scanner cukup menyimpan satu public key per event.
+func SignEventManifest(eventID string, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	return token.SignedString(eventTicketSigningKey(eventID))
+}
@@ -65,3 +65,112 @@ func GenerateEventCategoryID() string {
 func GenerateChildEventCategoryID() string {
 	return GeneratePrefixedUUID("child")
 }
+
+// GeneratePrintNonce - nonce QR e-ticket cetak, diganti setiap PDF diterbitkan ulang
+func GeneratePrintNonce() string {
+	return "prt" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+func GenerateOfflineScanID() string {
+	return GeneratePrefixedUUID("oscan")
+}
+
+func GenerateTicketCodeHistoryID() string {
+	return GeneratePrefixedUUID("tcode")
+}
+
+func GenerateEventStaffID() string {
+	return GeneratePrefixedUUID("staff")
+}
+
+func GenerateCheckInLogID() string {
+	return GeneratePrefixedUUID("scan")
+}
+
+func GenerateEntrySessionID() string {
+	return GeneratePrefixedUUID("esess")
+}
+
+func GenerateTicketEntryID() string {
+	return GeneratePrefixedUUID("entry")
+}
+
+func GenerateWalletPassID() string {
+	return GeneratePrefixedUUID("wpass")
+}
+
+func GenerateWalletDeviceID() string {
+	return GeneratePrefixedUUID("wdev")
+}
+
+// GenerateWalletAuthToken - authenticationToken pass Apple (minimal 16 karakter)
+func GenerateWalletAuthToken() string {
+	return strings.ReplaceAll(uuid.New().String()+uuid.New().String(), "-", "")
+}
+
+// GeneratePassNonce - nonce QR pass wallet, tetap selama pass berlaku
+func GeneratePassNonce() string {
+	return "wal" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+func GenerateQuestionID() string {
+	return GeneratePrefixedUUID("quest")
+}
+
+func GenerateTicketAnswerID() string {
+	return GeneratePrefixedUUID("answer")
+}
+
+func GenerateStatusHistoryID() string {
+	return GeneratePrefixedUUID("status")
+}
+
+func GenerateTicketImportID() string {
+	return GeneratePrefixedUUID("import")
+}
+
+// GenerateClaimCode - kode sekali pakai untuk mengklaim tiket impor tanpa akun
+func GenerateClaimCode() string {
+	return "clm" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+func GenerateSeriesID() string {
+	return GeneratePrefixedUUID("series")
+}
+
+func GenerateSpeakerID() string {
+	return GeneratePrefixedUUID("speaker")
+}
+
+func GenerateAgendaSessionID() string {
+	return GeneratePrefixedUUID("agenda")
+}
+
+func GenerateBookmarkID() string {
+	return GeneratePrefixedUUID("bookmark")
+}
+
+func GenerateSeatSectionID() string {
+	return GeneratePrefixedUUID("section")
+}
+
+func GenerateSeatID() string {
+	return GeneratePrefixedUUID("seat")
+}
+
+func GenerateRevisionID() string {
+	return GeneratePrefixedUUID("rev")
+}
+
+func GenerateRefundID() string {
+	return GeneratePrefixedUUID("refund")
+}
+
+func GenerateNotificationID() string {
+	return GeneratePrefixedUUID("notif")
+}
+
+// GenerateCalendarToken - token rahasia URL langganan kalender
+func GenerateCalendarToken() string {
+	return strings.ReplaceAll(uuid.New().String()+uuid.New().String(), "-", "")
+}
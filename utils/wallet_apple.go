@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mozilla.org/pkcs7"
+)
+
+// BuildPKPass menyusun bundle .pkpass: manifest.json berisi SHA-1 tiap file,
+// signature PKCS#7 detached atas manifest.json, lalu semuanya di-zip.
+func BuildPKPass(files map[string][]byte, cert *x509.Certificate, key crypto.PrivateKey, wwdr *x509.Certificate) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	manifest := make(map[string]string, len(files))
+	for _, name := range names {
+		sum := sha1.Sum(files[name])
+		manifest[name] = hex.EncodeToString(sum[:])
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	signedData, err := pkcs7.NewSignedData(manifestJSON)
+	if err != nil {
+		return nil, err
+	}
+	signedData.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	if err := signedData.AddSignerChain(cert, key, []*x509.Certificate{wwdr}, pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, err
+	}
+	signedData.Detach()
+	signature, err := signedData.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	entries := append(names, "manifest.json", "signature")
+	contents := map[string][]byte{"manifest.json": manifestJSON, "signature": signature}
+	for _, name := range entries {
+		content, ok := files[name]
+		if !ok {
+			content = contents[name]
+		}
+		w, err := archive.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// NewAPNsClient - HTTP/2 client dengan sertifikat pass sebagai TLS client certificate
+func NewAPNsClient(certPEM []byte, keyPEM []byte) (*http.Client, error) {
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{Certificates: []tls.Certificate{certificate}},
+			ForceAttemptHTTP2: true,
+		},
+	}, nil
+}
+
+// PushPassUpdate mengirim notifikasi kosong ke APNs; Wallet lalu mengambil pass
+// terbaru lewat web service.
+func PushPassUpdate(client *http.Client, host string, passTypeID string, pushToken string) error {
+	url := strings.TrimRight(host, "/") + "/3/device/" + pushToken
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader("{}"))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("apns-topic", passTypeID)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("apns returned %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// FetchImagePNG mengunduh gambar (JPEG/PNG) dan mengubah lebarnya menjadi width
+// piksel dengan aspek tetap, hasilnya PNG.
+func FetchImagePNG(url string, width int) ([]byte, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image returned %d", resp.StatusCode)
+	}
+
+	src, _, err := image.Decode(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	// nearest-neighbor cukup untuk thumbnail pass
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst.Set(x, y, src.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SolidPNG - gambar polos, dipakai sebagai icon pass jika event tidak punya gambar
+func SolidPNG(size int, c color.Color) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	GoogleWalletSaveURL = "https://pay.google.com/gp/v/save/"
+	googleWalletAPI     = "https://walletobjects.googleapis.com/walletobjects/v1/"
+	googleTokenURL      = "https://oauth2.googleapis.com/token"
+	googleWalletScope   = "https://www.googleapis.com/auth/wallet_object.issuer"
+)
+
+// SignGoogleWalletJWT menandatangani save-JWT (RS256) berisi class dan object pass
+func SignGoogleWalletJWT(clientEmail string, key *rsa.PrivateKey, origins []string, payload map[string]interface{}) (string, error) {
+	claims := jwt.MapClaims{
+		"iss":     clientEmail,
+		"aud":     "google",
+		"typ":     "savetowallet",
+		"iat":     time.Now().Unix(),
+		"origins": origins,
+		"payload": payload,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+}
+
+// GoogleWalletAccessToken - OAuth access token service account (JWT bearer grant)
+func GoogleWalletAccessToken(clientEmail string, key *rsa.PrivateKey) (string, error) {
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   clientEmail,
+		"scope": googleWalletScope,
+		"aud":   googleTokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.PostForm(googleTokenURL, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("google token request failed: %s", token.Error)
+	}
+	return token.AccessToken, nil
+}
+
+// PatchGoogleWalletResource memperbarui class/object yang sudah disimpan user.
+// Resource yang belum pernah disimpan (404) diabaikan.
+func PatchGoogleWalletResource(accessToken string, resource string, id string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, googleWalletAPI+resource+"/"+url.PathEscape(id), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("google wallet returned %d: %s", resp.StatusCode, message)
+	}
+	return nil
+}